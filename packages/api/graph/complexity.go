@@ -0,0 +1,96 @@
+package graph
+
+import "github.com/chirag3003/collab-draw-backend/graph/model"
+
+// Costs of the fields that load whole scenes or replay the op log. Fields not
+// listed cost 1 plus their selections.
+const (
+	elementsCost  = 50  // a full scene, often megabytes of JSON
+	historyCost   = 100 // a range of the op log
+	replayCost    = 200 // rebuilding a scene from the op log
+	exportCost    = 100
+	analyticsCost = 50 // aggregations over the op log
+
+	// Lists are assumed to hold this many entries when not paged
+	defaultListSize = 50
+	// Op log pages are weighted less than their length, since ops are small
+	opsPerCostUnit = 100
+)
+
+// NewComplexity returns the per-field costs used by the complexity limit.
+func NewComplexity() ComplexityRoot {
+	var c ComplexityRoot
+
+	c.Project.Elements = func(childComplexity int) int { return elementsCost }
+	c.ProjectSnapshot.Elements = func(childComplexity int) int { return elementsCost }
+	c.LibraryItem.Elements = func(childComplexity int) int { return elementsCost / 5 }
+
+	c.Query.Projects = func(childComplexity int) int {
+		return 2 * defaultListSize * childComplexity
+	}
+	c.Query.ProjectsByUser = func(childComplexity int, userID string, filter *model.ProjectFilter, sort *model.ProjectSort, first *int32, after *string) int {
+		return pageSize(first) * childComplexity
+	}
+	c.Query.ProjectsByWorkspace = func(childComplexity int, workspaceID string, filter *model.ProjectFilter, sort *model.ProjectSort, first *int32, after *string) int {
+		return pageSize(first) * childComplexity
+	}
+	c.Query.ProjectsPersonalByUser = func(childComplexity int, userID string) int {
+		return defaultListSize * childComplexity
+	}
+	c.Query.Templates = func(childComplexity int, workspaceID string) int {
+		return defaultListSize * childComplexity
+	}
+	c.Query.FavoriteProjects = func(childComplexity int) int {
+		return defaultListSize * childComplexity
+	}
+	c.Query.RecentProjects = func(childComplexity int, limit *int32) int {
+		return pageSize(limit) * childComplexity
+	}
+	c.Query.Trash = func(childComplexity int) int {
+		return defaultListSize * childComplexity
+	}
+	c.Query.Search = func(childComplexity int, query string, workspaceID *string, limit *int32) int {
+		return pageSize(limit) * childComplexity
+	}
+
+	c.Query.OpsSince = func(childComplexity int, projectID string, sinceSeq int32, limit *int32) int {
+		ops := 1000
+		if limit != nil && *limit > 0 {
+			ops = int(*limit)
+		}
+		return historyCost + (ops/opsPerCostUnit+1)*childComplexity
+	}
+	c.Query.ProjectHistory = func(childComplexity int, projectID string, fromSeq int32, toSeq int32) int {
+		return historyCost + (max(int(toSeq-fromSeq), 0)/opsPerCostUnit+1)*childComplexity
+	}
+	c.Query.ProjectSnapshotAt = func(childComplexity int, projectID string, seq int32) int {
+		return replayCost + childComplexity
+	}
+	c.Query.ExportProject = func(childComplexity int, input model.ExportInput) int {
+		return exportCost + childComplexity
+	}
+	c.Query.ExportLibrary = func(childComplexity int, id string, itemIDs []string) int {
+		return exportCost + childComplexity
+	}
+
+	c.Query.ProjectStats = func(childComplexity int, projectID string, days *int32) int {
+		return analyticsCost + childComplexity
+	}
+	c.Query.WorkspaceActivity = func(childComplexity int, workspaceID string, days *int32) int {
+		return analyticsCost + childComplexity
+	}
+	c.Query.MyActivityFeed = func(childComplexity int, cursor *string, limit *int32, includeOwn *bool) int {
+		return analyticsCost + pageSize(limit)*childComplexity
+	}
+
+	return c
+}
+
+// pageSize returns the requested page size, or the assumed size of an unpaged
+// list.
+func pageSize(first *int32) int {
+	if first != nil && *first > 0 {
+		return int(*first)
+	}
+	return defaultListSize
+}
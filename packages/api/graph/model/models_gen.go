@@ -7,14 +7,141 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+
+	"github.com/99designs/gqlgen/graphql"
 )
 
+type AccessToken struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Prefix      string  `json:"prefix"`
+	ProjectID   *string `json:"projectID,omitempty"`
+	WorkspaceID *string `json:"workspaceID,omitempty"`
+	ReadOnly    bool    `json:"readOnly"`
+	ExpiresAt   *string `json:"expiresAt,omitempty"`
+	LastUsedAt  *string `json:"lastUsedAt,omitempty"`
+	CreatedAt   string  `json:"createdAt"`
+}
+
+type ActivityEvent struct {
+	UserID          string   `json:"userID"`
+	UserName        *string  `json:"userName,omitempty"`
+	ProjectID       string   `json:"projectID"`
+	ProjectName     string   `json:"projectName"`
+	WorkspaceID     *string  `json:"workspaceID,omitempty"`
+	Summary         string   `json:"summary"`
+	Ops             int32    `json:"ops"`
+	ElementsChanged int32    `json:"elementsChanged"`
+	ElementsAdded   int32    `json:"elementsAdded"`
+	ElementsDeleted int32    `json:"elementsDeleted"`
+	ElementTypes    []string `json:"elementTypes"`
+	StartedAt       string   `json:"startedAt"`
+	EndedAt         string   `json:"endedAt"`
+	Cursor          string   `json:"cursor"`
+}
+
+type ActivityFeed struct {
+	Events      []*ActivityEvent `json:"events"`
+	NextCursor  *string          `json:"nextCursor,omitempty"`
+	HasNextPage bool             `json:"hasNextPage"`
+}
+
 type ApplyOpsResult struct {
 	Ack       bool          `json:"ack"`
 	ServerSeq int32         `json:"serverSeq"`
 	Rejected  []*RejectedOp `json:"rejected,omitempty"`
 }
 
+type AuditEntry struct {
+	ID         string  `json:"id"`
+	ActorID    string  `json:"actorID"`
+	ActorName  string  `json:"actorName"`
+	Action     string  `json:"action"`
+	TargetType string  `json:"targetType"`
+	TargetID   string  `json:"targetID"`
+	Before     *string `json:"before,omitempty"`
+	After      *string `json:"after,omitempty"`
+	IP         *string `json:"ip,omitempty"`
+	UserAgent  *string `json:"userAgent,omitempty"`
+	CreatedAt  string  `json:"createdAt"`
+}
+
+type AuditLogFilter struct {
+	ActorID  *string `json:"actorID,omitempty"`
+	Action   *string `json:"action,omitempty"`
+	TargetID *string `json:"targetID,omitempty"`
+	Since    *string `json:"since,omitempty"`
+	Until    *string `json:"until,omitempty"`
+}
+
+type AuditLogPage struct {
+	Entries    []*AuditEntry `json:"entries"`
+	NextCursor *string       `json:"nextCursor,omitempty"`
+}
+
+type Comment struct {
+	ID         string   `json:"id"`
+	ThreadID   string   `json:"threadID"`
+	AuthorID   string   `json:"authorID"`
+	AuthorName string   `json:"authorName"`
+	Body       string   `json:"body"`
+	Mentions   []string `json:"mentions"`
+	CreatedAt  string   `json:"createdAt"`
+	EditedAt   *string  `json:"editedAt,omitempty"`
+}
+
+type CommentAnchor struct {
+	ElementID *string  `json:"elementID,omitempty"`
+	X         *float64 `json:"x,omitempty"`
+	Y         *float64 `json:"y,omitempty"`
+	Orphaned  bool     `json:"orphaned"`
+}
+
+type CommentAnchorInput struct {
+	ElementID *string  `json:"elementID,omitempty"`
+	X         *float64 `json:"x,omitempty"`
+	Y         *float64 `json:"y,omitempty"`
+}
+
+type CommentEvent struct {
+	Type     CommentEventType `json:"type"`
+	ThreadID string           `json:"threadID"`
+	Thread   *CommentThread   `json:"thread,omitempty"`
+}
+
+type CommentThread struct {
+	ID         string         `json:"id"`
+	ProjectID  string         `json:"projectID"`
+	Anchor     *CommentAnchor `json:"anchor"`
+	CreatedBy  string         `json:"createdBy"`
+	Resolved   bool           `json:"resolved"`
+	ResolvedBy *string        `json:"resolvedBy,omitempty"`
+	ResolvedAt *string        `json:"resolvedAt,omitempty"`
+	Comments   []*Comment     `json:"comments"`
+	CreatedAt  string         `json:"createdAt"`
+	UpdatedAt  string         `json:"updatedAt"`
+}
+
+type CreateAccessTokenResult struct {
+	Token       string       `json:"token"`
+	AccessToken *AccessToken `json:"accessToken"`
+}
+
+type CreateInvitationResult struct {
+	Token      string      `json:"token"`
+	Invitation *Invitation `json:"invitation"`
+}
+
+type CreateShareLinkResult struct {
+	Token     string     `json:"token"`
+	ShareLink *ShareLink `json:"shareLink"`
+}
+
+type CreateWebhookResult struct {
+	Secret  string   `json:"secret"`
+	Webhook *Webhook `json:"webhook"`
+}
+
 type CursorInput struct {
 	X                  float64  `json:"x"`
 	Y                  float64  `json:"y"`
@@ -31,15 +158,154 @@ type CursorUpdate struct {
 	Timestamp          string   `json:"timestamp"`
 }
 
+type DailyActivity struct {
+	Date          string `json:"date"`
+	Ops           int32  `json:"ops"`
+	ActiveEditors int32  `json:"activeEditors"`
+}
+
+type ElementTypeCount struct {
+	Type  string `json:"type"`
+	Count int32  `json:"count"`
+}
+
+type Event struct {
+	Type        EventType `json:"type"`
+	WorkspaceID *string   `json:"workspaceID,omitempty"`
+	ProjectID   *string   `json:"projectID,omitempty"`
+	UserID      *string   `json:"userID,omitempty"`
+	ActorID     string    `json:"actorID"`
+	Name        *string   `json:"name,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Timestamp   string    `json:"timestamp"`
+}
+
+type ExportInput struct {
+	ProjectID  string       `json:"projectID"`
+	Format     ExportFormat `json:"format"`
+	ElementIDs []string     `json:"elementIDs,omitempty"`
+	Seq        *int32       `json:"seq,omitempty"`
+	Scale      *float64     `json:"scale,omitempty"`
+}
+
+type ExportLink struct {
+	URL       string `json:"url"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+type Folder struct {
+	ID          string  `json:"id"`
+	WorkspaceID string  `json:"workspaceID"`
+	ParentID    *string `json:"parentID,omitempty"`
+	Name        string  `json:"name"`
+	CreatedBy   string  `json:"createdBy"`
+	CreatedAt   string  `json:"createdAt"`
+	UpdatedAt   string  `json:"updatedAt"`
+}
+
+type ImportFailure struct {
+	FileName string `json:"fileName"`
+	Reason   string `json:"reason"`
+}
+
+type ImportInput struct {
+	File      graphql.Upload `json:"file"`
+	Workspace *string        `json:"workspace,omitempty"`
+	Personal  *bool          `json:"personal,omitempty"`
+	Name      *string        `json:"name,omitempty"`
+}
+
+type ImportResult struct {
+	Projects  []*Project       `json:"projects"`
+	Libraries []*Library       `json:"libraries"`
+	Failed    []*ImportFailure `json:"failed"`
+}
+
+type Invitation struct {
+	ID            string           `json:"id"`
+	WorkspaceID   string           `json:"workspaceID"`
+	WorkspaceName string           `json:"workspaceName"`
+	Email         string           `json:"email"`
+	Role          WorkspaceRole    `json:"role"`
+	InvitedBy     string           `json:"invitedBy"`
+	Status        InvitationStatus `json:"status"`
+	ExpiresAt     string           `json:"expiresAt"`
+	CreatedAt     string           `json:"createdAt"`
+}
+
+type Library struct {
+	ID          string         `json:"id"`
+	WorkspaceID string         `json:"workspaceID"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Items       []*LibraryItem `json:"items"`
+	ItemCount   int32          `json:"itemCount"`
+	CreatedBy   string         `json:"createdBy"`
+	CreatedAt   string         `json:"createdAt"`
+	UpdatedAt   string         `json:"updatedAt"`
+}
+
+type LibraryEvent struct {
+	Type      LibraryEventType `json:"type"`
+	LibraryID string           `json:"libraryID"`
+	Library   *Library         `json:"library,omitempty"`
+}
+
+type LibraryItem struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Tags      []string `json:"tags"`
+	Elements  string   `json:"elements"`
+	Thumbnail string   `json:"thumbnail"`
+	CreatedBy string   `json:"createdBy"`
+	CreatedAt string   `json:"createdAt"`
+	UpdatedAt string   `json:"updatedAt"`
+}
+
+type LibraryItemInput struct {
+	Name     string   `json:"name"`
+	Tags     []string `json:"tags,omitempty"`
+	Elements string   `json:"elements"`
+}
+
 type Mutation struct {
 }
 
+type NewAccessToken struct {
+	Name          string  `json:"name"`
+	ProjectID     *string `json:"projectID,omitempty"`
+	WorkspaceID   *string `json:"workspaceID,omitempty"`
+	ReadOnly      bool    `json:"readOnly"`
+	ExpiresInDays *int32  `json:"expiresInDays,omitempty"`
+}
+
+type NewLibrary struct {
+	WorkspaceID string  `json:"workspaceID"`
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+}
+
 type NewProject struct {
 	Name        string  `json:"name"`
 	Description *string `json:"description,omitempty"`
 	Owner       string  `json:"owner"`
 	Workspace   *string `json:"workspace,omitempty"`
 	Personal    bool    `json:"personal"`
+	TemplateID  *string `json:"templateID,omitempty"`
+}
+
+type NewShareLink struct {
+	ProjectID      string          `json:"projectID"`
+	Permission     SharePermission `json:"permission"`
+	Password       *string         `json:"password,omitempty"`
+	ExpiresInHours *int32          `json:"expiresInHours,omitempty"`
+	MaxUses        *int32          `json:"maxUses,omitempty"`
+}
+
+type NewWebhook struct {
+	WorkspaceID string         `json:"workspaceID"`
+	URL         string         `json:"url"`
+	Events      []WebhookEvent `json:"events"`
 }
 
 type NewWorkspace struct {
@@ -48,6 +314,35 @@ type NewWorkspace struct {
 	Owner       string `json:"owner"`
 }
 
+type Notification struct {
+	ID          string           `json:"id"`
+	Type        NotificationType `json:"type"`
+	ActorID     string           `json:"actorID"`
+	ActorName   string           `json:"actorName"`
+	WorkspaceID *string          `json:"workspaceID,omitempty"`
+	ProjectID   *string          `json:"projectID,omitempty"`
+	TargetID    *string          `json:"targetID,omitempty"`
+	Message     string           `json:"message"`
+	Read        bool             `json:"read"`
+	CreatedAt   string           `json:"createdAt"`
+}
+
+type NotificationPage struct {
+	Notifications []*Notification `json:"notifications"`
+	UnreadCount   int32           `json:"unreadCount"`
+	NextCursor    *string         `json:"nextCursor,omitempty"`
+}
+
+type NotificationPreferences struct {
+	MutedTypes      []NotificationType `json:"mutedTypes"`
+	MutedWorkspaces []string           `json:"mutedWorkspaces"`
+}
+
+type NotificationPreferencesInput struct {
+	MutedTypes      []NotificationType `json:"mutedTypes"`
+	MutedWorkspaces []string           `json:"mutedWorkspaces"`
+}
+
 type Operation struct {
 	OpID       string  `json:"opID"`
 	Seq        int32   `json:"seq"`
@@ -71,19 +366,55 @@ type OperationInput struct {
 }
 
 type Project struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description *string `json:"description,omitempty"`
-	Owner       string  `json:"owner"`
-	Workspace   *string `json:"workspace,omitempty"`
-	Personal    bool    `json:"personal"`
-	Elements    string  `json:"elements"`
-	CreatedAt   string  `json:"createdAt"`
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Description  *string  `json:"description,omitempty"`
+	Owner        string   `json:"owner"`
+	Workspace    *string  `json:"workspace,omitempty"`
+	FolderID     *string  `json:"folderID,omitempty"`
+	Tags         []string `json:"tags"`
+	Personal     bool     `json:"personal"`
+	Restricted   bool     `json:"restricted"`
+	IsTemplate   bool     `json:"isTemplate"`
+	IsFavorite   bool     `json:"isFavorite"`
+	Elements     string   `json:"elements"`
+	ThumbnailURL *string  `json:"thumbnailURL,omitempty"`
+	CreatedAt    string   `json:"createdAt"`
+	UpdatedAt    string   `json:"updatedAt"`
+	Cursor       *string  `json:"cursor,omitempty"`
+	DeletedAt    *string  `json:"deletedAt,omitempty"`
+	DeletedBy    *string  `json:"deletedBy,omitempty"`
+}
+
+type ProjectActivity struct {
+	ProjectID     string  `json:"projectID"`
+	ProjectName   string  `json:"projectName"`
+	Ops           int32   `json:"ops"`
+	ActiveEditors int32   `json:"activeEditors"`
+	LastEditedBy  *string `json:"lastEditedBy,omitempty"`
+	LastEditedAt  *string `json:"lastEditedAt,omitempty"`
+}
+
+type ProjectFilter struct {
+	FolderID *string  `json:"folderID,omitempty"`
+	Unfiled  *bool    `json:"unfiled,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Favorite *bool    `json:"favorite,omitempty"`
+	Name     *string  `json:"name,omitempty"`
+}
+
+type ProjectMember struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	FullName  string `json:"fullName"`
+	ImageURL  string `json:"imageURL"`
+	Inherited bool   `json:"inherited"`
 }
 
 type ProjectOpsSubscription struct {
-	Ops      []*Operation `json:"ops"`
-	SocketID string       `json:"socketID"`
+	Ops          []*Operation `json:"ops"`
+	SocketID     string       `json:"socketID"`
+	ClosedReason *string      `json:"closedReason,omitempty"`
 }
 
 type ProjectSnapshot struct {
@@ -92,9 +423,26 @@ type ProjectSnapshot struct {
 	Timestamp string `json:"timestamp"`
 }
 
+type ProjectSort struct {
+	Field     ProjectSortField `json:"field"`
+	Direction *SortDirection   `json:"direction,omitempty"`
+}
+
+type ProjectStats struct {
+	ProjectID      string              `json:"projectID"`
+	ElementCount   int32               `json:"elementCount"`
+	ElementsByType []*ElementTypeCount `json:"elementsByType"`
+	TotalOps       int32               `json:"totalOps"`
+	OpsByUser      []*UserActivity     `json:"opsByUser"`
+	DailyActivity  []*DailyActivity    `json:"dailyActivity"`
+	LastEditedBy   *UserActivity       `json:"lastEditedBy,omitempty"`
+	LastEditedAt   *string             `json:"lastEditedAt,omitempty"`
+}
+
 type ProjectSubscription struct {
-	Elements string `json:"elements"`
-	SocketID string `json:"socketID"`
+	Elements     string  `json:"elements"`
+	SocketID     string  `json:"socketID"`
+	ClosedReason *string `json:"closedReason,omitempty"`
 }
 
 type Query struct {
@@ -106,17 +454,94 @@ type RejectedOp struct {
 	Reason    string `json:"reason"`
 }
 
+type SearchHit struct {
+	Kind        SearchHitKind `json:"kind"`
+	ProjectID   string        `json:"projectID"`
+	ProjectName string        `json:"projectName"`
+	WorkspaceID *string       `json:"workspaceID,omitempty"`
+	ElementID   *string       `json:"elementID,omitempty"`
+	ThreadID    *string       `json:"threadID,omitempty"`
+	CommentID   *string       `json:"commentID,omitempty"`
+	Snippet     string        `json:"snippet"`
+	Score       float64       `json:"score"`
+}
+
+type ShareLink struct {
+	ID          string          `json:"id"`
+	ProjectID   string          `json:"projectID"`
+	Permission  SharePermission `json:"permission"`
+	HasPassword bool            `json:"hasPassword"`
+	ExpiresAt   *string         `json:"expiresAt,omitempty"`
+	MaxUses     *int32          `json:"maxUses,omitempty"`
+	Uses        int32           `json:"uses"`
+	CreatedBy   string          `json:"createdBy"`
+	CreatedAt   string          `json:"createdAt"`
+}
+
 type Subscription struct {
 }
 
+type Trash struct {
+	Projects      []*Project   `json:"projects"`
+	Workspaces    []*Workspace `json:"workspaces"`
+	RetentionDays int32        `json:"retentionDays"`
+}
+
+type UpdateLibrary struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+type UpdateLibraryItem struct {
+	Name     *string  `json:"name,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Elements *string  `json:"elements,omitempty"`
+}
+
+type UserActivity struct {
+	UserID   string  `json:"userID"`
+	FullName *string `json:"fullName,omitempty"`
+	Ops      int32   `json:"ops"`
+	LastOpAt string  `json:"lastOpAt"`
+}
+
 type UserPresence struct {
 	UserID   string         `json:"userID"`
 	UserName string         `json:"userName"`
 	Email    string         `json:"email"`
+	Guest    bool           `json:"guest"`
 	Status   PresenceStatus `json:"status"`
 	JoinedAt string         `json:"joinedAt"`
 }
 
+type Webhook struct {
+	ID          string         `json:"id"`
+	WorkspaceID string         `json:"workspaceID"`
+	URL         string         `json:"url"`
+	Events      []WebhookEvent `json:"events"`
+	Active      bool           `json:"active"`
+	CreatedBy   string         `json:"createdBy"`
+	CreatedAt   string         `json:"createdAt"`
+}
+
+type WebhookDelivery struct {
+	ID            string                `json:"id"`
+	Event         WebhookEvent          `json:"event"`
+	Status        WebhookDeliveryStatus `json:"status"`
+	Attempts      int32                 `json:"attempts"`
+	ResponseCode  *int32                `json:"responseCode,omitempty"`
+	Error         *string               `json:"error,omitempty"`
+	Payload       string                `json:"payload"`
+	NextAttemptAt *string               `json:"nextAttemptAt,omitempty"`
+	DeliveredAt   *string               `json:"deliveredAt,omitempty"`
+	CreatedAt     string                `json:"createdAt"`
+}
+
+type WebhookDeliveryPage struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+	NextCursor *string            `json:"nextCursor,omitempty"`
+}
+
 type Workspace struct {
 	ID          string                    `json:"id"`
 	Name        string                    `json:"name"`
@@ -124,13 +549,27 @@ type Workspace struct {
 	Owner       string                    `json:"owner"`
 	Members     *WorkspaceMembersResponse `json:"members,omitempty"`
 	CreatedAt   string                    `json:"createdAt"`
+	DeletedAt   *string                   `json:"deletedAt,omitempty"`
+	DeletedBy   *string                   `json:"deletedBy,omitempty"`
+}
+
+type WorkspaceActivity struct {
+	WorkspaceID    string             `json:"workspaceID"`
+	ProjectCount   int32              `json:"projectCount"`
+	ActiveProjects int32              `json:"activeProjects"`
+	TotalOps       int32              `json:"totalOps"`
+	ActiveEditors  int32              `json:"activeEditors"`
+	OpsByUser      []*UserActivity    `json:"opsByUser"`
+	DailyActivity  []*DailyActivity   `json:"dailyActivity"`
+	Projects       []*ProjectActivity `json:"projects"`
 }
 
 type WorkspaceMember struct {
-	ID       string `json:"id"`
-	Email    string `json:"email"`
-	ImageURL string `json:"imageURL"`
-	FullName string `json:"fullName"`
+	ID       string        `json:"id"`
+	Email    string        `json:"email"`
+	ImageURL string        `json:"imageURL"`
+	FullName string        `json:"fullName"`
+	Role     WorkspaceRole `json:"role"`
 }
 
 type WorkspaceMembersResponse struct {
@@ -138,50 +577,60 @@ type WorkspaceMembersResponse struct {
 	Owner   *WorkspaceMember   `json:"owner"`
 }
 
-type OpType string
+type WorkspaceQuota struct {
+	WorkspaceID     string   `json:"workspaceID"`
+	Projects        int32    `json:"projects"`
+	MaxProjects     *int32   `json:"maxProjects,omitempty"`
+	Elements        int32    `json:"elements"`
+	MaxElements     *int32   `json:"maxElements,omitempty"`
+	StorageBytes    float64  `json:"storageBytes"`
+	MaxStorageBytes *float64 `json:"maxStorageBytes,omitempty"`
+}
+
+type CommentEventType string
 
 const (
-	OpTypeAdd    OpType = "ADD"
-	OpTypeUpdate OpType = "UPDATE"
-	OpTypeDelete OpType = "DELETE"
+	CommentEventTypeThreadCreated CommentEventType = "THREAD_CREATED"
+	CommentEventTypeThreadUpdated CommentEventType = "THREAD_UPDATED"
+	CommentEventTypeThreadDeleted CommentEventType = "THREAD_DELETED"
 )
 
-var AllOpType = []OpType{
-	OpTypeAdd,
-	OpTypeUpdate,
-	OpTypeDelete,
+var AllCommentEventType = []CommentEventType{
+	CommentEventTypeThreadCreated,
+	CommentEventTypeThreadUpdated,
+	CommentEventTypeThreadDeleted,
 }
 
-func (e OpType) IsValid() bool {
+func (e CommentEventType) IsValid() bool {
 	switch e {
-	case OpTypeAdd, OpTypeUpdate, OpTypeDelete:
+	case CommentEventTypeThreadCreated, CommentEventTypeThreadUpdated, CommentEventTypeThreadDeleted:
 		return true
 	}
 	return false
 }
 
-func (e OpType) String() string {
+func (e CommentEventType) String() string {
 	return string(e)
 }
 
-func (e *OpType) UnmarshalGQL(v any) error {
+func (e *CommentEventType) UnmarshalGQL(v any) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
 	}
 
-	*e = OpType(str)
+	*e = CommentEventType(str)
 	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid OpType", str)
+		return fmt.Errorf("%s is not a valid CommentEventType", str)
 	}
 	return nil
 }
 
-func (e OpType) MarshalGQL(w io.Writer) {
+func (e CommentEventType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
-func (e *OpType) UnmarshalJSON(b []byte) error {
+func (e *CommentEventType) UnmarshalJSON(b []byte) error {
 	s, err := strconv.Unquote(string(b))
 	if err != nil {
 		return err
@@ -189,54 +638,68 @@ func (e *OpType) UnmarshalJSON(b []byte) error {
 	return e.UnmarshalGQL(s)
 }
 
-func (e OpType) MarshalJSON() ([]byte, error) {
+func (e CommentEventType) MarshalJSON() ([]byte, error) {
 	var buf bytes.Buffer
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
 
-type PresenceStatus string
+type EventType string
 
 const (
-	PresenceStatusActive PresenceStatus = "ACTIVE"
-	PresenceStatusIdle   PresenceStatus = "IDLE"
+	EventTypeProjectCreated   EventType = "PROJECT_CREATED"
+	EventTypeProjectRenamed   EventType = "PROJECT_RENAMED"
+	EventTypeProjectDeleted   EventType = "PROJECT_DELETED"
+	EventTypeProjectRestored  EventType = "PROJECT_RESTORED"
+	EventTypeWorkspaceRenamed EventType = "WORKSPACE_RENAMED"
+	EventTypeWorkspaceDeleted EventType = "WORKSPACE_DELETED"
+	EventTypeMemberAdded      EventType = "MEMBER_ADDED"
+	EventTypeMemberRemoved    EventType = "MEMBER_REMOVED"
+	EventTypeAccessRevoked    EventType = "ACCESS_REVOKED"
 )
 
-var AllPresenceStatus = []PresenceStatus{
-	PresenceStatusActive,
-	PresenceStatusIdle,
+var AllEventType = []EventType{
+	EventTypeProjectCreated,
+	EventTypeProjectRenamed,
+	EventTypeProjectDeleted,
+	EventTypeProjectRestored,
+	EventTypeWorkspaceRenamed,
+	EventTypeWorkspaceDeleted,
+	EventTypeMemberAdded,
+	EventTypeMemberRemoved,
+	EventTypeAccessRevoked,
 }
 
-func (e PresenceStatus) IsValid() bool {
+func (e EventType) IsValid() bool {
 	switch e {
-	case PresenceStatusActive, PresenceStatusIdle:
+	case EventTypeProjectCreated, EventTypeProjectRenamed, EventTypeProjectDeleted, EventTypeProjectRestored, EventTypeWorkspaceRenamed, EventTypeWorkspaceDeleted, EventTypeMemberAdded, EventTypeMemberRemoved, EventTypeAccessRevoked:
 		return true
 	}
 	return false
 }
 
-func (e PresenceStatus) String() string {
+func (e EventType) String() string {
 	return string(e)
 }
 
-func (e *PresenceStatus) UnmarshalGQL(v any) error {
+func (e *EventType) UnmarshalGQL(v any) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
 	}
 
-	*e = PresenceStatus(str)
+	*e = EventType(str)
 	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid PresenceStatus", str)
+		return fmt.Errorf("%s is not a valid EventType", str)
 	}
 	return nil
 }
 
-func (e PresenceStatus) MarshalGQL(w io.Writer) {
+func (e EventType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
-func (e *PresenceStatus) UnmarshalJSON(b []byte) error {
+func (e *EventType) UnmarshalJSON(b []byte) error {
 	s, err := strconv.Unquote(string(b))
 	if err != nil {
 		return err
@@ -244,7 +707,754 @@ func (e *PresenceStatus) UnmarshalJSON(b []byte) error {
 	return e.UnmarshalGQL(s)
 }
 
-func (e PresenceStatus) MarshalJSON() ([]byte, error) {
+func (e EventType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ExportFormat string
+
+const (
+	ExportFormatExcalidraw ExportFormat = "EXCALIDRAW"
+	ExportFormatSVG        ExportFormat = "SVG"
+	ExportFormatPng        ExportFormat = "PNG"
+)
+
+var AllExportFormat = []ExportFormat{
+	ExportFormatExcalidraw,
+	ExportFormatSVG,
+	ExportFormatPng,
+}
+
+func (e ExportFormat) IsValid() bool {
+	switch e {
+	case ExportFormatExcalidraw, ExportFormatSVG, ExportFormatPng:
+		return true
+	}
+	return false
+}
+
+func (e ExportFormat) String() string {
+	return string(e)
+}
+
+func (e *ExportFormat) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ExportFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ExportFormat", str)
+	}
+	return nil
+}
+
+func (e ExportFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ExportFormat) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ExportFormat) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type InvitationStatus string
+
+const (
+	InvitationStatusPending  InvitationStatus = "PENDING"
+	InvitationStatusAccepted InvitationStatus = "ACCEPTED"
+	InvitationStatusDeclined InvitationStatus = "DECLINED"
+	InvitationStatusRevoked  InvitationStatus = "REVOKED"
+)
+
+var AllInvitationStatus = []InvitationStatus{
+	InvitationStatusPending,
+	InvitationStatusAccepted,
+	InvitationStatusDeclined,
+	InvitationStatusRevoked,
+}
+
+func (e InvitationStatus) IsValid() bool {
+	switch e {
+	case InvitationStatusPending, InvitationStatusAccepted, InvitationStatusDeclined, InvitationStatusRevoked:
+		return true
+	}
+	return false
+}
+
+func (e InvitationStatus) String() string {
+	return string(e)
+}
+
+func (e *InvitationStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = InvitationStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid InvitationStatus", str)
+	}
+	return nil
+}
+
+func (e InvitationStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *InvitationStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e InvitationStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type LibraryEventType string
+
+const (
+	LibraryEventTypeCreated LibraryEventType = "CREATED"
+	LibraryEventTypeUpdated LibraryEventType = "UPDATED"
+	LibraryEventTypeDeleted LibraryEventType = "DELETED"
+)
+
+var AllLibraryEventType = []LibraryEventType{
+	LibraryEventTypeCreated,
+	LibraryEventTypeUpdated,
+	LibraryEventTypeDeleted,
+}
+
+func (e LibraryEventType) IsValid() bool {
+	switch e {
+	case LibraryEventTypeCreated, LibraryEventTypeUpdated, LibraryEventTypeDeleted:
+		return true
+	}
+	return false
+}
+
+func (e LibraryEventType) String() string {
+	return string(e)
+}
+
+func (e *LibraryEventType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LibraryEventType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LibraryEventType", str)
+	}
+	return nil
+}
+
+func (e LibraryEventType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *LibraryEventType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e LibraryEventType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type NotificationType string
+
+const (
+	NotificationTypeWorkspaceInvitation NotificationType = "WORKSPACE_INVITATION"
+	NotificationTypeWorkspaceJoined     NotificationType = "WORKSPACE_JOINED"
+	NotificationTypeWorkspaceRemoved    NotificationType = "WORKSPACE_REMOVED"
+	NotificationTypeProjectShared       NotificationType = "PROJECT_SHARED"
+	NotificationTypeCommentMention      NotificationType = "COMMENT_MENTION"
+)
+
+var AllNotificationType = []NotificationType{
+	NotificationTypeWorkspaceInvitation,
+	NotificationTypeWorkspaceJoined,
+	NotificationTypeWorkspaceRemoved,
+	NotificationTypeProjectShared,
+	NotificationTypeCommentMention,
+}
+
+func (e NotificationType) IsValid() bool {
+	switch e {
+	case NotificationTypeWorkspaceInvitation, NotificationTypeWorkspaceJoined, NotificationTypeWorkspaceRemoved, NotificationTypeProjectShared, NotificationTypeCommentMention:
+		return true
+	}
+	return false
+}
+
+func (e NotificationType) String() string {
+	return string(e)
+}
+
+func (e *NotificationType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationType", str)
+	}
+	return nil
+}
+
+func (e NotificationType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *NotificationType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e NotificationType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type OpType string
+
+const (
+	OpTypeAdd    OpType = "ADD"
+	OpTypeUpdate OpType = "UPDATE"
+	OpTypeDelete OpType = "DELETE"
+)
+
+var AllOpType = []OpType{
+	OpTypeAdd,
+	OpTypeUpdate,
+	OpTypeDelete,
+}
+
+func (e OpType) IsValid() bool {
+	switch e {
+	case OpTypeAdd, OpTypeUpdate, OpTypeDelete:
+		return true
+	}
+	return false
+}
+
+func (e OpType) String() string {
+	return string(e)
+}
+
+func (e *OpType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OpType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OpType", str)
+	}
+	return nil
+}
+
+func (e OpType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *OpType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e OpType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PresenceStatus string
+
+const (
+	PresenceStatusActive PresenceStatus = "ACTIVE"
+	PresenceStatusIdle   PresenceStatus = "IDLE"
+)
+
+var AllPresenceStatus = []PresenceStatus{
+	PresenceStatusActive,
+	PresenceStatusIdle,
+}
+
+func (e PresenceStatus) IsValid() bool {
+	switch e {
+	case PresenceStatusActive, PresenceStatusIdle:
+		return true
+	}
+	return false
+}
+
+func (e PresenceStatus) String() string {
+	return string(e)
+}
+
+func (e *PresenceStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PresenceStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PresenceStatus", str)
+	}
+	return nil
+}
+
+func (e PresenceStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PresenceStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PresenceStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ProjectSortField string
+
+const (
+	ProjectSortFieldName      ProjectSortField = "NAME"
+	ProjectSortFieldUpdatedAt ProjectSortField = "UPDATED_AT"
+	ProjectSortFieldCreatedAt ProjectSortField = "CREATED_AT"
+)
+
+var AllProjectSortField = []ProjectSortField{
+	ProjectSortFieldName,
+	ProjectSortFieldUpdatedAt,
+	ProjectSortFieldCreatedAt,
+}
+
+func (e ProjectSortField) IsValid() bool {
+	switch e {
+	case ProjectSortFieldName, ProjectSortFieldUpdatedAt, ProjectSortFieldCreatedAt:
+		return true
+	}
+	return false
+}
+
+func (e ProjectSortField) String() string {
+	return string(e)
+}
+
+func (e *ProjectSortField) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ProjectSortField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ProjectSortField", str)
+	}
+	return nil
+}
+
+func (e ProjectSortField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ProjectSortField) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ProjectSortField) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type SearchHitKind string
+
+const (
+	SearchHitKindProject SearchHitKind = "PROJECT"
+	SearchHitKindElement SearchHitKind = "ELEMENT"
+	SearchHitKindComment SearchHitKind = "COMMENT"
+)
+
+var AllSearchHitKind = []SearchHitKind{
+	SearchHitKindProject,
+	SearchHitKindElement,
+	SearchHitKindComment,
+}
+
+func (e SearchHitKind) IsValid() bool {
+	switch e {
+	case SearchHitKindProject, SearchHitKindElement, SearchHitKindComment:
+		return true
+	}
+	return false
+}
+
+func (e SearchHitKind) String() string {
+	return string(e)
+}
+
+func (e *SearchHitKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SearchHitKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SearchHitKind", str)
+	}
+	return nil
+}
+
+func (e SearchHitKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *SearchHitKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e SearchHitKind) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type SharePermission string
+
+const (
+	SharePermissionView SharePermission = "VIEW"
+	SharePermissionEdit SharePermission = "EDIT"
+)
+
+var AllSharePermission = []SharePermission{
+	SharePermissionView,
+	SharePermissionEdit,
+}
+
+func (e SharePermission) IsValid() bool {
+	switch e {
+	case SharePermissionView, SharePermissionEdit:
+		return true
+	}
+	return false
+}
+
+func (e SharePermission) String() string {
+	return string(e)
+}
+
+func (e *SharePermission) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SharePermission(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SharePermission", str)
+	}
+	return nil
+}
+
+func (e SharePermission) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *SharePermission) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e SharePermission) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type SortDirection string
+
+const (
+	SortDirectionAsc  SortDirection = "ASC"
+	SortDirectionDesc SortDirection = "DESC"
+)
+
+var AllSortDirection = []SortDirection{
+	SortDirectionAsc,
+	SortDirectionDesc,
+}
+
+func (e SortDirection) IsValid() bool {
+	switch e {
+	case SortDirectionAsc, SortDirectionDesc:
+		return true
+	}
+	return false
+}
+
+func (e SortDirection) String() string {
+	return string(e)
+}
+
+func (e *SortDirection) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SortDirection(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SortDirection", str)
+	}
+	return nil
+}
+
+func (e SortDirection) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *SortDirection) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e SortDirection) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "SUCCEEDED"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "FAILED"
+)
+
+var AllWebhookDeliveryStatus = []WebhookDeliveryStatus{
+	WebhookDeliveryStatusPending,
+	WebhookDeliveryStatusSucceeded,
+	WebhookDeliveryStatusFailed,
+}
+
+func (e WebhookDeliveryStatus) IsValid() bool {
+	switch e {
+	case WebhookDeliveryStatusPending, WebhookDeliveryStatusSucceeded, WebhookDeliveryStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e WebhookDeliveryStatus) String() string {
+	return string(e)
+}
+
+func (e *WebhookDeliveryStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebhookDeliveryStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebhookDeliveryStatus", str)
+	}
+	return nil
+}
+
+func (e WebhookDeliveryStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *WebhookDeliveryStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e WebhookDeliveryStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type WebhookEvent string
+
+const (
+	WebhookEventOpsApplied     WebhookEvent = "OPS_APPLIED"
+	WebhookEventProjectCreated WebhookEvent = "PROJECT_CREATED"
+	WebhookEventProjectDeleted WebhookEvent = "PROJECT_DELETED"
+	WebhookEventMemberAdded    WebhookEvent = "MEMBER_ADDED"
+	WebhookEventMemberRemoved  WebhookEvent = "MEMBER_REMOVED"
+	WebhookEventCommentCreated WebhookEvent = "COMMENT_CREATED"
+)
+
+var AllWebhookEvent = []WebhookEvent{
+	WebhookEventOpsApplied,
+	WebhookEventProjectCreated,
+	WebhookEventProjectDeleted,
+	WebhookEventMemberAdded,
+	WebhookEventMemberRemoved,
+	WebhookEventCommentCreated,
+}
+
+func (e WebhookEvent) IsValid() bool {
+	switch e {
+	case WebhookEventOpsApplied, WebhookEventProjectCreated, WebhookEventProjectDeleted, WebhookEventMemberAdded, WebhookEventMemberRemoved, WebhookEventCommentCreated:
+		return true
+	}
+	return false
+}
+
+func (e WebhookEvent) String() string {
+	return string(e)
+}
+
+func (e *WebhookEvent) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebhookEvent(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebhookEvent", str)
+	}
+	return nil
+}
+
+func (e WebhookEvent) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *WebhookEvent) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e WebhookEvent) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type WorkspaceRole string
+
+const (
+	WorkspaceRoleOwner  WorkspaceRole = "OWNER"
+	WorkspaceRoleEditor WorkspaceRole = "EDITOR"
+	WorkspaceRoleViewer WorkspaceRole = "VIEWER"
+)
+
+var AllWorkspaceRole = []WorkspaceRole{
+	WorkspaceRoleOwner,
+	WorkspaceRoleEditor,
+	WorkspaceRoleViewer,
+}
+
+func (e WorkspaceRole) IsValid() bool {
+	switch e {
+	case WorkspaceRoleOwner, WorkspaceRoleEditor, WorkspaceRoleViewer:
+		return true
+	}
+	return false
+}
+
+func (e WorkspaceRole) String() string {
+	return string(e)
+}
+
+func (e *WorkspaceRole) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WorkspaceRole(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WorkspaceRole", str)
+	}
+	return nil
+}
+
+func (e WorkspaceRole) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *WorkspaceRole) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e WorkspaceRole) MarshalJSON() ([]byte, error) {
 	var buf bytes.Buffer
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
@@ -0,0 +1,119 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/graph/model"
+	"github.com/chirag3003/collab-draw-backend/internal/auth"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"github.com/chirag3003/collab-draw-backend/internal/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// MyActivityFeed is the resolver for the myActivityFeed field.
+func (r *queryResolver) MyActivityFeed(ctx context.Context, cursor *string, limit *int32, includeOwn *bool) (*model.ActivityFeed, error) {
+	authContext := auth.ForContext(ctx)
+	if authContext == nil || auth.IsGuest(ctx) {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	size := 20
+	if limit != nil && *limit > 0 && *limit < 100 {
+		size = int(*limit)
+	}
+
+	projects, err := r.Repo.Project.GetAccessibleProjects(ctx, authContext.Sub, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch projects: %v", err)
+	}
+	byID := make(map[bson.ObjectID]*models.Project, len(projects))
+	query := repository.FeedQuery{Limit: size}
+	for _, p := range projects {
+		if !projectInScope(ctx, p) {
+			continue
+		}
+		byID[p.ID] = p
+		query.ProjectIDs = append(query.ProjectIDs, p.ID)
+	}
+	if includeOwn == nil || !*includeOwn {
+		query.ExcludeUserID = authContext.Sub
+	}
+	if cursor != nil {
+		query.After = *cursor
+	}
+
+	page, err := r.Repo.Operation.GetActivityFeed(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch activity: %v", err)
+	}
+	userIDs := make([]string, 0, len(page.Events))
+	for _, event := range page.Events {
+		userIDs = append(userIDs, event.UserID)
+	}
+	names := r.userNames(ctx, userIDs)
+
+	result := &model.ActivityFeed{
+		Events:      make([]*model.ActivityEvent, 0, len(page.Events)),
+		HasNextPage: page.HasNextPage,
+	}
+	for _, event := range page.Events {
+		project := byID[event.ProjectID]
+		if project == nil {
+			continue
+		}
+		result.Events = append(result.Events, convertActivityEventToModel(event, project, names))
+	}
+	if n := len(page.Events); n > 0 && page.HasNextPage {
+		result.NextCursor = &page.Events[n-1].Cursor
+	}
+	return result, nil
+}
+
+func convertActivityEventToModel(event repository.FeedEvent, project *models.Project, names map[string]string) *model.ActivityEvent {
+	result := &model.ActivityEvent{
+		UserID:          event.UserID,
+		ProjectID:       project.ID.Hex(),
+		ProjectName:     project.Name,
+		Ops:             int32(event.Ops),
+		ElementsChanged: int32(event.ElementsChanged),
+		ElementsAdded:   int32(event.ElementsAdded),
+		ElementsDeleted: int32(event.ElementsDeleted),
+		ElementTypes:    event.ElementTypes,
+		StartedAt:       event.StartedAt.Format(time.RFC3339),
+		EndedAt:         event.EndedAt.Format(time.RFC3339),
+		Cursor:          event.Cursor,
+	}
+	actor := "Someone"
+	if name, ok := names[event.UserID]; ok {
+		result.UserName = &name
+		actor = name
+	}
+	if project.Workspace != nil {
+		hex := project.Workspace.Hex()
+		result.WorkspaceID = &hex
+	}
+	result.Summary = fmt.Sprintf("%s %s in %s", actor, describeChanges(event), project.Name)
+	return result
+}
+
+// describeChanges phrases the changes of a feed event, e.g. "edited 23
+// elements" or "added 2 elements".
+func describeChanges(event repository.FeedEvent) string {
+	verb := "edited"
+	switch event.ElementsChanged {
+	case event.ElementsAdded:
+		verb = "added"
+	case event.ElementsDeleted:
+		verb = "deleted"
+	}
+	noun := "elements"
+	if event.ElementsChanged == 1 {
+		noun = "element"
+	}
+	return fmt.Sprintf("%s %d %s", verb, event.ElementsChanged, noun)
+}
@@ -0,0 +1,116 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/graph/model"
+	"github.com/chirag3003/collab-draw-backend/internal/auth"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"github.com/chirag3003/collab-draw-backend/internal/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// AuditLog is the resolver for the auditLog field.
+func (r *queryResolver) AuditLog(ctx context.Context, workspaceID string, filter *model.AuditLogFilter, cursor *string) (*model.AuditLogPage, error) {
+	authContext := auth.ForContext(ctx)
+	if authContext == nil {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	if !workspaceInScope(ctx, workspaceID) {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	workspace, err := r.Repo.Workspace.GetWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workspace: %v", err)
+	}
+	if workspace == nil || workspace.Owner != authContext.Sub {
+		return nil, fmt.Errorf("only the workspace owner can view the audit log")
+	}
+
+	repoFilter := repository.AuditFilter{}
+	if filter != nil {
+		if filter.ActorID != nil {
+			repoFilter.ActorID = *filter.ActorID
+		}
+		if filter.Action != nil {
+			repoFilter.Action = *filter.Action
+		}
+		if filter.TargetID != nil {
+			repoFilter.TargetID = *filter.TargetID
+		}
+		if repoFilter.Since, err = parseAuditTime(filter.Since); err != nil {
+			return nil, fmt.Errorf("invalid since: %v", err)
+		}
+		if repoFilter.Until, err = parseAuditTime(filter.Until); err != nil {
+			return nil, fmt.Errorf("invalid until: %v", err)
+		}
+	}
+	after := ""
+	if cursor != nil {
+		after = *cursor
+	}
+
+	entries, next, err := r.Repo.Audit.GetByWorkspace(ctx, workspaceID, repoFilter, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch audit log: %v", err)
+	}
+	page := &model.AuditLogPage{Entries: make([]*model.AuditEntry, 0, len(entries))}
+	for _, entry := range entries {
+		page.Entries = append(page.Entries, convertAuditEntryToModel(entry))
+	}
+	if next != "" {
+		page.NextCursor = &next
+	}
+	return page, nil
+}
+
+func parseAuditTime(value *string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func convertAuditEntryToModel(entry *models.AuditEntry) *model.AuditEntry {
+	result := &model.AuditEntry{
+		ID:         entry.ID.Hex(),
+		ActorID:    entry.ActorID,
+		ActorName:  entry.ActorName,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Before:     auditStateToJSON(entry.Before),
+		After:      auditStateToJSON(entry.After),
+		CreatedAt:  entry.CreatedAt.Format(time.RFC3339),
+	}
+	if entry.IP != "" {
+		result.IP = &entry.IP
+	}
+	if entry.UserAgent != "" {
+		result.UserAgent = &entry.UserAgent
+	}
+	return result
+}
+
+func auditStateToJSON(state bson.M) *string {
+	if state == nil {
+		return nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil
+	}
+	s := string(data)
+	return &s
+}
@@ -0,0 +1,436 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/chirag3003/collab-draw-backend/graph/model"
+	"github.com/chirag3003/collab-draw-backend/internal/auth"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// CreateCommentThread is the resolver for the createCommentThread field.
+func (r *mutationResolver) CreateCommentThread(ctx context.Context, projectID string, anchor model.CommentAnchorInput, body string, mentions []string) (*model.CommentThread, error) {
+	authContext := auth.ForContext(ctx)
+	project, err := r.getAccessibleProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if project == nil {
+		return nil, fmt.Errorf("project not found or access denied")
+	}
+	if strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("comment cannot be empty")
+	}
+	thread := &models.CommentThread{
+		ProjectID: project.ID,
+		CreatedBy: authContext.Sub,
+	}
+	switch {
+	case anchor.ElementID != nil && *anchor.ElementID != "":
+		thread.ElementID = *anchor.ElementID
+	case anchor.X != nil && anchor.Y != nil:
+		thread.X, thread.Y = anchor.X, anchor.Y
+	default:
+		return nil, fmt.Errorf("anchor needs an element ID or both x and y")
+	}
+	mentions, err = r.validateMentions(ctx, project, mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &models.Comment{
+		AuthorID:   authContext.Sub,
+		AuthorName: authContext.PreferredUsername,
+		Body:       body,
+		Mentions:   mentions,
+	}
+	err = r.Repo.Comment.CreateThread(ctx, thread, comment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create comment thread: %v", err)
+	}
+
+	r.Search.IndexComment(ctx, thread, comment)
+	r.notifyMentions(ctx, project, thread.ID, mentions)
+	result := convertCommentThreadToModel(thread, []*models.Comment{comment})
+	r.enqueueCommentWebhook(ctx, project, result.Comments[0])
+	r.broadcastComment(projectID, &model.CommentEvent{
+		Type:     model.CommentEventTypeThreadCreated,
+		ThreadID: result.ID,
+		Thread:   result,
+	})
+	return result, nil
+}
+
+// ReplyToCommentThread is the resolver for the replyToCommentThread field.
+func (r *mutationResolver) ReplyToCommentThread(ctx context.Context, threadID string, body string, mentions []string) (*model.Comment, error) {
+	authContext := auth.ForContext(ctx)
+	thread, project, err := r.getAccessibleThread(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("comment cannot be empty")
+	}
+	mentions, err = r.validateMentions(ctx, project, mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &models.Comment{
+		ThreadID:   thread.ID,
+		ProjectID:  project.ID,
+		AuthorID:   authContext.Sub,
+		AuthorName: authContext.PreferredUsername,
+		Body:       body,
+		Mentions:   mentions,
+	}
+	err = r.Repo.Comment.AddComment(ctx, comment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add comment: %v", err)
+	}
+	r.Search.IndexComment(ctx, thread, comment)
+	r.notifyMentions(ctx, project, thread.ID, mentions)
+	r.publishThreadUpdate(ctx, project.ID.Hex(), thread.ID.Hex())
+	result := convertCommentToModel(comment)
+	r.enqueueCommentWebhook(ctx, project, result)
+	return result, nil
+}
+
+// EditComment is the resolver for the editComment field.
+func (r *mutationResolver) EditComment(ctx context.Context, id string, body string, mentions []string) (*model.Comment, error) {
+	authContext := auth.ForContext(ctx)
+	comment, project, err := r.getOwnComment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("comment cannot be empty")
+	}
+	mentions, err = r.validateMentions(ctx, project, mentions)
+	if err != nil {
+		return nil, err
+	}
+	previousMentions := comment.Mentions
+	edited, err := r.Repo.Comment.EditComment(ctx, comment.ID, authContext.Sub, body, mentions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to edit comment: %v", err)
+	}
+	if !edited {
+		return nil, fmt.Errorf("comment not found")
+	}
+	comment, err = r.Repo.Comment.GetComment(ctx, id)
+	if err != nil || comment == nil {
+		return nil, fmt.Errorf("failed to fetch comment: %v", err)
+	}
+	if thread, err := r.Repo.Comment.GetThread(ctx, comment.ThreadID.Hex()); err == nil && thread != nil {
+		r.Search.IndexComment(ctx, thread, comment)
+	}
+	// Only users mentioned by this edit are notified
+	var added []string
+	for _, userID := range mentions {
+		if !slices.Contains(previousMentions, userID) {
+			added = append(added, userID)
+		}
+	}
+	r.notifyMentions(ctx, project, comment.ThreadID, added)
+	r.publishThreadUpdate(ctx, project.ID.Hex(), comment.ThreadID.Hex())
+	return convertCommentToModel(comment), nil
+}
+
+// DeleteComment is the resolver for the deleteComment field.
+func (r *mutationResolver) DeleteComment(ctx context.Context, id string) (bool, error) {
+	comment, project, err := r.getOwnComment(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	err = r.Repo.Comment.DeleteComment(ctx, comment)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete comment: %v", err)
+	}
+	r.Search.RemoveComment(ctx, comment.ID)
+	r.publishThreadUpdate(ctx, project.ID.Hex(), comment.ThreadID.Hex())
+	return true, nil
+}
+
+// ResolveCommentThread is the resolver for the resolveCommentThread field.
+func (r *mutationResolver) ResolveCommentThread(ctx context.Context, threadID string) (bool, error) {
+	return r.setThreadResolved(ctx, threadID, true)
+}
+
+// ReopenCommentThread is the resolver for the reopenCommentThread field.
+func (r *mutationResolver) ReopenCommentThread(ctx context.Context, threadID string) (bool, error) {
+	return r.setThreadResolved(ctx, threadID, false)
+}
+
+// CommentThreads is the resolver for the commentThreads field.
+func (r *queryResolver) CommentThreads(ctx context.Context, projectID string, includeResolved *bool) ([]*model.CommentThread, error) {
+	project, err := r.getAccessibleProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if project == nil {
+		return nil, fmt.Errorf("project not found or access denied")
+	}
+	threads, err := r.Repo.Comment.GetThreadsByProject(ctx, project.ID, includeResolved != nil && *includeResolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch comment threads: %v", err)
+	}
+	threadIDs := make([]bson.ObjectID, 0, len(threads))
+	for _, t := range threads {
+		threadIDs = append(threadIDs, t.ID)
+	}
+	comments, err := r.Repo.Comment.GetCommentsByThreads(ctx, threadIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch comments: %v", err)
+	}
+	byThread := make(map[bson.ObjectID][]*models.Comment)
+	for _, c := range comments {
+		byThread[c.ThreadID] = append(byThread[c.ThreadID], c)
+	}
+
+	result := make([]*model.CommentThread, 0, len(threads))
+	for _, t := range threads {
+		result = append(result, convertCommentThreadToModel(t, byThread[t.ID]))
+	}
+	return result, nil
+}
+
+// ProjectComments is the resolver for the projectComments field.
+func (r *subscriptionResolver) ProjectComments(ctx context.Context, projectID string) (<-chan *model.CommentEvent, error) {
+	project, err := r.getAccessibleProject(ctx, projectID)
+	if err != nil || project == nil {
+		return nil, fmt.Errorf("project not found or access denied")
+	}
+
+	ch := make(chan *model.CommentEvent, 32)
+	socketID := r.subscribeToComments(projectID, holderForContext(ctx), ch)
+
+	go func(socketID string) {
+		<-ctx.Done()
+		r.unsubscribeFromComments(projectID, socketID)
+	}(socketID)
+
+	return ch, nil
+}
+
+// getAccessibleThread fetches a comment thread of a project the current user
+// can access.
+func (r *Resolver) getAccessibleThread(ctx context.Context, threadID string) (*models.CommentThread, *models.Project, error) {
+	thread, err := r.Repo.Comment.GetThread(ctx, threadID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch comment thread: %v", err)
+	}
+	if thread == nil {
+		return nil, nil, fmt.Errorf("comment thread not found")
+	}
+	project, err := r.getAccessibleProject(ctx, thread.ProjectID.Hex())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if project == nil {
+		return nil, nil, fmt.Errorf("comment thread not found")
+	}
+	return thread, project, nil
+}
+
+// getOwnComment fetches a comment written by the current user on a project
+// they can still access.
+func (r *Resolver) getOwnComment(ctx context.Context, id string) (*models.Comment, *models.Project, error) {
+	authContext := auth.ForContext(ctx)
+	comment, err := r.Repo.Comment.GetComment(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch comment: %v", err)
+	}
+	if comment == nil {
+		return nil, nil, fmt.Errorf("comment not found")
+	}
+	if comment.AuthorID != authContext.Sub {
+		return nil, nil, fmt.Errorf("you can only change your own comments")
+	}
+	project, err := r.getAccessibleProject(ctx, comment.ProjectID.Hex())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if project == nil {
+		return nil, nil, fmt.Errorf("comment not found")
+	}
+	return comment, project, nil
+}
+
+func (r *Resolver) setThreadResolved(ctx context.Context, threadID string, resolved bool) (bool, error) {
+	authContext := auth.ForContext(ctx)
+	thread, _, err := r.getAccessibleThread(ctx, threadID)
+	if err != nil {
+		return false, err
+	}
+	changed, err := r.Repo.Comment.SetResolved(ctx, thread.ID, resolved, authContext.Sub)
+	if err != nil {
+		return false, fmt.Errorf("failed to update comment thread: %v", err)
+	}
+	if changed {
+		r.publishThreadUpdate(ctx, thread.ProjectID.Hex(), threadID)
+	}
+	return changed, nil
+}
+
+// syncCommentAnchors flags threads anchored to elements removed by DELETE ops
+// as orphaned, and clears the flag when an element is added back.
+func (r *Resolver) syncCommentAnchors(ctx context.Context, projectID bson.ObjectID, ops []*models.Operation) {
+	var deleted, added []string
+	for _, op := range ops {
+		switch op.Type {
+		case "DELETE":
+			deleted = append(deleted, op.ElementID)
+		case "ADD":
+			added = append(added, op.ElementID)
+		}
+	}
+	for orphaned, elementIDs := range map[bool][]string{true: deleted, false: added} {
+		threadIDs, err := r.Repo.Comment.SetOrphaned(ctx, projectID, elementIDs, orphaned)
+		if err != nil {
+			fmt.Printf("Warning: failed to update comment anchors on project %s: %v\n", projectID.Hex(), err)
+			continue
+		}
+		for _, id := range threadIDs {
+			r.publishThreadUpdate(ctx, projectID.Hex(), id.Hex())
+		}
+	}
+}
+
+// enqueueCommentWebhook queues COMMENT_CREATED for workspace projects.
+func (r *Resolver) enqueueCommentWebhook(ctx context.Context, project *models.Project, comment *model.Comment) {
+	if project.Workspace == nil {
+		return
+	}
+	r.Webhooks.Enqueue(ctx, *project.Workspace, models.WebhookEventCommentCreated, map[string]any{
+		"projectId": project.ID.Hex(),
+		"comment":   comment,
+	})
+}
+
+// notifyMentions tells mentioned users about a comment on the project.
+func (r *Resolver) notifyMentions(ctx context.Context, project *models.Project, threadID bson.ObjectID, mentions []string) {
+	authContext := auth.ForContext(ctx)
+	for _, userID := range mentions {
+		r.notify(ctx, &models.Notification{
+			UserID:      userID,
+			Type:        models.NotificationCommentMention,
+			WorkspaceID: project.Workspace,
+			ProjectID:   &project.ID,
+			TargetID:    threadID.Hex(),
+			Message:     fmt.Sprintf("%s mentioned you in a comment on %s", authContext.PreferredUsername, project.Name),
+		})
+	}
+}
+
+// validateMentions checks that every mentioned user can see the project and
+// removes duplicates.
+func (r *Resolver) validateMentions(ctx context.Context, project *models.Project, mentions []string) ([]string, error) {
+	if len(mentions) == 0 {
+		return []string{}, nil
+	}
+	allowed := append([]string{project.Owner}, project.Members...)
+	if project.Workspace != nil && !project.Restricted {
+		workspace, err := r.Repo.Workspace.GetWorkspace(ctx, project.Workspace.Hex())
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch workspace: %v", err)
+		}
+		if workspace != nil {
+			allowed = append(allowed, workspace.Owner)
+			allowed = append(allowed, workspace.Members...)
+		}
+	}
+	result := make([]string, 0, len(mentions))
+	for _, userID := range mentions {
+		if !slices.Contains(allowed, userID) {
+			return nil, fmt.Errorf("cannot mention user %s: not a member of this project", userID)
+		}
+		if !slices.Contains(result, userID) {
+			result = append(result, userID)
+		}
+	}
+	return result, nil
+}
+
+// publishThreadUpdate sends the current state of a thread to the project's
+// comment subscribers, or a deletion event when the thread is gone.
+func (r *Resolver) publishThreadUpdate(ctx context.Context, projectID string, threadID string) {
+	thread, err := r.Repo.Comment.GetThread(ctx, threadID)
+	if err != nil {
+		fmt.Printf("Warning: failed to fetch comment thread %s: %v\n", threadID, err)
+		return
+	}
+	if thread == nil {
+		r.broadcastComment(projectID, &model.CommentEvent{
+			Type:     model.CommentEventTypeThreadDeleted,
+			ThreadID: threadID,
+		})
+		return
+	}
+	comments, err := r.Repo.Comment.GetCommentsByThreads(ctx, []bson.ObjectID{thread.ID})
+	if err != nil {
+		fmt.Printf("Warning: failed to fetch comments of thread %s: %v\n", threadID, err)
+		return
+	}
+	result := convertCommentThreadToModel(thread, comments)
+	r.broadcastComment(projectID, &model.CommentEvent{
+		Type:     model.CommentEventTypeThreadUpdated,
+		ThreadID: result.ID,
+		Thread:   result,
+	})
+}
+
+func convertCommentThreadToModel(thread *models.CommentThread, comments []*models.Comment) *model.CommentThread {
+	result := &model.CommentThread{
+		ID:        thread.ID.Hex(),
+		ProjectID: thread.ProjectID.Hex(),
+		Anchor: &model.CommentAnchor{
+			X:        thread.X,
+			Y:        thread.Y,
+			Orphaned: thread.Orphaned,
+		},
+		CreatedBy: thread.CreatedBy,
+		Resolved:  thread.ResolvedAt != "",
+		Comments:  make([]*model.Comment, 0, len(comments)),
+		CreatedAt: thread.CreatedAt,
+		UpdatedAt: thread.UpdatedAt,
+	}
+	if thread.ElementID != "" {
+		result.Anchor.ElementID = &thread.ElementID
+	}
+	if thread.ResolvedAt != "" {
+		result.ResolvedAt = &thread.ResolvedAt
+		result.ResolvedBy = &thread.ResolvedBy
+	}
+	for _, c := range comments {
+		result.Comments = append(result.Comments, convertCommentToModel(c))
+	}
+	return result
+}
+
+func convertCommentToModel(comment *models.Comment) *model.Comment {
+	result := &model.Comment{
+		ID:         comment.ID.Hex(),
+		ThreadID:   comment.ThreadID.Hex(),
+		AuthorID:   comment.AuthorID,
+		AuthorName: comment.AuthorName,
+		Body:       comment.Body,
+		Mentions:   comment.Mentions,
+		CreatedAt:  comment.CreatedAt,
+	}
+	if result.Mentions == nil {
+		result.Mentions = []string{}
+	}
+	if comment.EditedAt != "" {
+		result.EditedAt = &comment.EditedAt
+	}
+	return result
+}
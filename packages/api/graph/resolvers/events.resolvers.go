@@ -0,0 +1,62 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chirag3003/collab-draw-backend/graph/model"
+	"github.com/chirag3003/collab-draw-backend/internal/auth"
+)
+
+// WorkspaceEvents is the resolver for the workspaceEvents field.
+func (r *subscriptionResolver) WorkspaceEvents(ctx context.Context, workspaceID string) (<-chan *model.Event, error) {
+	authContext := auth.ForContext(ctx)
+	if authContext == nil || auth.IsGuest(ctx) {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	if !workspaceInScope(ctx, workspaceID) {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	workspace, err := r.Repo.Workspace.GetWorkspaceByID(ctx, workspaceID, authContext.Sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workspace: %v", err)
+	}
+	if workspace == nil {
+		return nil, fmt.Errorf("workspace not found")
+	}
+
+	ch := make(chan *model.Event, 32)
+	socketID := r.subscribeToWorkspaceEvents(workspaceID, authContext.Sub, ch)
+
+	go func(socketID string) {
+		<-ctx.Done()
+		r.unsubscribeFromEvents(r.workspaceEventSubs, workspaceID, socketID)
+	}(socketID)
+
+	return ch, nil
+}
+
+// MyEvents is the resolver for the myEvents field.
+func (r *subscriptionResolver) MyEvents(ctx context.Context) (<-chan *model.Event, error) {
+	authContext := auth.ForContext(ctx)
+	if authContext == nil || auth.IsGuest(ctx) {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	if scopeRestricted(ctx) {
+		return nil, fmt.Errorf("myEvents is not available to scoped access tokens")
+	}
+
+	ch := make(chan *model.Event, 32)
+	socketID := r.subscribeToUserEvents(authContext.Sub, ch)
+
+	go func(socketID string) {
+		<-ctx.Done()
+		r.unsubscribeFromEvents(r.userEventSubs, authContext.Sub, socketID)
+	}(socketID)
+
+	return ch, nil
+}
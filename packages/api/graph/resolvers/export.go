@@ -0,0 +1,104 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/export"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"github.com/go-chi/chi"
+)
+
+// unsafeFilename matches characters replaced in download file names.
+var unsafeFilename = regexp.MustCompile(`[^\w\- ]+`)
+
+// fileExtensions maps export formats to the extension of the downloaded file.
+var fileExtensions = map[string]string{
+	export.FormatExcalidraw: ".excalidraw",
+	export.FormatSVG:        ".svg",
+	export.FormatPNG:        ".png",
+}
+
+// ServeExport handles GET /export/{projectID}. The request is authorized
+// either by a signed link from the exportProject query or by the usual API
+// credentials, in which case auth.Middleware must have run.
+func (r *Resolver) ServeExport(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	projectID := chi.URLParam(req, "projectID")
+	query := req.URL.Query()
+
+	var project *models.Project
+	var err error
+	if query.Has("sig") {
+		if err := export.VerifySignature(projectID, query, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		project, err = r.Repo.Project.GetProject(ctx, projectID)
+	} else {
+		project, err = r.getAccessibleProject(ctx, projectID)
+	}
+	if err != nil {
+		http.Error(w, "failed to fetch project", http.StatusInternalServerError)
+		return
+	}
+	if project == nil {
+		http.Error(w, "project not found or access denied", http.StatusNotFound)
+		return
+	}
+
+	exportReq, err := export.ParseRequest(projectID, query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	body, err := r.renderExport(ctx, project, exportReq)
+	if err != nil {
+		log.Printf("Warning: export of project %s failed: %v", projectID, err)
+		http.Error(w, "failed to export project", http.StatusInternalServerError)
+		return
+	}
+
+	filename := unsafeFilename.ReplaceAllString(project.Name, "_")
+	if filename == "" {
+		filename = "drawing"
+	}
+	w.Header().Set("Content-Type", export.ContentTypes[exportReq.Format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s%s"`, filename, fileExtensions[exportReq.Format]))
+	w.Header().Set("Cache-Control", "private, no-store")
+	_, _ = w.Write(body)
+}
+
+// renderExport renders the project, or its state at a historical seq, in the
+// requested format.
+func (r *Resolver) renderExport(ctx context.Context, project *models.Project, req *export.Request) ([]byte, error) {
+	elements := project.Elements
+	if req.Seq != nil {
+		var err error
+		elements, _, _, err = r.Repo.Operation.ReconstructStateAt(ctx, project.ID.Hex(), *req.Seq, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to reconstruct snapshot: %v", err)
+		}
+	}
+	scene, err := export.NewScene(elements, req.ElementIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.Format {
+	case export.FormatSVG:
+		return export.RenderSVG(scene), nil
+	case export.FormatPNG:
+		scale := req.Scale
+		if scale == 0 {
+			scale = 1
+		}
+		return export.RenderPNG(scene, scale)
+	default:
+		return export.RenderExcalidraw(scene, "collab-draw")
+	}
+}
@@ -0,0 +1,49 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/graph/model"
+	"github.com/chirag3003/collab-draw-backend/internal/export"
+)
+
+// ExportProject is the resolver for the exportProject field.
+func (r *queryResolver) ExportProject(ctx context.Context, input model.ExportInput) (*model.ExportLink, error) {
+	project, err := r.getAccessibleProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if project == nil {
+		return nil, fmt.Errorf("project not found or access denied")
+	}
+
+	req := &export.Request{
+		ProjectID:  project.ID.Hex(),
+		Format:     strings.ToLower(string(input.Format)),
+		Seq:        input.Seq,
+		ElementIDs: input.ElementIDs,
+	}
+	if input.Scale != nil {
+		req.Scale = *input.Scale
+	}
+	// Validate the same way the download endpoint will
+	if _, err := export.ParseRequest(req.ProjectID, req.Query()); err != nil {
+		return nil, err
+	}
+	if req.Seq != nil && int64(*req.Seq) > project.HeadSeq {
+		return nil, fmt.Errorf("seq %d is ahead of the project head %d", *req.Seq, project.HeadSeq)
+	}
+
+	now := time.Now()
+	return &model.ExportLink{
+		URL:       req.SignedPath(now),
+		ExpiresAt: now.Add(export.LinkTTL).UTC().Format(time.RFC3339),
+	}, nil
+}
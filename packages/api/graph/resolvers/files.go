@@ -0,0 +1,106 @@
+package resolvers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/chirag3003/collab-draw-backend/internal/auth"
+	"github.com/chirag3003/collab-draw-backend/internal/filestore"
+	"github.com/chirag3003/collab-draw-backend/internal/quota"
+	"github.com/go-chi/chi"
+)
+
+// ServeFileUpload handles PUT /projects/{projectID}/files/{fileID}. The body is
+// the raw file contents. auth.Middleware must have run.
+func (r *Resolver) ServeFileUpload(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	project, err := r.getEditableProject(ctx, chi.URLParam(req, "projectID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	// Uploads without a declared length are refused once the quota is used up
+	if err := r.Quotas.CheckStorage(ctx, project.Workspace, max(req.ContentLength, 1)); err != nil {
+		var exceeded *quota.ExceededError
+		if errors.As(err, &exceeded) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		log.Printf("Warning: storage quota check for project %s failed: %v", project.ID.Hex(), err)
+		http.Error(w, "failed to check storage quota", http.StatusInternalServerError)
+		return
+	}
+
+	file, err := r.Files.Upload(ctx, project.ID, chi.URLParam(req, "fileID"), req.Header.Get("Content-Type"), req.Body, auth.ForContext(ctx).Sub)
+	switch {
+	case errors.Is(err, filestore.ErrTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	case errors.Is(err, filestore.ErrUnsupportedType):
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	case errors.Is(err, filestore.ErrInvalidFileID):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, filestore.ErrFileIDConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		log.Printf("Warning: file upload to project %s failed: %v", project.ID.Hex(), err)
+		http.Error(w, "failed to store file", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"id":       file.FileID,
+		"mimeType": file.MimeType,
+		"size":     file.Size,
+	})
+}
+
+// ServeFile handles GET /projects/{projectID}/files/{fileID}.
+// auth.Middleware must have run.
+func (r *Resolver) ServeFile(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	project, err := r.getAccessibleProject(ctx, chi.URLParam(req, "projectID"))
+	if err != nil {
+		http.Error(w, "failed to fetch project", http.StatusInternalServerError)
+		return
+	}
+	if project == nil {
+		http.Error(w, "project not found or access denied", http.StatusNotFound)
+		return
+	}
+
+	file, contents, err := r.Files.Open(ctx, project.ID, chi.URLParam(req, "fileID"))
+	if errors.Is(err, filestore.ErrNotFound) || (err == nil && file == nil) {
+		http.Error(w, "file not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Warning: failed to open file of project %s: %v", project.ID.Hex(), err)
+		http.Error(w, "failed to read file", http.StatusInternalServerError)
+		return
+	}
+	defer contents.Close()
+
+	etag := `"` + file.Hash + `"`
+	w.Header().Set("ETag", etag)
+	// A fileId always refers to the same contents
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	if req.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", file.MimeType)
+	w.Header().Set("Content-Length", strconv.FormatInt(file.Size, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	// SVGs can carry scripts; never let them run in our origin
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	_, _ = io.Copy(w, contents)
+}
@@ -0,0 +1,374 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/chirag3003/collab-draw-backend/graph/model"
+	"github.com/chirag3003/collab-draw-backend/internal/auth"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"github.com/chirag3003/collab-draw-backend/internal/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// maxFolderDepth bounds how deeply folders nest.
+const maxFolderDepth = 10
+
+// maxProjectTags bounds how many tags a project can have.
+const maxProjectTags = 20
+
+// CreateFolder is the resolver for the createFolder field.
+func (r *mutationResolver) CreateFolder(ctx context.Context, workspaceID string, name string, parentID *string) (*model.Folder, error) {
+	authContext := auth.ForContext(ctx)
+	workspace, err := r.getMemberWorkspace(ctx, workspaceID, true)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("folder name is required")
+	}
+	folder := &models.Folder{
+		WorkspaceID: workspace.ID,
+		Name:        name,
+		CreatedBy:   authContext.Sub,
+	}
+	if parentID != nil {
+		parent, err := r.getWorkspaceFolder(ctx, *parentID, workspace.ID)
+		if err != nil {
+			return nil, err
+		}
+		depth, err := r.folderDepth(ctx, parent)
+		if err != nil {
+			return nil, err
+		}
+		if depth >= maxFolderDepth {
+			return nil, fmt.Errorf("folders cannot be nested more than %d levels deep", maxFolderDepth)
+		}
+		folder.ParentID = &parent.ID
+	}
+	err = r.Repo.Folder.CreateFolder(ctx, folder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create folder: %v", err)
+	}
+	return convertFolderToModel(folder), nil
+}
+
+// RenameFolder is the resolver for the renameFolder field.
+func (r *mutationResolver) RenameFolder(ctx context.Context, id string, name string) (*model.Folder, error) {
+	folder, err := r.getEditableFolder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("folder name is required")
+	}
+	err = r.Repo.Folder.RenameFolder(ctx, folder.ID, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename folder: %v", err)
+	}
+	folder.Name = name
+	return convertFolderToModel(folder), nil
+}
+
+// MoveFolder is the resolver for the moveFolder field.
+func (r *mutationResolver) MoveFolder(ctx context.Context, id string, parentID *string) (*model.Folder, error) {
+	folder, err := r.getEditableFolder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var parent *models.Folder
+	if parentID != nil {
+		parent, err = r.getWorkspaceFolder(ctx, *parentID, folder.WorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		// Walk up from the new parent; finding the folder itself means a cycle
+		depth := 1
+		for ancestor := parent; ancestor != nil; depth++ {
+			if ancestor.ID == folder.ID {
+				return nil, fmt.Errorf("a folder cannot be moved into itself")
+			}
+			if ancestor.ParentID == nil {
+				break
+			}
+			ancestor, err = r.Repo.Folder.GetFolder(ctx, ancestor.ParentID.Hex())
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch folder: %v", err)
+			}
+		}
+		if depth >= maxFolderDepth {
+			return nil, fmt.Errorf("folders cannot be nested more than %d levels deep", maxFolderDepth)
+		}
+		folder.ParentID = &parent.ID
+	} else {
+		folder.ParentID = nil
+	}
+	err = r.Repo.Folder.MoveFolder(ctx, folder.ID, folder.ParentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move folder: %v", err)
+	}
+	return convertFolderToModel(folder), nil
+}
+
+// DeleteFolder is the resolver for the deleteFolder field.
+func (r *mutationResolver) DeleteFolder(ctx context.Context, id string) (bool, error) {
+	folder, err := r.getEditableFolder(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	err = r.Repo.Folder.DeleteFolder(ctx, folder)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete folder: %v", err)
+	}
+	return true, nil
+}
+
+// MoveProjectToFolder is the resolver for the moveProjectToFolder field.
+func (r *mutationResolver) MoveProjectToFolder(ctx context.Context, projectID string, folderID *string) (bool, error) {
+	project, err := r.getEditableProject(ctx, projectID)
+	if err != nil {
+		return false, err
+	}
+	var target *bson.ObjectID
+	if folderID != nil {
+		if project.Workspace == nil {
+			return false, fmt.Errorf("only workspace projects can be put in folders")
+		}
+		folder, err := r.getWorkspaceFolder(ctx, *folderID, *project.Workspace)
+		if err != nil {
+			return false, err
+		}
+		target = &folder.ID
+	}
+	err = r.Repo.Project.SetFolder(ctx, projectID, target)
+	if err != nil {
+		return false, fmt.Errorf("failed to move project: %v", err)
+	}
+	return true, nil
+}
+
+// SetProjectTags is the resolver for the setProjectTags field.
+func (r *mutationResolver) SetProjectTags(ctx context.Context, projectID string, tags []string) ([]string, error) {
+	project, err := r.getEditableProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	tags = normalizeTags(tags)
+	if len(tags) > maxProjectTags {
+		return nil, fmt.Errorf("a project can have at most %d tags", maxProjectTags)
+	}
+	err = r.Repo.Project.SetTags(ctx, projectID, tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update tags: %v", err)
+	}
+	r.recordAudit(ctx, "project.set_tags", "project", projectID, project.Workspace,
+		bson.M{"tags": project.Tags}, bson.M{"tags": tags})
+	return tags, nil
+}
+
+// SetProjectFavorite is the resolver for the setProjectFavorite field.
+func (r *mutationResolver) SetProjectFavorite(ctx context.Context, projectID string, favorite bool) (bool, error) {
+	if auth.IsGuest(ctx) {
+		return false, fmt.Errorf("guests cannot star projects")
+	}
+	project, err := r.getAccessibleProject(ctx, projectID)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if project == nil {
+		return false, fmt.Errorf("project not found or access denied")
+	}
+	err = r.Repo.ProjectState.SetFavorite(ctx, auth.ForContext(ctx).Sub, project.ID, favorite)
+	if err != nil {
+		return false, fmt.Errorf("failed to update favorite: %v", err)
+	}
+	return true, nil
+}
+
+// Folders is the resolver for the folders field.
+func (r *queryResolver) Folders(ctx context.Context, workspaceID string) ([]*model.Folder, error) {
+	workspace, err := r.getMemberWorkspace(ctx, workspaceID, false)
+	if err != nil {
+		return nil, err
+	}
+	folders, err := r.Repo.Folder.GetFoldersByWorkspace(ctx, workspace.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch folders: %v", err)
+	}
+	result := make([]*model.Folder, 0, len(folders))
+	for _, folder := range folders {
+		result = append(result, convertFolderToModel(folder))
+	}
+	return result, nil
+}
+
+// FavoriteProjects is the resolver for the favoriteProjects field.
+func (r *queryResolver) FavoriteProjects(ctx context.Context) ([]*model.Project, error) {
+	authContext := auth.ForContext(ctx)
+	ids, err := r.Repo.ProjectState.GetFavoriteIDs(ctx, authContext.Sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch favorites: %v", err)
+	}
+	return r.projectsInOrder(ctx, ids)
+}
+
+// RecentProjects is the resolver for the recentProjects field.
+func (r *queryResolver) RecentProjects(ctx context.Context, limit *int32) ([]*model.Project, error) {
+	authContext := auth.ForContext(ctx)
+	size := 10
+	if limit != nil && *limit > 0 && *limit <= 50 {
+		size = int(*limit)
+	}
+	states, err := r.Repo.ProjectState.GetRecent(ctx, authContext.Sub, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recent projects: %v", err)
+	}
+	ids := make([]bson.ObjectID, 0, len(states))
+	for _, state := range states {
+		ids = append(ids, state.ProjectID)
+	}
+	return r.projectsInOrder(ctx, ids)
+}
+
+// buildProjectQuery turns the filter, sort and paging arguments of project
+// list queries into a repository query.
+func (r *Resolver) buildProjectQuery(ctx context.Context, filter *model.ProjectFilter, sort *model.ProjectSort, first *int32, after *string) (*repository.ProjectQuery, error) {
+	query := &repository.ProjectQuery{}
+	if filter != nil {
+		if filter.FolderID != nil {
+			folderID, err := bson.ObjectIDFromHex(*filter.FolderID)
+			if err != nil {
+				return nil, fmt.Errorf("folder not found")
+			}
+			query.FolderID = &folderID
+		}
+		query.Unfiled = filter.Unfiled != nil && *filter.Unfiled
+		query.Tags = normalizeTags(filter.Tags)
+		if filter.Name != nil {
+			query.Name = strings.TrimSpace(*filter.Name)
+		}
+		if filter.Favorite != nil {
+			ids, err := r.Repo.ProjectState.GetFavoriteIDs(ctx, auth.ForContext(ctx).Sub)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch favorites: %v", err)
+			}
+			if *filter.Favorite {
+				query.IDs = append([]bson.ObjectID{}, ids...)
+			} else {
+				query.ExcludeIDs = ids
+			}
+		}
+	}
+	if sort != nil {
+		switch sort.Field {
+		case model.ProjectSortFieldName:
+			query.SortBy = repository.ProjectSortName
+		case model.ProjectSortFieldCreatedAt:
+			query.SortBy = repository.ProjectSortCreatedAt
+		default:
+			query.SortBy = repository.ProjectSortUpdatedAt
+		}
+		query.Descending = sort.Direction != nil && *sort.Direction == model.SortDirectionDesc
+	} else {
+		query.SortBy = repository.ProjectSortUpdatedAt
+		query.Descending = true
+	}
+	if first != nil {
+		if *first <= 0 || *first > 100 {
+			return nil, fmt.Errorf("first must be between 1 and 100")
+		}
+		query.Limit = int(*first)
+	}
+	if after != nil {
+		query.After = *after
+	}
+	return query, nil
+}
+
+// projectsInOrder fetches the projects the user can access, keeping the order
+// of ids and skipping the rest.
+func (r *Resolver) projectsInOrder(ctx context.Context, ids []bson.ObjectID) ([]*model.Project, error) {
+	projects, err := r.Repo.Project.GetProjectsByIDs(ctx, ids, auth.ForContext(ctx).Sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch projects: %v", err)
+	}
+	result := []*model.Project{}
+	for _, id := range ids {
+		index := slices.IndexFunc(projects, func(p *models.Project) bool { return p.ID == id })
+		if index < 0 || !projectInScope(ctx, projects[index]) {
+			continue
+		}
+		result = append(result, convertProjectToModel(projects[index]))
+	}
+	return result, nil
+}
+
+// getWorkspaceFolder fetches a folder of the workspace.
+func (r *Resolver) getWorkspaceFolder(ctx context.Context, id string, workspaceID bson.ObjectID) (*models.Folder, error) {
+	folder, err := r.Repo.Folder.GetFolder(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch folder: %v", err)
+	}
+	if folder == nil || folder.WorkspaceID != workspaceID {
+		return nil, fmt.Errorf("folder not found")
+	}
+	return folder, nil
+}
+
+// getEditableFolder fetches a folder whose workspace the current user may
+// make changes in.
+func (r *Resolver) getEditableFolder(ctx context.Context, id string) (*models.Folder, error) {
+	folder, err := r.Repo.Folder.GetFolder(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch folder: %v", err)
+	}
+	if folder == nil {
+		return nil, fmt.Errorf("folder not found")
+	}
+	if _, err := r.getMemberWorkspace(ctx, folder.WorkspaceID.Hex(), true); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+// folderDepth returns how many folders deep the folder is, 1 at the top level.
+func (r *Resolver) folderDepth(ctx context.Context, folder *models.Folder) (int, error) {
+	depth := 1
+	for folder.ParentID != nil && depth <= maxFolderDepth {
+		parent, err := r.Repo.Folder.GetFolder(ctx, folder.ParentID.Hex())
+		if err != nil {
+			return 0, fmt.Errorf("failed to fetch folder: %v", err)
+		}
+		if parent == nil {
+			break
+		}
+		folder = parent
+		depth++
+	}
+	return depth, nil
+}
+
+func convertFolderToModel(folder *models.Folder) *model.Folder {
+	var parent *string
+	if folder.ParentID != nil {
+		hex := folder.ParentID.Hex()
+		parent = &hex
+	}
+	return &model.Folder{
+		ID:          folder.ID.Hex(),
+		WorkspaceID: folder.WorkspaceID.Hex(),
+		ParentID:    parent,
+		Name:        folder.Name,
+		CreatedBy:   folder.CreatedBy,
+		CreatedAt:   folder.CreatedAt,
+		UpdatedAt:   folder.UpdatedAt,
+	}
+}
@@ -0,0 +1,196 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chirag3003/collab-draw-backend/graph/model"
+	"github.com/chirag3003/collab-draw-backend/internal/auth"
+	"github.com/chirag3003/collab-draw-backend/internal/importer"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ImportProject is the resolver for the importProject field.
+func (r *mutationResolver) ImportProject(ctx context.Context, input model.ImportInput) (*model.ImportResult, error) {
+	if input.Workspace == nil && scopeRestricted(ctx) {
+		return nil, fmt.Errorf("access token cannot create projects outside its scope")
+	}
+	var workspace *models.Workspace
+	if input.Workspace != nil {
+		var err error
+		workspace, err = r.getOwnedWorkspace(ctx, *input.Workspace)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	kind := importer.KindOf(input.File.Filename)
+	if kind == "" {
+		return nil, fmt.Errorf("unsupported file type, expected .excalidraw, .excalidrawlib or .zip")
+	}
+	data, err := importer.ReadAll(input.File.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %v", err)
+	}
+
+	files := []importer.File{{Name: input.File.Filename, Data: data}}
+	if kind == importer.KindZip {
+		if workspace == nil {
+			return nil, fmt.Errorf("bulk imports must target a workspace")
+		}
+		files, err = importer.ReadZip(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	personal := input.Personal != nil && *input.Personal
+	result := &model.ImportResult{
+		Projects:  []*model.Project{},
+		Libraries: []*model.Library{},
+		Failed:    []*model.ImportFailure{},
+	}
+	for _, file := range files {
+		name := importer.BaseName(file.Name)
+		if kind != importer.KindZip && input.Name != nil && strings.TrimSpace(*input.Name) != "" {
+			name = strings.TrimSpace(*input.Name)
+		}
+
+		var err error
+		switch importer.KindOf(file.Name) {
+		case importer.KindDrawing:
+			var project *models.Project
+			project, err = r.importDrawing(ctx, file, name, workspace, personal)
+			if err == nil {
+				result.Projects = append(result.Projects, convertProjectToModel(project))
+			}
+		case importer.KindLibrary:
+			var library *models.Library
+			library, err = r.importLibrary(ctx, file, name, workspace, nil)
+			if err == nil {
+				result.Libraries = append(result.Libraries, convertLibraryToModel(library))
+			}
+		}
+		if err != nil {
+			// A single file either imports or fails the whole request
+			if kind != importer.KindZip {
+				return nil, err
+			}
+			result.Failed = append(result.Failed, &model.ImportFailure{
+				FileName: file.Name,
+				Reason:   err.Error(),
+			})
+		}
+	}
+	return result, nil
+}
+
+// importDrawing creates a project from a .excalidraw file. Its elements are
+// written as ADD ops so the op log explains the initial state.
+func (r *Resolver) importDrawing(ctx context.Context, file importer.File, name string, workspace *models.Workspace, personal bool) (*models.Project, error) {
+	authContext := auth.ForContext(ctx)
+	drawing, err := importer.ParseDrawing(file.Data)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = "Untitled"
+	}
+
+	project := &models.Project{
+		Name:     name,
+		Elements: "",
+		Owner:    authContext.Sub,
+		Members:  []string{},
+		Personal: personal,
+	}
+	if workspace != nil {
+		project.Workspace = &workspace.ID
+	}
+	err = r.seedProject(ctx, project, drawing.Elements, "import")
+	if err != nil {
+		return nil, err
+	}
+
+	r.recordAudit(ctx, "project.create", "project", project.ID.Hex(), project.Workspace,
+		nil, bson.M{"name": project.Name, "imported_from": file.Name})
+	r.publishProjectEvent(ctx, model.EventTypeProjectCreated, project)
+	return project, nil
+}
+
+// importLibrary stores a .excalidrawlib file as a workspace library, or adds
+// its items to an existing library of the workspace.
+func (r *Resolver) importLibrary(ctx context.Context, file importer.File, name string, workspace *models.Workspace, existing *models.Library) (*models.Library, error) {
+	authContext := auth.ForContext(ctx)
+	if workspace == nil {
+		return nil, fmt.Errorf("libraries must be imported into a workspace")
+	}
+	parsed, err := importer.ParseLibrary(file.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	taken := make(map[string]bool)
+	if existing != nil {
+		for _, item := range existing.Items {
+			taken[item.ID] = true
+		}
+	}
+	items := make([]models.LibraryItem, 0, len(parsed.Items))
+	for _, parsedItem := range parsed.Items {
+		item := models.LibraryItem{
+			ID:        parsedItem.ID,
+			Name:      parsedItem.Name,
+			Tags:      normalizeTags(parsedItem.Tags),
+			Elements:  parsedItem.Elements,
+			CreatedBy: authContext.Sub,
+		}
+		if item.ID == "" || taken[item.ID] {
+			item.ID = bson.NewObjectID().Hex()
+		}
+		taken[item.ID] = true
+		items = append(items, item)
+	}
+
+	if existing != nil {
+		if err := r.Repo.Library.AddItems(ctx, existing.ID, items); err != nil {
+			return nil, fmt.Errorf("failed to import library items: %v", err)
+		}
+		library, err := r.Repo.Library.GetLibrary(ctx, existing.ID.Hex())
+		if err != nil || library == nil {
+			return nil, fmt.Errorf("failed to fetch library: %v", err)
+		}
+		r.recordAudit(ctx, "workspace.import_library", "workspace", workspace.ID.Hex(), &workspace.ID,
+			nil, bson.M{"library": library.ID.Hex(), "name": library.Name, "imported_from": file.Name, "items": len(items)})
+		r.publishLibraryEvent(model.LibraryEventTypeUpdated, library)
+		return library, nil
+	}
+
+	if name == "" {
+		name = "Library"
+	}
+	library := &models.Library{
+		WorkspaceID: workspace.ID,
+		Name:        name,
+		CreatedBy:   authContext.Sub,
+	}
+	if err := r.Repo.Library.CreateLibrary(ctx, library); err != nil {
+		return nil, fmt.Errorf("failed to create library: %v", err)
+	}
+	if len(items) > 0 {
+		if err := r.Repo.Library.AddItems(ctx, library.ID, items); err != nil {
+			return nil, fmt.Errorf("failed to import library items: %v", err)
+		}
+		library.Items = items
+	}
+	r.recordAudit(ctx, "workspace.import_library", "workspace", workspace.ID.Hex(), &workspace.ID,
+		nil, bson.M{"library": library.ID.Hex(), "name": library.Name, "imported_from": file.Name, "items": len(items)})
+	r.publishLibraryEvent(model.LibraryEventTypeCreated, library)
+	return library, nil
+}
@@ -0,0 +1,276 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/graph/model"
+	"github.com/chirag3003/collab-draw-backend/internal/auth"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"github.com/chirag3003/collab-draw-backend/internal/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// InviteToWorkspace is the resolver for the inviteToWorkspace field.
+func (r *mutationResolver) InviteToWorkspace(ctx context.Context, workspaceID string, email string, role model.WorkspaceRole) (*model.CreateInvitationResult, error) {
+	authContext := auth.ForContext(ctx)
+	if !workspaceInScope(ctx, workspaceID) {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	workspace, err := r.Repo.Workspace.GetWorkspaceByID(ctx, workspaceID, authContext.Sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workspace: %v", err)
+	}
+	if workspace == nil || workspace.Owner != authContext.Sub {
+		return nil, fmt.Errorf("workspace not found")
+	}
+
+	token, invitation, err := r.inviteToWorkspace(ctx, workspace, email, string(role))
+	if err != nil {
+		return nil, err
+	}
+	return &model.CreateInvitationResult{
+		Token:      token,
+		Invitation: convertInvitationToModel(invitation, workspace.Name),
+	}, nil
+}
+
+// AcceptInvitation is the resolver for the acceptInvitation field.
+func (r *mutationResolver) AcceptInvitation(ctx context.Context, id string, token *string) (bool, error) {
+	authContext := auth.ForContext(ctx)
+	invitation, err := r.getInvitationForRecipient(ctx, id, token)
+	if err != nil {
+		return false, err
+	}
+
+	accepted, err := r.Repo.Invitation.SetStatus(ctx, invitation.ID, models.InvitationAccepted, authContext.Sub)
+	if err != nil {
+		return false, fmt.Errorf("failed to accept invitation: %v", err)
+	}
+	if !accepted {
+		return false, fmt.Errorf("invitation is no longer pending")
+	}
+
+	workspace, err := r.Repo.Workspace.GetWorkspace(ctx, invitation.WorkspaceID.Hex())
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch workspace: %v", err)
+	}
+	if workspace == nil {
+		return false, fmt.Errorf("workspace not found")
+	}
+	if workspace.RoleOf(authContext.Sub) == "" {
+		err = r.Repo.Workspace.AddMemberToWorkspace(ctx, workspace.ID.Hex(), authContext.Sub, invitation.Role)
+		if err != nil {
+			return false, fmt.Errorf("failed to add member to workspace: %v", err)
+		}
+		r.publishMemberEvent(ctx, model.EventTypeMemberAdded, &workspace.ID, nil, authContext.Sub)
+		r.notify(ctx, &models.Notification{
+			UserID:      workspace.Owner,
+			Type:        models.NotificationWorkspaceJoined,
+			WorkspaceID: &workspace.ID,
+			Message:     fmt.Sprintf("%s joined %s", authContext.PreferredUsername, workspace.Name),
+		})
+	}
+	return true, nil
+}
+
+// DeclineInvitation is the resolver for the declineInvitation field.
+func (r *mutationResolver) DeclineInvitation(ctx context.Context, id string, token *string) (bool, error) {
+	authContext := auth.ForContext(ctx)
+	invitation, err := r.getInvitationForRecipient(ctx, id, token)
+	if err != nil {
+		return false, err
+	}
+	declined, err := r.Repo.Invitation.SetStatus(ctx, invitation.ID, models.InvitationDeclined, authContext.Sub)
+	if err != nil {
+		return false, fmt.Errorf("failed to decline invitation: %v", err)
+	}
+	return declined, nil
+}
+
+// RevokeInvitation is the resolver for the revokeInvitation field.
+func (r *mutationResolver) RevokeInvitation(ctx context.Context, id string) (bool, error) {
+	authContext := auth.ForContext(ctx)
+	invitation, err := r.Repo.Invitation.GetInvitationByID(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch invitation: %v", err)
+	}
+	if invitation == nil || !workspaceInScope(ctx, invitation.WorkspaceID.Hex()) {
+		return false, fmt.Errorf("invitation not found")
+	}
+	workspace, err := r.Repo.Workspace.GetWorkspaceByID(ctx, invitation.WorkspaceID.Hex(), authContext.Sub)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch workspace: %v", err)
+	}
+	if workspace == nil || workspace.Owner != authContext.Sub {
+		return false, fmt.Errorf("invitation not found")
+	}
+	revoked, err := r.Repo.Invitation.SetStatus(ctx, invitation.ID, models.InvitationRevoked, authContext.Sub)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke invitation: %v", err)
+	}
+	return revoked, nil
+}
+
+// WorkspaceInvitations is the resolver for the workspaceInvitations field.
+func (r *queryResolver) WorkspaceInvitations(ctx context.Context, workspaceID string) ([]*model.Invitation, error) {
+	authContext := auth.ForContext(ctx)
+	if !workspaceInScope(ctx, workspaceID) {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	workspace, err := r.Repo.Workspace.GetWorkspaceByID(ctx, workspaceID, authContext.Sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workspace: %v", err)
+	}
+	if workspace == nil || workspace.Owner != authContext.Sub {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	invitations, err := r.Repo.Invitation.GetPendingByWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch invitations: %v", err)
+	}
+	result := []*model.Invitation{}
+	for _, inv := range invitations {
+		result = append(result, convertInvitationToModel(inv, workspace.Name))
+	}
+	return result, nil
+}
+
+// MyInvitations is the resolver for the myInvitations field.
+func (r *queryResolver) MyInvitations(ctx context.Context) ([]*model.Invitation, error) {
+	authContext := auth.ForContext(ctx)
+	result := []*model.Invitation{}
+	if authContext.Email == "" || auth.ScopeForContext(ctx) != nil {
+		return result, nil
+	}
+	invitations, err := r.Repo.Invitation.GetPendingByEmail(ctx, authContext.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch invitations: %v", err)
+	}
+	for _, inv := range invitations {
+		workspace, err := r.Repo.Workspace.GetWorkspace(ctx, inv.WorkspaceID.Hex())
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch workspace: %v", err)
+		}
+		if workspace == nil {
+			continue
+		}
+		result = append(result, convertInvitationToModel(inv, workspace.Name))
+	}
+	return result, nil
+}
+
+// invitationTTL is how long an invitation stays valid.
+const invitationTTL = 7 * 24 * time.Hour
+
+// inviteToWorkspace creates a pending invitation, replacing any earlier one for
+// the same email. Emails without a Keycloak account are accepted automatically
+// on the first login of that account.
+func (r *Resolver) inviteToWorkspace(ctx context.Context, workspace *models.Workspace, email string, role string) (string, *models.Invitation, error) {
+	authContext := auth.ForContext(ctx)
+	email = repository.NormalizeEmail(email)
+	if email == "" {
+		return "", nil, fmt.Errorf("email cannot be empty")
+	}
+	if role != models.WorkspaceRoleEditor && role != models.WorkspaceRoleViewer {
+		return "", nil, fmt.Errorf("invalid role %s", role)
+	}
+
+	users, err := r.Repo.User.GetUserByEmail(ctx, email)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to fetch user by email: %v", err)
+	}
+	for _, user := range users {
+		if workspace.RoleOf(user.ID) != "" {
+			return "", nil, fmt.Errorf("user with email %s is already a member", email)
+		}
+	}
+
+	token, hash, err := auth.GenerateInvitationToken()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate invitation token: %v", err)
+	}
+
+	err = r.Repo.Invitation.RevokePending(ctx, workspace.ID, email)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to replace previous invitation: %v", err)
+	}
+	invitation := &models.Invitation{
+		WorkspaceID: workspace.ID,
+		Email:       email,
+		Role:        role,
+		TokenHash:   hash,
+		InvitedBy:   authContext.Sub,
+		AutoAccept:  len(users) == 0,
+		ExpiresAt:   time.Now().Add(invitationTTL).Format(time.RFC3339),
+	}
+	err = r.Repo.Invitation.CreateInvitation(ctx, invitation)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create invitation: %v", err)
+	}
+	r.recordAudit(ctx, "workspace.invite_member", "workspace", workspace.ID.Hex(), &workspace.ID,
+		nil, bson.M{"email": email, "role": role})
+	for _, user := range users {
+		r.notify(ctx, &models.Notification{
+			UserID:      user.ID,
+			Type:        models.NotificationWorkspaceInvitation,
+			WorkspaceID: &workspace.ID,
+			TargetID:    invitation.ID.Hex(),
+			Message:     fmt.Sprintf("%s invited you to %s", authContext.PreferredUsername, workspace.Name),
+		})
+	}
+	return token, invitation, nil
+}
+
+// getInvitationForRecipient loads a pending invitation that the current user
+// may respond to: either it was sent to their email or they hold its token.
+func (r *Resolver) getInvitationForRecipient(ctx context.Context, id string, token *string) (*models.Invitation, error) {
+	authContext := auth.ForContext(ctx)
+	if auth.ScopeForContext(ctx) != nil {
+		return nil, fmt.Errorf("invitations cannot be answered with an access token")
+	}
+	invitation, err := r.Repo.Invitation.GetInvitationByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch invitation: %v", err)
+	}
+	if invitation == nil {
+		return nil, fmt.Errorf("invitation not found")
+	}
+
+	recipient := authContext.Email != "" && repository.NormalizeEmail(authContext.Email) == invitation.Email
+	if token != nil && subtle.ConstantTimeCompare([]byte(auth.HashToken(*token)), []byte(invitation.TokenHash)) == 1 {
+		recipient = true
+	}
+	if !recipient {
+		return nil, fmt.Errorf("invitation not found")
+	}
+
+	if invitation.Status != models.InvitationPending {
+		return nil, fmt.Errorf("invitation is no longer pending")
+	}
+	expiresAt, err := time.Parse(time.RFC3339, invitation.ExpiresAt)
+	if err != nil || time.Now().After(expiresAt) {
+		return nil, fmt.Errorf("invitation has expired")
+	}
+	return invitation, nil
+}
+
+func convertInvitationToModel(inv *models.Invitation, workspaceName string) *model.Invitation {
+	return &model.Invitation{
+		ID:            inv.ID.Hex(),
+		WorkspaceID:   inv.WorkspaceID.Hex(),
+		WorkspaceName: workspaceName,
+		Email:         inv.Email,
+		Role:          model.WorkspaceRole(inv.Role),
+		InvitedBy:     inv.InvitedBy,
+		Status:        model.InvitationStatus(inv.Status),
+		ExpiresAt:     inv.ExpiresAt,
+		CreatedAt:     inv.CreatedAt,
+	}
+}
@@ -0,0 +1,393 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/chirag3003/collab-draw-backend/graph"
+	"github.com/chirag3003/collab-draw-backend/graph/model"
+	"github.com/chirag3003/collab-draw-backend/internal/auth"
+	"github.com/chirag3003/collab-draw-backend/internal/export"
+	"github.com/chirag3003/collab-draw-backend/internal/importer"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Thumbnail is the resolver for the thumbnail field.
+func (r *libraryItemResolver) Thumbnail(ctx context.Context, obj *model.LibraryItem) (string, error) {
+	scene, err := export.NewScene(obj.Elements, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to render thumbnail: %v", err)
+	}
+	return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(export.RenderSVG(scene)), nil
+}
+
+// CreateLibrary is the resolver for the createLibrary field.
+func (r *mutationResolver) CreateLibrary(ctx context.Context, input model.NewLibrary) (*model.Library, error) {
+	authContext := auth.ForContext(ctx)
+	workspace, err := r.getMemberWorkspace(ctx, input.WorkspaceID, true)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("library name is required")
+	}
+
+	library := &models.Library{
+		WorkspaceID: workspace.ID,
+		Name:        name,
+		CreatedBy:   authContext.Sub,
+	}
+	if input.Description != nil {
+		library.Description = *input.Description
+	}
+	err = r.Repo.Library.CreateLibrary(ctx, library)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create library: %v", err)
+	}
+	r.recordAudit(ctx, "workspace.create_library", "workspace", workspace.ID.Hex(), &workspace.ID,
+		nil, bson.M{"library": library.ID.Hex(), "name": library.Name})
+	r.publishLibraryEvent(model.LibraryEventTypeCreated, library)
+	return convertLibraryToModel(library), nil
+}
+
+// UpdateLibrary is the resolver for the updateLibrary field.
+func (r *mutationResolver) UpdateLibrary(ctx context.Context, id string, input model.UpdateLibrary) (*model.Library, error) {
+	library, err := r.getLibrary(ctx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	before := bson.M{"name": library.Name, "description": library.Description}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, fmt.Errorf("library name is required")
+		}
+		library.Name = name
+	}
+	if input.Description != nil {
+		library.Description = *input.Description
+	}
+	err = r.Repo.Library.UpdateLibrary(ctx, library.ID, library.Name, library.Description)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update library: %v", err)
+	}
+	r.recordAudit(ctx, "workspace.update_library", "workspace", library.WorkspaceID.Hex(), &library.WorkspaceID,
+		before, bson.M{"library": library.ID.Hex(), "name": library.Name, "description": library.Description})
+	return r.publishLibraryUpdate(ctx, library.ID)
+}
+
+// DeleteLibrary is the resolver for the deleteLibrary field.
+func (r *mutationResolver) DeleteLibrary(ctx context.Context, id string) (bool, error) {
+	library, err := r.getLibrary(ctx, id, true)
+	if err != nil {
+		return false, err
+	}
+	err = r.Repo.Library.DeleteLibrary(ctx, library.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete library: %v", err)
+	}
+	r.recordAudit(ctx, "workspace.delete_library", "workspace", library.WorkspaceID.Hex(), &library.WorkspaceID,
+		bson.M{"library": library.ID.Hex(), "name": library.Name, "items": len(library.Items)}, nil)
+	r.broadcastLibrary(library.WorkspaceID.Hex(), &model.LibraryEvent{
+		Type:      model.LibraryEventTypeDeleted,
+		LibraryID: library.ID.Hex(),
+	})
+	return true, nil
+}
+
+// AddLibraryItem is the resolver for the addLibraryItem field.
+func (r *mutationResolver) AddLibraryItem(ctx context.Context, libraryID string, input model.LibraryItemInput) (*model.LibraryItem, error) {
+	authContext := auth.ForContext(ctx)
+	library, err := r.getLibrary(ctx, libraryID, true)
+	if err != nil {
+		return nil, err
+	}
+	elements, err := importer.NormalizeElements(input.Elements)
+	if err != nil {
+		return nil, fmt.Errorf("invalid elements: %v", err)
+	}
+
+	item := models.LibraryItem{
+		ID:        bson.NewObjectID().Hex(),
+		Name:      strings.TrimSpace(input.Name),
+		Tags:      normalizeTags(input.Tags),
+		Elements:  elements,
+		CreatedBy: authContext.Sub,
+	}
+	items := []models.LibraryItem{item}
+	err = r.Repo.Library.AddItems(ctx, library.ID, items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add library item: %v", err)
+	}
+	if _, err := r.publishLibraryUpdate(ctx, library.ID); err != nil {
+		return nil, err
+	}
+	return convertLibraryItemToModel(&items[0]), nil
+}
+
+// UpdateLibraryItem is the resolver for the updateLibraryItem field.
+func (r *mutationResolver) UpdateLibraryItem(ctx context.Context, libraryID string, itemID string, input model.UpdateLibraryItem) (*model.LibraryItem, error) {
+	library, err := r.getLibrary(ctx, libraryID, true)
+	if err != nil {
+		return nil, err
+	}
+	index := slices.IndexFunc(library.Items, func(item models.LibraryItem) bool { return item.ID == itemID })
+	if index < 0 {
+		return nil, fmt.Errorf("library item not found")
+	}
+
+	item := library.Items[index]
+	if input.Name != nil {
+		item.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Tags != nil {
+		item.Tags = normalizeTags(input.Tags)
+	}
+	if input.Elements != nil {
+		item.Elements, err = importer.NormalizeElements(*input.Elements)
+		if err != nil {
+			return nil, fmt.Errorf("invalid elements: %v", err)
+		}
+	}
+	found, err := r.Repo.Library.UpdateItem(ctx, library.ID, &item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update library item: %v", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("library item not found")
+	}
+	if _, err := r.publishLibraryUpdate(ctx, library.ID); err != nil {
+		return nil, err
+	}
+	return convertLibraryItemToModel(&item), nil
+}
+
+// RemoveLibraryItem is the resolver for the removeLibraryItem field.
+func (r *mutationResolver) RemoveLibraryItem(ctx context.Context, libraryID string, itemID string) (bool, error) {
+	library, err := r.getLibrary(ctx, libraryID, true)
+	if err != nil {
+		return false, err
+	}
+	found, err := r.Repo.Library.RemoveItem(ctx, library.ID, itemID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove library item: %v", err)
+	}
+	if !found {
+		return false, fmt.Errorf("library item not found")
+	}
+	if _, err := r.publishLibraryUpdate(ctx, library.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ImportLibrary is the resolver for the importLibrary field.
+func (r *mutationResolver) ImportLibrary(ctx context.Context, workspaceID string, file graphql.Upload, libraryID *string) (*model.Library, error) {
+	workspace, err := r.getMemberWorkspace(ctx, workspaceID, true)
+	if err != nil {
+		return nil, err
+	}
+	var existing *models.Library
+	if libraryID != nil {
+		existing, err = r.getLibrary(ctx, *libraryID, true)
+		if err != nil {
+			return nil, err
+		}
+		if existing.WorkspaceID != workspace.ID {
+			return nil, fmt.Errorf("library not found")
+		}
+	}
+	if importer.KindOf(file.Filename) != importer.KindLibrary {
+		return nil, fmt.Errorf("unsupported file type, expected .excalidrawlib")
+	}
+	data, err := importer.ReadAll(file.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %v", err)
+	}
+
+	library, err := r.importLibrary(ctx, importer.File{Name: file.Filename, Data: data}, importer.BaseName(file.Filename), workspace, existing)
+	if err != nil {
+		return nil, err
+	}
+	return convertLibraryToModel(library), nil
+}
+
+// Libraries is the resolver for the libraries field.
+func (r *queryResolver) Libraries(ctx context.Context, workspaceID string, tag *string) ([]*model.Library, error) {
+	workspace, err := r.getMemberWorkspace(ctx, workspaceID, false)
+	if err != nil {
+		return nil, err
+	}
+	filterTag := ""
+	if tag != nil {
+		filterTag = strings.ToLower(strings.TrimSpace(*tag))
+	}
+	libraries, err := r.Repo.Library.GetLibrariesByWorkspace(ctx, workspace.ID, filterTag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch libraries: %v", err)
+	}
+	result := make([]*model.Library, 0, len(libraries))
+	for _, library := range libraries {
+		result = append(result, convertLibraryToModel(library))
+	}
+	return result, nil
+}
+
+// Library is the resolver for the library field.
+func (r *queryResolver) Library(ctx context.Context, id string) (*model.Library, error) {
+	library, err := r.getLibrary(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	return convertLibraryToModel(library), nil
+}
+
+// ExportLibrary is the resolver for the exportLibrary field.
+func (r *queryResolver) ExportLibrary(ctx context.Context, id string, itemIDs []string) (string, error) {
+	library, err := r.getLibrary(ctx, id, false)
+	if err != nil {
+		return "", err
+	}
+	items := make([]export.LibraryItem, 0, len(library.Items))
+	for _, item := range library.Items {
+		if len(itemIDs) > 0 && !slices.Contains(itemIDs, item.ID) {
+			continue
+		}
+		var created int64
+		if t, err := time.Parse(time.RFC3339, item.CreatedAt); err == nil {
+			created = t.UnixMilli()
+		}
+		items = append(items, export.LibraryItem{
+			ID:       item.ID,
+			Status:   "unpublished",
+			Created:  created,
+			Name:     item.Name,
+			Tags:     item.Tags,
+			Elements: json.RawMessage(item.Elements),
+		})
+	}
+	data, err := export.RenderLibrary(items, "collab-draw")
+	if err != nil {
+		return "", fmt.Errorf("failed to export library: %v", err)
+	}
+	return string(data), nil
+}
+
+// WorkspaceLibraries is the resolver for the workspaceLibraries field.
+func (r *subscriptionResolver) WorkspaceLibraries(ctx context.Context, workspaceID string) (<-chan *model.LibraryEvent, error) {
+	workspace, err := r.getMemberWorkspace(ctx, workspaceID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan *model.LibraryEvent, 32)
+	socketID := r.subscribeToLibraries(workspace.ID.Hex(), auth.ForContext(ctx).Sub, ch)
+
+	go func(socketID string) {
+		<-ctx.Done()
+		r.unsubscribeFromLibraries(workspace.ID.Hex(), socketID)
+	}(socketID)
+
+	return ch, nil
+}
+
+// LibraryItem returns graph.LibraryItemResolver implementation.
+func (r *Resolver) LibraryItem() graph.LibraryItemResolver { return &libraryItemResolver{r} }
+
+type libraryItemResolver struct{ *Resolver }
+
+// getLibrary fetches a library the current user may read, or edit when edit
+// is set.
+func (r *Resolver) getLibrary(ctx context.Context, id string, edit bool) (*models.Library, error) {
+	library, err := r.Repo.Library.GetLibrary(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch library: %v", err)
+	}
+	if library == nil {
+		return nil, fmt.Errorf("library not found")
+	}
+	if _, err := r.getMemberWorkspace(ctx, library.WorkspaceID.Hex(), edit); err != nil {
+		return nil, err
+	}
+	return library, nil
+}
+
+// publishLibraryEvent tells the workspace's live subscribers about a library.
+func (r *Resolver) publishLibraryEvent(eventType model.LibraryEventType, library *models.Library) {
+	r.broadcastLibrary(library.WorkspaceID.Hex(), &model.LibraryEvent{
+		Type:      eventType,
+		LibraryID: library.ID.Hex(),
+		Library:   convertLibraryToModel(library),
+	})
+}
+
+// publishLibraryUpdate reloads a changed library, publishes it and returns it.
+func (r *Resolver) publishLibraryUpdate(ctx context.Context, id bson.ObjectID) (*model.Library, error) {
+	library, err := r.Repo.Library.GetLibrary(ctx, id.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch library: %v", err)
+	}
+	if library == nil {
+		return nil, fmt.Errorf("library not found")
+	}
+	r.publishLibraryEvent(model.LibraryEventTypeUpdated, library)
+	return convertLibraryToModel(library), nil
+}
+
+// normalizeTags lowercases and trims tags, dropping empty and repeated ones.
+func normalizeTags(tags []string) []string {
+	result := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+func convertLibraryToModel(library *models.Library) *model.Library {
+	result := &model.Library{
+		ID:          library.ID.Hex(),
+		WorkspaceID: library.WorkspaceID.Hex(),
+		Name:        library.Name,
+		Description: library.Description,
+		Items:       make([]*model.LibraryItem, 0, len(library.Items)),
+		ItemCount:   int32(len(library.Items)),
+		CreatedBy:   library.CreatedBy,
+		CreatedAt:   library.CreatedAt,
+		UpdatedAt:   library.UpdatedAt,
+	}
+	for i := range library.Items {
+		result.Items = append(result.Items, convertLibraryItemToModel(&library.Items[i]))
+	}
+	return result
+}
+
+func convertLibraryItemToModel(item *models.LibraryItem) *model.LibraryItem {
+	tags := item.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return &model.LibraryItem{
+		ID:        item.ID,
+		Name:      item.Name,
+		Tags:      tags,
+		Elements:  item.Elements,
+		CreatedBy: item.CreatedBy,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+	}
+}
@@ -0,0 +1,205 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chirag3003/collab-draw-backend/graph/model"
+	"github.com/chirag3003/collab-draw-backend/internal/auth"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"github.com/chirag3003/collab-draw-backend/internal/oidc"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// MarkNotificationsRead is the resolver for the markNotificationsRead field.
+func (r *mutationResolver) MarkNotificationsRead(ctx context.Context, ids []string) (int32, error) {
+	authContext, err := notificationUser(ctx)
+	if err != nil {
+		return 0, err
+	}
+	objectIDs := make([]bson.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objectID, err := bson.ObjectIDFromHex(id)
+		if err != nil {
+			return 0, fmt.Errorf("invalid notification ID %s", id)
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+	marked, err := r.Repo.Notification.MarkRead(ctx, authContext.Sub, objectIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications as read: %v", err)
+	}
+	return int32(marked), nil
+}
+
+// UpdateNotificationPreferences is the resolver for the updateNotificationPreferences field.
+func (r *mutationResolver) UpdateNotificationPreferences(ctx context.Context, input model.NotificationPreferencesInput) (*model.NotificationPreferences, error) {
+	authContext, err := notificationUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prefs := &models.NotificationPreferences{
+		UserID:          authContext.Sub,
+		MutedTypes:      make([]string, 0, len(input.MutedTypes)),
+		MutedWorkspaces: make([]bson.ObjectID, 0, len(input.MutedWorkspaces)),
+	}
+	for _, t := range input.MutedTypes {
+		prefs.MutedTypes = append(prefs.MutedTypes, string(t))
+	}
+	for _, id := range input.MutedWorkspaces {
+		workspaceID, err := bson.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid workspace ID %s", id)
+		}
+		prefs.MutedWorkspaces = append(prefs.MutedWorkspaces, workspaceID)
+	}
+	err = r.Repo.Notification.SetPreferences(ctx, prefs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update notification preferences: %v", err)
+	}
+	return convertNotificationPreferencesToModel(prefs), nil
+}
+
+// Notifications is the resolver for the notifications field.
+func (r *queryResolver) Notifications(ctx context.Context, unreadOnly *bool, cursor *string) (*model.NotificationPage, error) {
+	authContext, err := notificationUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	after := ""
+	if cursor != nil {
+		after = *cursor
+	}
+	notifications, next, err := r.Repo.Notification.GetNotifications(ctx, authContext.Sub, unreadOnly != nil && *unreadOnly, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch notifications: %v", err)
+	}
+	unread, err := r.Repo.Notification.CountUnread(ctx, authContext.Sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread notifications: %v", err)
+	}
+
+	page := &model.NotificationPage{
+		Notifications: make([]*model.Notification, 0, len(notifications)),
+		UnreadCount:   int32(unread),
+	}
+	for _, n := range notifications {
+		page.Notifications = append(page.Notifications, convertNotificationToModel(n))
+	}
+	if next != "" {
+		page.NextCursor = &next
+	}
+	return page, nil
+}
+
+// NotificationPreferences is the resolver for the notificationPreferences field.
+func (r *queryResolver) NotificationPreferences(ctx context.Context) (*model.NotificationPreferences, error) {
+	authContext, err := notificationUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prefs, err := r.Repo.Notification.GetPreferences(ctx, authContext.Sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch notification preferences: %v", err)
+	}
+	return convertNotificationPreferencesToModel(prefs), nil
+}
+
+// Notifications is the resolver for the notifications field.
+func (r *subscriptionResolver) Notifications(ctx context.Context) (<-chan *model.Notification, error) {
+	authContext, err := notificationUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan *model.Notification, 32)
+	socketID := r.subscribeToNotifications(authContext.Sub, ch)
+
+	go func(socketID string) {
+		<-ctx.Done()
+		r.unsubscribeFromNotifications(authContext.Sub, socketID)
+	}(socketID)
+
+	return ch, nil
+}
+
+// notificationUser returns the signed in user. Guests and scoped access tokens
+// have no inbox.
+func notificationUser(ctx context.Context) (*oidc.Claims, error) {
+	authContext := auth.ForContext(ctx)
+	if authContext == nil || auth.IsGuest(ctx) {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	if scopeRestricted(ctx) {
+		return nil, fmt.Errorf("notifications are not available to scoped access tokens")
+	}
+	return authContext, nil
+}
+
+// notify stores a notification in the recipient's inbox and pushes it to their
+// live subscriptions, unless they muted it. Users are never notified about
+// their own actions.
+func (r *Resolver) notify(ctx context.Context, notification *models.Notification) {
+	authContext := auth.ForContext(ctx)
+	if notification.UserID == "" || notification.UserID == authContext.Sub ||
+		strings.HasPrefix(notification.UserID, auth.GuestSubPrefix) {
+		return
+	}
+	notification.ActorID = authContext.Sub
+	notification.ActorName = authContext.PreferredUsername
+
+	prefs, err := r.Repo.Notification.GetPreferences(ctx, notification.UserID)
+	if err != nil {
+		fmt.Printf("Warning: failed to fetch notification preferences of %s: %v\n", notification.UserID, err)
+	} else if prefs.Mutes(notification) {
+		return
+	}
+	if err := r.Repo.Notification.CreateNotification(ctx, notification); err != nil {
+		fmt.Printf("Warning: failed to store notification for %s: %v\n", notification.UserID, err)
+		return
+	}
+	r.pushNotification(notification.UserID, convertNotificationToModel(notification))
+}
+
+func convertNotificationToModel(n *models.Notification) *model.Notification {
+	result := &model.Notification{
+		ID:        n.ID.Hex(),
+		Type:      model.NotificationType(n.Type),
+		ActorID:   n.ActorID,
+		ActorName: n.ActorName,
+		Message:   n.Message,
+		Read:      n.ReadAt != "",
+		CreatedAt: n.CreatedAt,
+	}
+	if n.WorkspaceID != nil {
+		id := n.WorkspaceID.Hex()
+		result.WorkspaceID = &id
+	}
+	if n.ProjectID != nil {
+		id := n.ProjectID.Hex()
+		result.ProjectID = &id
+	}
+	if n.TargetID != "" {
+		result.TargetID = &n.TargetID
+	}
+	return result
+}
+
+func convertNotificationPreferencesToModel(prefs *models.NotificationPreferences) *model.NotificationPreferences {
+	result := &model.NotificationPreferences{
+		MutedTypes:      make([]model.NotificationType, 0, len(prefs.MutedTypes)),
+		MutedWorkspaces: make([]string, 0, len(prefs.MutedWorkspaces)),
+	}
+	for _, t := range prefs.MutedTypes {
+		result.MutedTypes = append(result.MutedTypes, model.NotificationType(t))
+	}
+	for _, id := range prefs.MutedWorkspaces {
+		result.MutedWorkspaces = append(result.MutedWorkspaces, id.Hex())
+	}
+	return result
+}
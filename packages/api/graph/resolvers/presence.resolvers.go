@@ -17,6 +17,9 @@ import (
 // UpdateCursor is the resolver for the updateCursor field.
 func (r *mutationResolver) UpdateCursor(ctx context.Context, projectID string, cursor model.CursorInput) (bool, error) {
 	authContext := auth.ForContext(ctx)
+	if err := r.checkProjectScope(ctx, projectID); err != nil {
+		return false, err
+	}
 
 	// Generate a deterministic color from userID
 	color := userIDToColor(authContext.Sub)
@@ -38,16 +41,14 @@ func (r *mutationResolver) UpdateCursor(ctx context.Context, projectID string, c
 
 // Cursors is the resolver for the cursors field.
 func (r *subscriptionResolver) Cursors(ctx context.Context, projectID string) (<-chan *model.CursorUpdate, error) {
-	authContext := auth.ForContext(ctx)
-
 	// Verify user has access
-	project, err := r.Repo.Project.GetProjectByID(ctx, projectID, authContext.Sub)
+	project, err := r.getAccessibleProject(ctx, projectID)
 	if err != nil || project == nil {
 		return nil, fmt.Errorf("project not found or access denied")
 	}
 
 	ch := make(chan *model.CursorUpdate, 64)
-	socketID := r.subscribeToCursors(projectID, ch)
+	socketID := r.subscribeToCursors(projectID, holderForContext(ctx), ch)
 
 	go func(socketID string) {
 		<-ctx.Done()
@@ -59,20 +60,14 @@ func (r *subscriptionResolver) Cursors(ctx context.Context, projectID string) (<
 
 // Presence is the resolver for the presence field.
 func (r *subscriptionResolver) Presence(ctx context.Context, projectID string) (<-chan []*model.UserPresence, error) {
-	authContext := auth.ForContext(ctx)
-
 	// Verify user has access
-	project, err := r.Repo.Project.GetProjectByID(ctx, projectID, authContext.Sub)
+	project, err := r.getAccessibleProject(ctx, projectID)
 	if err != nil || project == nil {
 		return nil, fmt.Errorf("project not found or access denied")
 	}
 
 	ch := make(chan []*model.UserPresence, 16)
-	socketID := r.subscribeToPresence(projectID, ch)
-
-	// Send initial presence list
-	presenceList := r.getPresenceList(projectID)
-	ch <- presenceList
+	socketID := r.subscribeToPresence(projectID, holderForContext(ctx), ch)
 
 	go func(socketID string) {
 		<-ctx.Done()
@@ -7,13 +7,17 @@ package resolvers
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/chirag3003/collab-draw-backend/graph"
 	"github.com/chirag3003/collab-draw-backend/graph/model"
 	"github.com/chirag3003/collab-draw-backend/internal/auth"
+	"github.com/chirag3003/collab-draw-backend/internal/importer"
 	"github.com/chirag3003/collab-draw-backend/internal/models"
 	"github.com/chirag3003/collab-draw-backend/internal/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 // CreateProject is the resolver for the createProject field.
@@ -23,43 +27,73 @@ func (r *mutationResolver) CreateProject(ctx context.Context, input model.NewPro
 		Name:     input.Name,
 		Elements: "",
 		Owner:    authContext.Sub,
+		Members:  []string{},
 		Personal: input.Personal,
 	}
 	if input.Description != nil {
 		project.Description = *input.Description
 	}
 
-	if input.Workspace != nil {
-		workspace, err := r.Repo.Workspace.GetWorkspaceByID(ctx, *input.Workspace, authContext.Sub)
+	workspace, err := r.getTargetWorkspace(ctx, input.Workspace)
+	if err != nil {
+		return "", err
+	}
+	if workspace != nil {
+		// Workspace members get access through the workspace itself
+		project.Workspace = &workspace.ID
+	}
+
+	var template *models.Project
+	var elements []importer.Element
+	if input.TemplateID != nil {
+		template, err = r.getAccessibleProject(ctx, *input.TemplateID)
 		if err != nil {
-			return "", fmt.Errorf("failed to fetch workspace: %v", err)
+			return "", fmt.Errorf("failed to fetch template: %v", err)
 		}
-		if workspace == nil {
-			return "", fmt.Errorf("workspace not found")
+		if template == nil || !template.IsTemplate {
+			return "", fmt.Errorf("template not found")
 		}
-		if workspace.Owner != authContext.Sub {
-			return "", fmt.Errorf("only workspace owner can create project in it")
+		elements, err = importer.CopyElements(template.Elements)
+		if err != nil {
+			return "", fmt.Errorf("failed to copy template: %v", err)
 		}
-		project.Workspace = &workspace.ID
-		project.Members = workspace.Members
 	}
 
-	err := r.Repo.Project.NewProject(ctx, project)
+	err = r.seedProject(ctx, project, elements, "template")
 	if err != nil {
-		return "", fmt.Errorf("failed to create project: %v", err)
+		return "", err
 	}
+	after := bson.M{"name": project.Name}
+	if template != nil {
+		r.copyProjectFiles(ctx, template.ID, project.ID)
+		after["template"] = template.ID.Hex()
+	}
+	r.recordAudit(ctx, "project.create", "project", project.ID.Hex(), project.Workspace, nil, after)
+	r.publishProjectEvent(ctx, model.EventTypeProjectCreated, project)
 
 	return "project created successfully", nil
 }
 
 // UpdateProject is the resolver for the updateProject field.
 func (r *mutationResolver) UpdateProject(ctx context.Context, id string, elements string, socketID string) (bool, error) {
-	authContext := auth.ForContext(ctx)
 	fmt.Printf("Update Request from %s\n", socketID)
-	err := r.Repo.Project.UpdateProject(ctx, id, elements, authContext.Sub)
+	project, err := r.getEditableProject(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if err := r.Quotas.CheckElements(ctx, project.Workspace, repository.CountElements(elements)-project.ElementCount); err != nil {
+		return false, quotaError(ctx, err)
+	}
+	err = r.Repo.Project.UpdateProject(ctx, id, elements)
 	if err != nil {
 		return false, fmt.Errorf("failed to update project: %v", err)
 	}
+	// Whole-document overwrites bypass the op log, so they are audited. Individual
+	// ops from applyOps are already recorded in the operations collection.
+	r.recordAudit(ctx, "project.overwrite_elements", "project", id, project.Workspace, nil, nil)
+	project.Elements = elements
+	r.Search.IndexProject(ctx, project)
+	r.Thumbnails.Schedule(project.ID)
 
 	r.broadcastProjectUpdate(id, &model.ProjectSubscription{
 		Elements: elements,
@@ -72,10 +106,23 @@ func (r *mutationResolver) UpdateProject(ctx context.Context, id string, element
 // DeleteProject is the resolver for the deleteProject field.
 func (r *mutationResolver) DeleteProject(ctx context.Context, id string) (bool, error) {
 	authContext := auth.ForContext(ctx)
+	project, err := r.getAccessibleProject(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if project == nil {
+		return false, fmt.Errorf("project not found or access denied")
+	}
 	success, err := r.Repo.Project.DeleteProject(ctx, id, authContext.Sub)
 	if err != nil {
 		return false, fmt.Errorf("failed to delete project: %v", err)
 	}
+	if success {
+		r.Cleanup.ProjectsRemoved(project.ID)
+		r.recordAudit(ctx, "project.delete", "project", id, project.Workspace,
+			bson.M{"name": project.Name}, nil)
+		r.publishProjectEvent(ctx, model.EventTypeProjectDeleted, project)
+	}
 	return success, nil
 }
 
@@ -85,20 +132,40 @@ func (r *mutationResolver) UpdateProjectMetadata(ctx context.Context, id string,
 	if strings.TrimSpace(name) == "" {
 		return false, fmt.Errorf("project name cannot be empty")
 	}
-	err := r.Repo.Project.UpdateProjectMetadata(ctx, id, name, description, authContext.Sub)
+	project, err := r.getAccessibleProject(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if project == nil {
+		return false, fmt.Errorf("project not found or access denied")
+	}
+	err = r.Repo.Project.UpdateProjectMetadata(ctx, id, name, description, authContext.Sub)
 	if err != nil {
 		return false, fmt.Errorf("failed to update project metadata: %v", err)
 	}
+	r.recordAudit(ctx, "project.update_metadata", "project", id, project.Workspace,
+		bson.M{"name": project.Name, "description": project.Description},
+		bson.M{"name": name, "description": description})
+	project.Name, project.Description = name, description
+	r.Search.IndexProjectInfo(ctx, project)
+	r.publishProjectEvent(ctx, model.EventTypeProjectRenamed, project)
 	return true, nil
 }
 
 // ApplyOps is the resolver for the applyOps field.
 func (r *mutationResolver) ApplyOps(ctx context.Context, projectID string, socketID string, ops []*model.OperationInput) (*model.ApplyOpsResult, error) {
-	authContext := auth.ForContext(ctx)
+	project, err := r.getEditableProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
 
 	// Convert GraphQL input to repository input
+	added := 0
 	repoOps := make([]repository.OpInput, len(ops))
 	for i, op := range ops {
+		if op.Type == model.OpTypeAdd {
+			added++
+		}
 		repoOps[i] = repository.OpInput{
 			ClientSeq:  op.ClientSeq,
 			Type:       string(op.Type),
@@ -108,8 +175,11 @@ func (r *mutationResolver) ApplyOps(ctx context.Context, projectID string, socke
 			Data:       op.Data,
 		}
 	}
+	if err := r.Quotas.CheckElements(ctx, project.Workspace, added); err != nil {
+		return nil, quotaError(ctx, err)
+	}
 
-	result, err := r.Repo.Operation.ApplyOps(ctx, projectID, socketID, repoOps, authContext.Sub)
+	result, err := r.Repo.Operation.ApplyOps(ctx, projectID, socketID, auth.ForContext(ctx).Sub, repoOps)
 	if err != nil {
 		return nil, fmt.Errorf("failed to apply ops: %v", err)
 	}
@@ -133,6 +203,17 @@ func (r *mutationResolver) ApplyOps(ctx context.Context, projectID string, socke
 			})
 		}
 		r.broadcastOps(projectID, gqlOps, socketID)
+		r.syncCommentAnchors(ctx, project.ID, result.Accepted)
+		r.Search.IndexOps(ctx, project.ID, result.Accepted)
+		r.Thumbnails.Schedule(project.ID)
+		if project.Workspace != nil {
+			r.Webhooks.Enqueue(ctx, *project.Workspace, models.WebhookEventOpsApplied, map[string]any{
+				"projectId": projectID,
+				"actorId":   auth.ForContext(ctx).Sub,
+				"serverSeq": result.ServerSeq,
+				"ops":       gqlOps,
+			})
+		}
 	}
 
 	// Build response
@@ -151,86 +232,331 @@ func (r *mutationResolver) ApplyOps(ctx context.Context, projectID string, socke
 	return gqlResult, nil
 }
 
+// TransferProjectOwnership is the resolver for the transferProjectOwnership field.
+func (r *mutationResolver) TransferProjectOwnership(ctx context.Context, id string, newOwnerID string) (bool, error) {
+	authContext := auth.ForContext(ctx)
+	project, err := r.getAccessibleProject(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if project == nil || project.Owner != authContext.Sub {
+		return false, fmt.Errorf("project not found")
+	}
+	if newOwnerID == authContext.Sub {
+		return false, fmt.Errorf("you already own this project")
+	}
+
+	// The recipient must already have access, either directly or through the workspace
+	isMember := slices.Contains(project.Members, newOwnerID)
+	if !isMember && project.Workspace != nil {
+		workspace, err := r.Repo.Workspace.GetWorkspace(ctx, project.Workspace.Hex())
+		if err != nil {
+			return false, fmt.Errorf("failed to fetch workspace: %v", err)
+		}
+		isMember = workspace != nil && workspace.RoleOf(newOwnerID) != ""
+	}
+	if !isMember {
+		return false, fmt.Errorf("new owner must be a member of the project")
+	}
+
+	err = r.Repo.Project.TransferOwnership(ctx, id, authContext.Sub, newOwnerID)
+	if err != nil {
+		return false, fmt.Errorf("failed to transfer project ownership: %v", err)
+	}
+	r.recordAudit(ctx, "project.transfer_ownership", "project", id, project.Workspace,
+		bson.M{"owner": authContext.Sub}, bson.M{"owner": newOwnerID})
+	return true, nil
+}
+
+// AddProjectMember is the resolver for the addProjectMember field.
+func (r *mutationResolver) AddProjectMember(ctx context.Context, projectID string, email string) (bool, error) {
+	authContext := auth.ForContext(ctx)
+	project, err := r.getAccessibleProject(ctx, projectID)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if project == nil || project.Owner != authContext.Sub {
+		return false, fmt.Errorf("project not found")
+	}
+	users, err := r.Repo.User.GetUserByEmail(ctx, email)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch user by email: %v", err)
+	}
+	if len(users) == 0 {
+		return false, fmt.Errorf("user with email %s not found", email)
+	}
+	if users[0].ID == project.Owner {
+		return false, fmt.Errorf("user already owns this project")
+	}
+	err = r.Repo.Project.AddMember(ctx, projectID, users[0].ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to add project member: %v", err)
+	}
+	r.recordAudit(ctx, "project.add_member", "project", projectID, project.Workspace,
+		nil, bson.M{"member": users[0].ID})
+	r.publishMemberEvent(ctx, model.EventTypeMemberAdded, project.Workspace, project, users[0].ID)
+	r.notify(ctx, &models.Notification{
+		UserID:      users[0].ID,
+		Type:        models.NotificationProjectShared,
+		WorkspaceID: project.Workspace,
+		ProjectID:   &project.ID,
+		Message:     fmt.Sprintf("%s shared %s with you", authContext.PreferredUsername, project.Name),
+	})
+	return true, nil
+}
+
+// RemoveProjectMember is the resolver for the removeProjectMember field.
+func (r *mutationResolver) RemoveProjectMember(ctx context.Context, projectID string, userID string) (bool, error) {
+	authContext := auth.ForContext(ctx)
+	project, err := r.getAccessibleProject(ctx, projectID)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch project: %v", err)
+	}
+	// Members may remove themselves; everyone else needs to be the owner
+	if project == nil || (project.Owner != authContext.Sub && userID != authContext.Sub) {
+		return false, fmt.Errorf("project not found")
+	}
+	err = r.Repo.Project.RemoveMember(ctx, projectID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove project member: %v", err)
+	}
+	r.recordAudit(ctx, "project.remove_member", "project", projectID, project.Workspace,
+		bson.M{"member": userID}, nil)
+	r.publishMemberEvent(ctx, model.EventTypeMemberRemoved, project.Workspace, project, userID)
+	r.notifyAccessLoss(ctx, project, []string{userID})
+	return true, nil
+}
+
+// SetProjectRestricted is the resolver for the setProjectRestricted field.
+func (r *mutationResolver) SetProjectRestricted(ctx context.Context, id string, restricted bool) (bool, error) {
+	authContext := auth.ForContext(ctx)
+	project, err := r.getAccessibleProject(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if project == nil || project.Owner != authContext.Sub {
+		return false, fmt.Errorf("project not found")
+	}
+	if project.Workspace == nil {
+		return false, fmt.Errorf("only workspace projects can be restricted")
+	}
+	err = r.Repo.Project.SetRestricted(ctx, id, restricted)
+	if err != nil {
+		return false, fmt.Errorf("failed to update project: %v", err)
+	}
+	r.recordAudit(ctx, "project.set_restricted", "project", id, project.Workspace,
+		bson.M{"restricted": project.Restricted}, bson.M{"restricted": restricted})
+	if restricted && !project.Restricted {
+		workspace, err := r.Repo.Workspace.GetWorkspace(ctx, project.Workspace.Hex())
+		if err == nil && workspace != nil {
+			r.notifyAccessLoss(ctx, project, append([]string{workspace.Owner}, workspace.Members...))
+		}
+	}
+	return true, nil
+}
+
+// RestoreProject is the resolver for the restoreProject field.
+func (r *mutationResolver) RestoreProject(ctx context.Context, id string) (bool, error) {
+	authContext := auth.ForContext(ctx)
+	project, err := r.Repo.Project.GetTrashedProject(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if project == nil || project.Owner != authContext.Sub || !projectInScope(ctx, project) {
+		return false, fmt.Errorf("project not found in trash")
+	}
+	if project.TrashedWithWorkspace {
+		return false, fmt.Errorf("project was deleted with its workspace; restore the workspace instead")
+	}
+	if project.Workspace != nil {
+		workspace, err := r.Repo.Workspace.GetWorkspace(ctx, project.Workspace.Hex())
+		if err != nil {
+			return false, fmt.Errorf("failed to fetch workspace: %v", err)
+		}
+		if workspace == nil {
+			return false, fmt.Errorf("restore the project's workspace first")
+		}
+	}
+	if err := r.Quotas.CheckProjects(ctx, project.Workspace, 1); err != nil {
+		return false, quotaError(ctx, err)
+	}
+	if err := r.Quotas.CheckElements(ctx, project.Workspace, project.ElementCount); err != nil {
+		return false, quotaError(ctx, err)
+	}
+	restored, err := r.Repo.Project.RestoreProject(ctx, id, authContext.Sub)
+	if err != nil {
+		return false, fmt.Errorf("failed to restore project: %v", err)
+	}
+	if restored {
+		r.recordAudit(ctx, "project.restore", "project", id, project.Workspace,
+			bson.M{"deletedAt": project.DeletedAt}, nil)
+		r.publishProjectEvent(ctx, model.EventTypeProjectRestored, project)
+	}
+	return restored, nil
+}
+
+// DuplicateProject is the resolver for the duplicateProject field.
+func (r *mutationResolver) DuplicateProject(ctx context.Context, id string, targetWorkspace *string, name *string, seq *int32) (*model.Project, error) {
+	authContext := auth.ForContext(ctx)
+	if auth.IsGuest(ctx) {
+		return nil, fmt.Errorf("guests cannot duplicate projects")
+	}
+	source, err := r.getAccessibleProject(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if source == nil {
+		return nil, fmt.Errorf("project not found or access denied")
+	}
+	workspace, err := r.getTargetWorkspace(ctx, targetWorkspace)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceElements := source.Elements
+	if seq != nil {
+		sourceElements, _, _, err = r.Repo.Operation.ReconstructStateAt(ctx, source.ID.Hex(), *seq, authContext.Sub)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reconstruct snapshot: %v", err)
+		}
+	}
+	elements, err := importer.CopyElements(sourceElements)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy elements: %v", err)
+	}
+
+	project := &models.Project{
+		Name:        source.Name + " (copy)",
+		Description: source.Description,
+		Elements:    "",
+		Owner:       authContext.Sub,
+		Members:     []string{},
+		Personal:    workspace == nil,
+	}
+	if name != nil && strings.TrimSpace(*name) != "" {
+		project.Name = strings.TrimSpace(*name)
+	}
+	if workspace != nil {
+		project.Workspace = &workspace.ID
+	}
+	err = r.seedProject(ctx, project, elements, "duplicate")
+	if err != nil {
+		return nil, err
+	}
+	r.copyProjectFiles(ctx, source.ID, project.ID)
+
+	after := bson.M{"name": project.Name, "duplicated_from": source.ID.Hex()}
+	if seq != nil {
+		after["seq"] = *seq
+	}
+	r.recordAudit(ctx, "project.create", "project", project.ID.Hex(), project.Workspace, nil, after)
+	r.publishProjectEvent(ctx, model.EventTypeProjectCreated, project)
+	return convertProjectToModel(project), nil
+}
+
+// SetProjectTemplate is the resolver for the setProjectTemplate field.
+func (r *mutationResolver) SetProjectTemplate(ctx context.Context, id string, isTemplate bool) (bool, error) {
+	project, err := r.getAccessibleProject(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if project == nil {
+		return false, fmt.Errorf("project not found")
+	}
+	if project.Workspace == nil {
+		return false, fmt.Errorf("only workspace projects can be templates")
+	}
+	if _, err := r.getOwnedWorkspace(ctx, project.Workspace.Hex()); err != nil {
+		return false, fmt.Errorf("only workspace owner can manage templates")
+	}
+	err = r.Repo.Project.SetTemplate(ctx, id, isTemplate)
+	if err != nil {
+		return false, fmt.Errorf("failed to update project: %v", err)
+	}
+	r.recordAudit(ctx, "project.set_template", "project", id, project.Workspace,
+		bson.M{"isTemplate": project.IsTemplate}, bson.M{"isTemplate": isTemplate})
+	return true, nil
+}
+
+// Elements is the resolver for the elements field.
+func (r *projectResolver) Elements(ctx context.Context, obj *model.Project) (string, error) {
+	// Single projects come with their elements; lists leave them out
+	if obj.Elements != "" {
+		return obj.Elements, nil
+	}
+	elements, err := r.Repo.Project.GetElements(ctx, obj.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch elements: %v", err)
+	}
+	return elements, nil
+}
+
+// IsFavorite is the resolver for the isFavorite field.
+func (r *projectResolver) IsFavorite(ctx context.Context, obj *model.Project) (bool, error) {
+	if auth.IsGuest(ctx) {
+		return false, nil
+	}
+	id, err := bson.ObjectIDFromHex(obj.ID)
+	if err != nil {
+		return false, nil
+	}
+	favorite, err := r.Repo.ProjectState.IsFavorite(ctx, auth.ForContext(ctx).Sub, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch favorite: %v", err)
+	}
+	return favorite, nil
+}
+
 // Projects is the resolver for the projects field.
 func (r *queryResolver) Projects(ctx context.Context) ([]*model.Project, error) {
+	// Lists every project in the database, regardless of membership
+	if !auth.IsAdmin(ctx) {
+		return nil, fmt.Errorf("only administrators can list all projects")
+	}
 	projects, err := r.Repo.Project.GetAll(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch projects: %v", err)
 	}
 	var result []*model.Project
 	for _, p := range projects {
-		var workspace *string = nil
-		if p.Workspace != nil {
-			hex := p.Workspace.Hex()
-			workspace = &hex
+		if !projectInScope(ctx, p) {
+			continue
 		}
-		result = append(result, &model.Project{
-			ID:          p.ID.Hex(),
-			Name:        p.Name,
-			Description: &p.Description,
-			Owner:       p.Owner,
-			Workspace:   workspace,
-			Personal:    p.Personal,
-			Elements:    p.Elements,
-			CreatedAt:   p.CreatedAt,
-		})
+		result = append(result, convertProjectToModel(p))
 	}
 	return result, nil
 }
 
 // Project is the resolver for the project field.
 func (r *queryResolver) Project(ctx context.Context, id string) (*model.Project, error) {
-	authContext := auth.ForContext(ctx)
-	project, err := r.Repo.Project.GetProjectByID(ctx, id, authContext.Sub)
+	project, err := r.getAccessibleProject(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch project: %v", err)
 	}
 	if project == nil {
 		return nil, nil // or return an error if preferred
 	}
-	var workspace *string = nil
-	if project.Workspace != nil {
-		hex := project.Workspace.Hex()
-		workspace = &hex
+	if !auth.IsGuest(ctx) {
+		// Opening a project is what puts it in the user's recent projects
+		if err := r.Repo.ProjectState.MarkOpened(ctx, auth.ForContext(ctx).Sub, project.ID); err != nil {
+			fmt.Printf("Warning: failed to record opening project %s: %v\n", id, err)
+		}
 	}
-	return &model.Project{
-		ID:          project.ID.Hex(),
-		Name:        project.Name,
-		Description: &project.Description,
-		Owner:       project.Owner,
-		Workspace:   workspace,
-		Personal:    project.Personal,
-		Elements:    project.Elements,
-		CreatedAt:   project.CreatedAt,
-	}, nil
+	return convertProjectToModel(project), nil
 }
 
 // ProjectsByUser is the resolver for the projectsByUser field.
-func (r *queryResolver) ProjectsByUser(ctx context.Context, userID string) ([]*model.Project, error) {
+func (r *queryResolver) ProjectsByUser(ctx context.Context, userID string, filter *model.ProjectFilter, sort *model.ProjectSort, first *int32, after *string) ([]*model.Project, error) {
 	authContext := auth.ForContext(ctx)
-	projects, err := r.Repo.Project.GetProjectsByUserID(ctx, authContext.Sub)
+	query, err := r.buildProjectQuery(ctx, filter, sort, first, after)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch projects: %v", err)
+		return nil, err
 	}
-	var result []*model.Project
-	for _, p := range projects {
-		var workspace *string = nil
-		if p.Workspace != nil {
-			hex := p.Workspace.Hex()
-			workspace = &hex
-		}
-		result = append(result, &model.Project{
-			ID:          p.ID.Hex(),
-			Name:        p.Name,
-			Description: &p.Description,
-			Owner:       p.Owner,
-			Workspace:   workspace,
-			Personal:    p.Personal,
-			Elements:    p.Elements,
-			CreatedAt:   p.CreatedAt,
-		})
+	page, err := r.Repo.Project.ListUserProjects(ctx, authContext.Sub, *query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch projects: %v", err)
 	}
-	return result, nil
+	return convertProjectPageToModel(ctx, page), nil
 }
 
 // ProjectsPersonalByUser is the resolver for the projectsPersonalByUser field.
@@ -242,43 +568,33 @@ func (r *queryResolver) ProjectsPersonalByUser(ctx context.Context, userID strin
 	}
 	var result []*model.Project
 	for _, p := range projects {
-		result = append(result, &model.Project{
-			ID:          p.ID.Hex(),
-			Name:        p.Name,
-			Description: &p.Description,
-			Owner:       p.Owner,
-			Personal:    p.Personal,
-			Elements:    p.Elements,
-			CreatedAt:   p.CreatedAt,
-		})
+		if !projectInScope(ctx, p) {
+			continue
+		}
+		result = append(result, convertProjectToModel(p))
 	}
 	return result, nil
 }
 
 // ProjectsByWorkspace is the resolver for the projectsByWorkspace field.
-func (r *queryResolver) ProjectsByWorkspace(ctx context.Context, workspaceID string) ([]*model.Project, error) {
-	projects, err := r.Repo.Project.GetProjectsByWorkspaceID(ctx, workspaceID)
+func (r *queryResolver) ProjectsByWorkspace(ctx context.Context, workspaceID string, filter *model.ProjectFilter, sort *model.ProjectSort, first *int32, after *string) ([]*model.Project, error) {
+	authContext := auth.ForContext(ctx)
+	query, err := r.buildProjectQuery(ctx, filter, sort, first, after)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch projects: %v", err)
+		return nil, err
 	}
-	var result []*model.Project
-	for _, p := range projects {
-		result = append(result, &model.Project{
-			ID:          p.ID.Hex(),
-			Name:        p.Name,
-			Description: &p.Description,
-			Owner:       p.Owner,
-			Workspace:   &workspaceID,
-			Personal:    p.Personal,
-			Elements:    p.Elements,
-			CreatedAt:   p.CreatedAt,
-		})
+	page, err := r.Repo.Project.ListWorkspaceProjects(ctx, workspaceID, authContext.Sub, *query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch projects: %v", err)
 	}
-	return result, nil
+	return convertProjectPageToModel(ctx, page), nil
 }
 
 // OpsSince is the resolver for the opsSince field.
 func (r *queryResolver) OpsSince(ctx context.Context, projectID string, sinceSeq int32, limit *int32) ([]*model.Operation, error) {
+	if err := r.checkProjectAccess(ctx, projectID); err != nil {
+		return nil, err
+	}
 	ops, err := r.Repo.Operation.GetOpsSince(ctx, projectID, sinceSeq, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ops: %v", err)
@@ -288,6 +604,9 @@ func (r *queryResolver) OpsSince(ctx context.Context, projectID string, sinceSeq
 
 // ProjectHistory is the resolver for the projectHistory field.
 func (r *queryResolver) ProjectHistory(ctx context.Context, projectID string, fromSeq int32, toSeq int32) ([]*model.Operation, error) {
+	if err := r.checkProjectAccess(ctx, projectID); err != nil {
+		return nil, err
+	}
 	ops, err := r.Repo.Operation.GetOpsRange(ctx, projectID, fromSeq, toSeq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get project history: %v", err)
@@ -298,6 +617,9 @@ func (r *queryResolver) ProjectHistory(ctx context.Context, projectID string, fr
 // ProjectSnapshotAt is the resolver for the projectSnapshotAt field.
 func (r *queryResolver) ProjectSnapshotAt(ctx context.Context, projectID string, seq int32) (*model.ProjectSnapshot, error) {
 	authContext := auth.ForContext(ctx)
+	if err := r.checkProjectAccess(ctx, projectID); err != nil {
+		return nil, err
+	}
 	elements, lastSeq, timestamp, err := r.Repo.Operation.ReconstructStateAt(ctx, projectID, seq, authContext.Sub)
 	if err != nil {
 		return nil, fmt.Errorf("failed to reconstruct snapshot: %v", err)
@@ -309,6 +631,229 @@ func (r *queryResolver) ProjectSnapshotAt(ctx context.Context, projectID string,
 	}, nil
 }
 
+// ProjectMembers is the resolver for the projectMembers field.
+func (r *queryResolver) ProjectMembers(ctx context.Context, projectID string) ([]*model.ProjectMember, error) {
+	project, err := r.getAccessibleProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if project == nil {
+		return nil, fmt.Errorf("project not found or access denied")
+	}
+
+	inherited := make(map[string]bool)
+	ids := append([]string{project.Owner}, project.Members...)
+	if project.Workspace != nil && !project.Restricted {
+		workspace, err := r.Repo.Workspace.GetWorkspace(ctx, project.Workspace.Hex())
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch workspace: %v", err)
+		}
+		if workspace != nil {
+			for _, id := range append([]string{workspace.Owner}, workspace.Members...) {
+				if id != project.Owner && !slices.Contains(project.Members, id) {
+					inherited[id] = true
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+
+	users, err := r.Repo.User.GetUsersByID(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch member details: %v", err)
+	}
+	result := []*model.ProjectMember{}
+	for _, user := range users {
+		result = append(result, &model.ProjectMember{
+			ID:        user.ID,
+			Email:     user.Email,
+			FullName:  user.FirstName + " " + user.LastName,
+			ImageURL:  "",
+			Inherited: inherited[user.ID],
+		})
+	}
+	return result, nil
+}
+
+// Templates is the resolver for the templates field.
+func (r *queryResolver) Templates(ctx context.Context, workspaceID string) ([]*model.Project, error) {
+	authContext := auth.ForContext(ctx)
+	if !workspaceInScope(ctx, workspaceID) {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	projects, err := r.Repo.Project.GetTemplates(ctx, workspaceID, authContext.Sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch templates: %v", err)
+	}
+	result := []*model.Project{}
+	for _, p := range projects {
+		if !projectInScope(ctx, p) {
+			continue
+		}
+		result = append(result, convertProjectToModel(p))
+	}
+	return result, nil
+}
+
+// getTargetWorkspace checks that the current user may create projects in the
+// workspace. A nil workspace means a project outside any workspace, which
+// scoped access tokens cannot create.
+func (r *Resolver) getTargetWorkspace(ctx context.Context, workspaceID *string) (*models.Workspace, error) {
+	authContext := auth.ForContext(ctx)
+	if workspaceID == nil {
+		if scopeRestricted(ctx) {
+			return nil, fmt.Errorf("access token cannot create projects outside its scope")
+		}
+		return nil, nil
+	}
+	if !workspaceInScope(ctx, *workspaceID) {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	workspace, err := r.Repo.Workspace.GetWorkspaceByID(ctx, *workspaceID, authContext.Sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workspace: %v", err)
+	}
+	if workspace == nil {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	if workspace.Owner != authContext.Sub {
+		return nil, fmt.Errorf("only workspace owner can create project in it")
+	}
+	return workspace, nil
+}
+
+// seedProject creates the project and adds the elements through the op log,
+// so that history and snapshots start from them. Both count towards the
+// workspace's quotas. The project is reloaded with
+// its elements once they are applied.
+func (r *Resolver) seedProject(ctx context.Context, project *models.Project, elements []importer.Element, socketID string) error {
+	if err := r.Quotas.CheckProjects(ctx, project.Workspace, 1); err != nil {
+		return quotaError(ctx, err)
+	}
+	if err := r.Quotas.CheckElements(ctx, project.Workspace, len(elements)); err != nil {
+		return quotaError(ctx, err)
+	}
+	err := r.Repo.Project.NewProject(ctx, project)
+	if err != nil {
+		return fmt.Errorf("failed to create project: %v", err)
+	}
+	if len(elements) == 0 {
+		r.Search.IndexProjectInfo(ctx, project)
+		return nil
+	}
+
+	ops := make([]repository.OpInput, 0, len(elements))
+	for i, el := range elements {
+		data := el.Data
+		ops = append(ops, repository.OpInput{
+			ClientSeq:  int32(i + 1),
+			Type:       "ADD",
+			ElementID:  el.ID,
+			ElementVer: int32(el.Version),
+			BaseSeq:    0,
+			Data:       &data,
+		})
+	}
+	for _, batch := range repository.SplitBatches(ops) {
+		result, err := r.Repo.Operation.ApplyOps(ctx, project.ID.Hex(), socketID, auth.ForContext(ctx).Sub, batch)
+		if err == nil && len(result.Rejected) > 0 {
+			rejected := result.Rejected[0]
+			err = fmt.Errorf("element %q: %s", rejected.ElementID, rejected.Reason)
+		}
+		if err != nil {
+			// Do not leave a half-created project behind
+			if purgeErr := r.Cleanup.PurgeProject(ctx, project.ID); purgeErr != nil {
+				fmt.Printf("Warning: failed to remove partially created project %s: %v\n", project.ID.Hex(), purgeErr)
+			}
+			return fmt.Errorf("failed to add elements: %v", err)
+		}
+	}
+	seeded, err := r.Repo.Project.GetProject(ctx, project.ID.Hex())
+	if err != nil {
+		return fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if seeded != nil {
+		*project = *seeded
+	}
+	r.Search.IndexProject(ctx, project)
+	r.Thumbnails.Schedule(project.ID)
+	return nil
+}
+
+// copyProjectFiles links the source project's image files to a copy of it.
+// The contents are shared, so only the file records are copied.
+func (r *Resolver) copyProjectFiles(ctx context.Context, from bson.ObjectID, to bson.ObjectID) {
+	files, err := r.Repo.File.GetFilesByProject(ctx, from)
+	if err != nil {
+		fmt.Printf("Warning: failed to fetch files of project %s: %v\n", from.Hex(), err)
+		return
+	}
+	for _, file := range files {
+		copied := *file
+		copied.ID = bson.ObjectID{}
+		copied.ProjectID = to
+		if err := r.Repo.File.CreateFile(ctx, &copied); err != nil {
+			fmt.Printf("Warning: failed to copy file %s to project %s: %v\n", file.FileID, to.Hex(), err)
+		}
+	}
+}
+
+func convertProjectToModel(project *models.Project) *model.Project {
+	var workspace, folder *string
+	if project.Workspace != nil {
+		hex := project.Workspace.Hex()
+		workspace = &hex
+	}
+	if project.FolderID != nil {
+		hex := project.FolderID.Hex()
+		folder = &hex
+	}
+	tags := project.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	updatedAt := project.UpdatedAt
+	if updatedAt == "" {
+		updatedAt = project.CreatedAt
+	}
+	var thumbnail *string
+	if project.ThumbnailHash != "" {
+		url := thumbnailPath(project.ThumbnailHash)
+		thumbnail = &url
+	}
+	return &model.Project{
+		ID:           project.ID.Hex(),
+		Name:         project.Name,
+		Description:  &project.Description,
+		Owner:        project.Owner,
+		Workspace:    workspace,
+		FolderID:     folder,
+		Tags:         tags,
+		Personal:     project.Personal,
+		Restricted:   project.Restricted,
+		IsTemplate:   project.IsTemplate,
+		Elements:     project.Elements,
+		ThumbnailURL: thumbnail,
+		CreatedAt:    project.CreatedAt,
+		UpdatedAt:    updatedAt,
+	}
+}
+
+// convertProjectPageToModel converts a page of projects, setting each one's
+// cursor. Projects outside the access token's scope are left out.
+func convertProjectPageToModel(ctx context.Context, page *repository.ProjectPage) []*model.Project {
+	result := []*model.Project{}
+	for i, p := range page.Projects {
+		if !projectInScope(ctx, p) {
+			continue
+		}
+		project := convertProjectToModel(p)
+		project.Cursor = &page.Cursors[i]
+		result = append(result, project)
+	}
+	return result
+}
+
 func convertOpsToModel(ops []*models.Operation) []*model.Operation {
 	var result []*model.Operation
 	for _, op := range ops {
@@ -332,10 +877,9 @@ func convertOpsToModel(ops []*models.Operation) []*model.Operation {
 // Project is the resolver for the project field.
 func (r *subscriptionResolver) Project(ctx context.Context, id string) (<-chan *model.ProjectSubscription, error) {
 	fmt.Println("Trying to subscribe to project:", id)
-	authContext := auth.ForContext(ctx)
 
 	// Verify user has access to this project
-	project, err := r.Repo.Project.GetProjectByID(ctx, id, authContext.Sub)
+	project, err := r.getAccessibleProject(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch project: %v", err)
 	}
@@ -346,15 +890,9 @@ func (r *subscriptionResolver) Project(ctx context.Context, id string) (<-chan *
 	// Create a channel for this subscription (buffer of 64 to prevent dropped updates)
 	ch := make(chan *model.ProjectSubscription, 64)
 
-	// Subscribe to project updates
-	socketID := r.subscribeToProject(id, ch)
+	// Subscribe to project updates, starting with the current state
+	socketID := r.subscribeToProject(id, holderForContext(ctx), project.Elements, ch)
 	println("Subscribed to project:", id, "with socketID:", socketID)
-	// Send initial project state
-	initialProject := &model.ProjectSubscription{
-		Elements: project.Elements,
-		SocketID: socketID,
-	}
-	ch <- initialProject
 
 	// Clean up when context is done
 	go func(socketID string) {
@@ -371,7 +909,7 @@ func (r *subscriptionResolver) ProjectOps(ctx context.Context, id string) (<-cha
 	authContext := auth.ForContext(ctx)
 
 	// Verify user has access to this project
-	project, err := r.Repo.Project.GetProjectByID(ctx, id, authContext.Sub)
+	project, err := r.getAccessibleProject(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch project: %v", err)
 	}
@@ -380,17 +918,14 @@ func (r *subscriptionResolver) ProjectOps(ctx context.Context, id string) (<-cha
 	}
 
 	ch := make(chan *model.ProjectOpsSubscription, 64)
-	socketID := r.subscribeToProjectOps(id, authContext.Sub, authContext.PreferredUsername, ch)
+	// Subscribe and join presence; the first message carries the socketID
+	socketID := r.subscribeToProjectOps(id, holderForContext(ctx), PresenceInfo{
+		UserName: authContext.PreferredUsername,
+		Email:    authContext.Email,
+		Guest:    auth.IsGuest(ctx),
+		JoinedAt: time.Now().Format(time.RFC3339),
+	}, ch)
 	fmt.Println("Subscribed to project ops:", id, "with socketID:", socketID)
-
-	// Send initial empty ops message with the socketID
-	ch <- &model.ProjectOpsSubscription{
-		Ops:      []*model.Operation{},
-		SocketID: socketID,
-	}
-
-	// Add to presence tracking
-	r.addPresence(id, authContext.Sub, authContext.PreferredUsername, authContext.Email, time.Now().Format(time.RFC3339))
 	r.broadcastPresence(id)
 
 	// Clean up when context is done
@@ -403,3 +938,8 @@ func (r *subscriptionResolver) ProjectOps(ctx context.Context, id string) (<-cha
 
 	return ch, nil
 }
+
+// Project returns graph.ProjectResolver implementation.
+func (r *Resolver) Project() graph.ProjectResolver { return &projectResolver{r} }
+
+type projectResolver struct{ *Resolver }
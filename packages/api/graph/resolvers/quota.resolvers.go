@@ -0,0 +1,44 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chirag3003/collab-draw-backend/graph/model"
+)
+
+// WorkspaceQuota is the resolver for the workspaceQuota field.
+func (r *queryResolver) WorkspaceQuota(ctx context.Context, workspaceID string) (*model.WorkspaceQuota, error) {
+	workspace, err := r.getMemberWorkspace(ctx, workspaceID, false)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := r.Quotas.Usage(ctx, workspace.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workspace usage: %v", err)
+	}
+	limits := r.Quotas.Limits()
+	result := &model.WorkspaceQuota{
+		WorkspaceID:  workspace.ID.Hex(),
+		Projects:     int32(usage.Projects),
+		Elements:     int32(usage.Elements),
+		StorageBytes: float64(usage.StorageBytes),
+	}
+	if limits.MaxProjects > 0 {
+		v := int32(limits.MaxProjects)
+		result.MaxProjects = &v
+	}
+	if limits.MaxElements > 0 {
+		v := int32(limits.MaxElements)
+		result.MaxElements = &v
+	}
+	if limits.MaxStorageBytes > 0 {
+		v := float64(limits.MaxStorageBytes)
+		result.MaxStorageBytes = &v
+	}
+	return result, nil
+}
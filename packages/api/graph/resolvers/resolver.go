@@ -3,31 +3,65 @@ package resolvers
 //go:generate go run github.com/99designs/gqlgen generate
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/rand/v2"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/chirag3003/collab-draw-backend/graph/model"
+	"github.com/chirag3003/collab-draw-backend/internal/auth"
+	"github.com/chirag3003/collab-draw-backend/internal/cleanup"
+	"github.com/chirag3003/collab-draw-backend/internal/filestore"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"github.com/chirag3003/collab-draw-backend/internal/quota"
 	"github.com/chirag3003/collab-draw-backend/internal/repository"
+	"github.com/chirag3003/collab-draw-backend/internal/search"
+	"github.com/chirag3003/collab-draw-backend/internal/thumbnail"
+	"github.com/chirag3003/collab-draw-backend/internal/webhook"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 // This file will not be regenerated automatically.
 //
 // It serves as dependency injection for your app, add any dependencies you require here.
 
+// subscriptionHolder identifies who holds a project subscription, so that the
+// subscriptions of a user or of a share link's guests can be ended.
+type subscriptionHolder struct {
+	userID      string
+	shareLinkID string // set for share link guests
+}
+
+// holderForContext returns the holder of subscriptions made with ctx.
+func holderForContext(ctx context.Context) subscriptionHolder {
+	holder := subscriptionHolder{userID: auth.ForContext(ctx).Sub}
+	if scope := auth.ScopeForContext(ctx); scope != nil {
+		holder.shareLinkID = scope.ShareLinkID
+	}
+	return holder
+}
+
 type ProjectSubscriber struct {
+	subscriptionHolder
 	sockedID string
 	channel  chan *model.ProjectSubscription
 }
 
 type ProjectOpsSubscriber struct {
+	subscriptionHolder
 	sockedID string
-	userID   string
 	userName string
 	channel  chan *model.ProjectOpsSubscription
 }
 
 type CursorSubscriber struct {
+	subscriptionHolder
 	sockedID string
 	channel  chan *model.CursorUpdate
 }
@@ -36,34 +70,85 @@ type PresenceInfo struct {
 	UserID   string
 	UserName string
 	Email    string
+	Guest    bool
 	JoinedAt string
 	Status   model.PresenceStatus
 }
 
 type PresenceSubscriber struct {
+	subscriptionHolder
 	sockedID string
 	channel  chan []*model.UserPresence
 }
 
+type CommentSubscriber struct {
+	subscriptionHolder
+	sockedID string
+	channel  chan *model.CommentEvent
+}
+
+type NotificationSubscriber struct {
+	sockedID string
+	channel  chan *model.Notification
+}
+
+type EventSubscriber struct {
+	sockedID string
+	userID   string
+	channel  chan *model.Event
+}
+
+type LibrarySubscriber struct {
+	sockedID string
+	userID   string
+	channel  chan *model.LibraryEvent
+}
+
 type Resolver struct {
-	Repo               *repository.Repository
-	projectSubscribers map[string][]ProjectSubscriber
-	opsSubscribers     map[string][]ProjectOpsSubscriber
-	cursorSubscribers  map[string][]CursorSubscriber
-	projectPresence    map[string]map[string]*PresenceInfo // projectID -> userID -> info
+	Repo                *repository.Repository
+	Cleanup             *cleanup.Service
+	Webhooks            *webhook.Dispatcher
+	Files               *filestore.Service
+	Search              *search.Indexer
+	Thumbnails          *thumbnail.Service
+	Quotas              *quota.Service
+	projectSubscribers  map[string][]ProjectSubscriber
+	opsSubscribers      map[string][]ProjectOpsSubscriber
+	cursorSubscribers   map[string][]CursorSubscriber
+	projectPresence     map[string]map[string]*PresenceInfo // projectID -> userID -> info
 	presenceSubscribers map[string][]PresenceSubscriber
-	subscribersMutex   sync.RWMutex
+	commentSubscribers  map[string][]CommentSubscriber
+	workspaceEventSubs  map[string][]EventSubscriber        // workspaceID -> subscribers
+	userEventSubs       map[string][]EventSubscriber        // userID -> subscribers
+	notificationSubs    map[string][]NotificationSubscriber // userID -> subscribers
+	librarySubs         map[string][]LibrarySubscriber      // workspaceID -> subscribers
+	subscribersMutex    sync.RWMutex
 }
 
-func NewResolver(repo *repository.Repository) *Resolver {
-	return &Resolver{
-		Repo:               repo,
-		projectSubscribers: make(map[string][]ProjectSubscriber),
-		opsSubscribers:     make(map[string][]ProjectOpsSubscriber),
-		cursorSubscribers:  make(map[string][]CursorSubscriber),
-		projectPresence:    make(map[string]map[string]*PresenceInfo),
+func NewResolver(repo *repository.Repository, cleanupService *cleanup.Service, webhooks *webhook.Dispatcher, files *filestore.Service, searchIndexer *search.Indexer, thumbnails *thumbnail.Service, quotas *quota.Service) *Resolver {
+	r := &Resolver{
+		Repo:                repo,
+		Cleanup:             cleanupService,
+		Webhooks:            webhooks,
+		Files:               files,
+		Search:              searchIndexer,
+		Thumbnails:          thumbnails,
+		Quotas:              quotas,
+		projectSubscribers:  make(map[string][]ProjectSubscriber),
+		opsSubscribers:      make(map[string][]ProjectOpsSubscriber),
+		cursorSubscribers:   make(map[string][]CursorSubscriber),
+		projectPresence:     make(map[string]map[string]*PresenceInfo),
 		presenceSubscribers: make(map[string][]PresenceSubscriber),
+		commentSubscribers:  make(map[string][]CommentSubscriber),
+		workspaceEventSubs:  make(map[string][]EventSubscriber),
+		userEventSubs:       make(map[string][]EventSubscriber),
+		notificationSubs:    make(map[string][]NotificationSubscriber),
+		librarySubs:         make(map[string][]LibrarySubscriber),
 	}
+	cleanupService.OnProjectRemoved(func(projectID string) {
+		r.terminateProjectSubscriptions(projectID, "project deleted")
+	})
+	return r
 }
 
 func generateRandom8DigitString() string {
@@ -77,13 +162,200 @@ func generateRandom8DigitString() string {
 	return fmt.Sprintf("%d", randomNumber)
 }
 
-// Subscribe adds a subscriber for a specific project
-func (r *Resolver) subscribeToProject(projectID string, ch chan *model.ProjectSubscription) string {
+// scopeRestricted reports whether the request was made with an access token
+// limited to a single project or workspace.
+func scopeRestricted(ctx context.Context) bool {
+	scope := auth.ScopeForContext(ctx)
+	return scope != nil && (scope.ProjectID != "" || scope.WorkspaceID != "")
+}
+
+// projectInScope reports whether the request's access token, if any, may
+// reach the given project.
+func projectInScope(ctx context.Context, project *models.Project) bool {
+	scope := auth.ScopeForContext(ctx)
+	if scope == nil {
+		return true
+	}
+	if scope.ProjectID != "" && scope.ProjectID != project.ID.Hex() {
+		return false
+	}
+	if scope.WorkspaceID != "" && (project.Workspace == nil || project.Workspace.Hex() != scope.WorkspaceID) {
+		return false
+	}
+	return true
+}
+
+// workspaceInScope reports whether the request's access token, if any, may
+// reach the given workspace. Project scoped tokens never reach workspaces.
+func workspaceInScope(ctx context.Context, workspaceID string) bool {
+	scope := auth.ScopeForContext(ctx)
+	if scope == nil {
+		return true
+	}
+	if scope.ProjectID != "" {
+		return false
+	}
+	return scope.WorkspaceID == "" || scope.WorkspaceID == workspaceID
+}
+
+// getAccessibleProject fetches a project the current user can access and that
+// is within the scope of their access token or share link. It returns nil when
+// not found.
+func (r *Resolver) getAccessibleProject(ctx context.Context, projectID string) (*models.Project, error) {
+	var project *models.Project
+	var err error
+	if auth.IsGuest(ctx) {
+		// Guests are not members; the share link scope below grants access
+		project, err = r.Repo.Project.GetProject(ctx, projectID)
+	} else {
+		authContext := auth.ForContext(ctx)
+		project, err = r.Repo.Project.GetProjectByID(ctx, projectID, authContext.Sub)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if project == nil || !projectInScope(ctx, project) {
+		return nil, nil
+	}
+	return project, nil
+}
+
+// getMemberWorkspace fetches a workspace the current user is a member of and
+// within the scope of their access token. With edit set, viewers are refused.
+func (r *Resolver) getMemberWorkspace(ctx context.Context, workspaceID string, edit bool) (*models.Workspace, error) {
+	authContext := auth.ForContext(ctx)
+	if authContext == nil || auth.IsGuest(ctx) {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	if !workspaceInScope(ctx, workspaceID) {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	workspace, err := r.Repo.Workspace.GetWorkspaceByID(ctx, workspaceID, authContext.Sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workspace: %v", err)
+	}
+	if workspace == nil {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	if edit && workspace.RoleOf(authContext.Sub) == models.WorkspaceRoleViewer {
+		return nil, fmt.Errorf("viewers cannot make changes in this workspace")
+	}
+	return workspace, nil
+}
+
+// checkProjectScope returns an error when the request's access token is not
+// allowed to reach the project. Unscoped requests skip the lookup entirely.
+func (r *Resolver) checkProjectScope(ctx context.Context, projectID string) error {
+	if !scopeRestricted(ctx) {
+		return nil
+	}
+	project, err := r.getAccessibleProject(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if project == nil {
+		return fmt.Errorf("project not found or access denied")
+	}
+	return nil
+}
+
+// checkProjectAccess returns an error unless the current user can access the
+// project within the scope of their access token.
+func (r *Resolver) checkProjectAccess(ctx context.Context, projectID string) error {
+	project, err := r.getAccessibleProject(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if project == nil {
+		return fmt.Errorf("project not found or access denied")
+	}
+	return nil
+}
+
+// getEditableProject fetches a project for writing: on top of the checks of
+// getAccessibleProject it rejects users who only have the viewer role in the
+// project's workspace.
+func (r *Resolver) getEditableProject(ctx context.Context, projectID string) (*models.Project, error) {
+	project, err := r.getAccessibleProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if project == nil {
+		return nil, fmt.Errorf("project not found or access denied")
+	}
+	authContext := auth.ForContext(ctx)
+	if project.Workspace == nil || project.Owner == authContext.Sub || auth.IsGuest(ctx) {
+		return project, nil
+	}
+	// Direct project members can edit regardless of their workspace role
+	if slices.Contains(project.Members, authContext.Sub) {
+		return project, nil
+	}
+	workspace, err := r.Repo.Workspace.GetWorkspace(ctx, project.Workspace.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workspace: %v", err)
+	}
+	if workspace != nil && workspace.RoleOf(authContext.Sub) == models.WorkspaceRoleViewer {
+		return nil, fmt.Errorf("viewers cannot edit this project")
+	}
+	return project, nil
+}
+
+// quotaError turns a quota check failure into the error returned to clients.
+// Exceeded quotas carry a code and the numbers so clients can explain them.
+func quotaError(ctx context.Context, err error) error {
+	var exceeded *quota.ExceededError
+	if !errors.As(err, &exceeded) {
+		return fmt.Errorf("failed to check workspace quota: %v", err)
+	}
+	return &gqlerror.Error{
+		Message: exceeded.Error(),
+		Path:    graphql.GetPath(ctx),
+		Extensions: map[string]any{
+			"code":     "QUOTA_EXCEEDED",
+			"resource": exceeded.Resource,
+			"limit":    exceeded.Limit,
+			"used":     exceeded.Used,
+		},
+	}
+}
+
+// recordAudit appends an entry to the audit log. Failures are logged rather
+// than returned so that auditing never blocks the action itself.
+func (r *Resolver) recordAudit(ctx context.Context, action string, targetType string, targetID string, workspaceID *bson.ObjectID, before bson.M, after bson.M) {
+	authContext := auth.ForContext(ctx)
+	requestInfo := auth.RequestInfoForContext(ctx)
+	entry := &models.AuditEntry{
+		ActorID:     authContext.Sub,
+		ActorName:   authContext.PreferredUsername,
+		Action:      action,
+		TargetType:  targetType,
+		TargetID:    targetID,
+		WorkspaceID: workspaceID,
+		Before:      before,
+		After:       after,
+		IP:          requestInfo.IP,
+		UserAgent:   requestInfo.UserAgent,
+	}
+	if err := r.Repo.Audit.Record(ctx, entry); err != nil {
+		fmt.Printf("Warning: failed to record audit entry %s on %s: %v\n", action, targetID, err)
+	}
+}
+
+// Subscribe adds a subscriber for a specific project. The initial project
+// state is queued on the channel before the subscriber becomes visible, since
+// the channel may be closed by a termination as soon as it is.
+func (r *Resolver) subscribeToProject(projectID string, holder subscriptionHolder, elements string, ch chan *model.ProjectSubscription) string {
 	r.subscribersMutex.Lock()
 	defer r.subscribersMutex.Unlock()
 	subscriber := ProjectSubscriber{
-		channel:  ch,
-		sockedID: generateRandom8DigitString(),
+		channel:            ch,
+		subscriptionHolder: holder,
+		sockedID:           generateRandom8DigitString(),
+	}
+	ch <- &model.ProjectSubscription{
+		Elements: elements,
+		SocketID: subscriber.sockedID,
 	}
 	r.projectSubscribers[projectID] = append(r.projectSubscribers[projectID], subscriber)
 	return subscriber.sockedID
@@ -133,17 +405,31 @@ func (r *Resolver) broadcastProjectUpdate(projectID string, project *model.Proje
 	}
 }
 
-// subscribeToProjectOps adds an ops subscriber for a specific project
-func (r *Resolver) subscribeToProjectOps(projectID string, userID string, userName string, ch chan *model.ProjectOpsSubscription) string {
+// subscribeToProjectOps adds an ops subscriber for a specific project and
+// marks the user present. The initial message carrying the socket ID is
+// queued first, and presence is added in the same step, so that a termination
+// either sees both or neither.
+func (r *Resolver) subscribeToProjectOps(projectID string, holder subscriptionHolder, presence PresenceInfo, ch chan *model.ProjectOpsSubscription) string {
 	r.subscribersMutex.Lock()
 	defer r.subscribersMutex.Unlock()
 	subscriber := ProjectOpsSubscriber{
-		channel:  ch,
-		sockedID: generateRandom8DigitString(),
-		userID:   userID,
-		userName: userName,
+		channel:            ch,
+		subscriptionHolder: holder,
+		sockedID:           generateRandom8DigitString(),
+		userName:           presence.UserName,
+	}
+	ch <- &model.ProjectOpsSubscription{
+		Ops:      []*model.Operation{},
+		SocketID: subscriber.sockedID,
 	}
 	r.opsSubscribers[projectID] = append(r.opsSubscribers[projectID], subscriber)
+
+	if r.projectPresence[projectID] == nil {
+		r.projectPresence[projectID] = make(map[string]*PresenceInfo)
+	}
+	presence.UserID = holder.userID
+	presence.Status = model.PresenceStatusActive
+	r.projectPresence[projectID][holder.userID] = &presence
 	return subscriber.sockedID
 }
 
@@ -190,12 +476,13 @@ func (r *Resolver) broadcastOps(projectID string, ops []*model.Operation, fromSo
 }
 
 // subscribeToCursors adds a cursor subscriber
-func (r *Resolver) subscribeToCursors(projectID string, ch chan *model.CursorUpdate) string {
+func (r *Resolver) subscribeToCursors(projectID string, holder subscriptionHolder, ch chan *model.CursorUpdate) string {
 	r.subscribersMutex.Lock()
 	defer r.subscribersMutex.Unlock()
 	subscriber := CursorSubscriber{
-		channel:  ch,
-		sockedID: generateRandom8DigitString(),
+		channel:            ch,
+		subscriptionHolder: holder,
+		sockedID:           generateRandom8DigitString(),
 	}
 	r.cursorSubscribers[projectID] = append(r.cursorSubscribers[projectID], subscriber)
 	return subscriber.sockedID
@@ -239,23 +526,6 @@ func (r *Resolver) broadcastCursor(projectID string, cursor *model.CursorUpdate,
 	}
 }
 
-// addPresence adds a user to project presence
-func (r *Resolver) addPresence(projectID string, userID string, userName string, email string, joinedAt string) {
-	r.subscribersMutex.Lock()
-	defer r.subscribersMutex.Unlock()
-
-	if r.projectPresence[projectID] == nil {
-		r.projectPresence[projectID] = make(map[string]*PresenceInfo)
-	}
-	r.projectPresence[projectID][userID] = &PresenceInfo{
-		UserID:   userID,
-		UserName: userName,
-		Email:    email,
-		JoinedAt: joinedAt,
-		Status:   model.PresenceStatusActive,
-	}
-}
-
 // removePresence removes a user from project presence
 func (r *Resolver) removePresence(projectID string, userID string) {
 	r.subscribersMutex.Lock()
@@ -273,7 +543,12 @@ func (r *Resolver) removePresence(projectID string, userID string) {
 func (r *Resolver) getPresenceList(projectID string) []*model.UserPresence {
 	r.subscribersMutex.RLock()
 	defer r.subscribersMutex.RUnlock()
+	return r.presenceList(projectID)
+}
 
+// presenceList builds the presence of a project. The caller holds
+// subscribersMutex.
+func (r *Resolver) presenceList(projectID string) []*model.UserPresence {
 	var result []*model.UserPresence
 	if users, ok := r.projectPresence[projectID]; ok {
 		for _, info := range users {
@@ -281,6 +556,7 @@ func (r *Resolver) getPresenceList(projectID string) []*model.UserPresence {
 				UserID:   info.UserID,
 				UserName: info.UserName,
 				Email:    info.Email,
+				Guest:    info.Guest,
 				Status:   info.Status,
 				JoinedAt: info.JoinedAt,
 			})
@@ -289,14 +565,17 @@ func (r *Resolver) getPresenceList(projectID string) []*model.UserPresence {
 	return result
 }
 
-// subscribeToPresence adds a presence subscriber
-func (r *Resolver) subscribeToPresence(projectID string, ch chan []*model.UserPresence) string {
+// subscribeToPresence adds a presence subscriber, queueing the current
+// presence list before the subscriber becomes visible.
+func (r *Resolver) subscribeToPresence(projectID string, holder subscriptionHolder, ch chan []*model.UserPresence) string {
 	r.subscribersMutex.Lock()
 	defer r.subscribersMutex.Unlock()
 	subscriber := PresenceSubscriber{
-		channel:  ch,
-		sockedID: generateRandom8DigitString(),
+		channel:            ch,
+		subscriptionHolder: holder,
+		sockedID:           generateRandom8DigitString(),
 	}
+	ch <- r.presenceList(projectID)
 	r.presenceSubscribers[projectID] = append(r.presenceSubscribers[projectID], subscriber)
 	return subscriber.sockedID
 }
@@ -340,3 +619,656 @@ func (r *Resolver) broadcastPresence(projectID string) {
 		}
 	}
 }
+
+// terminateProjectSubscriptions ends every live subscription to a project.
+// Project and ops subscribers get a last message carrying the reason before
+// their channel is closed.
+func (r *Resolver) terminateProjectSubscriptions(projectID string, reason string) {
+	r.subscribersMutex.Lock()
+	defer r.subscribersMutex.Unlock()
+
+	for _, subscriber := range r.projectSubscribers[projectID] {
+		select {
+		case subscriber.channel <- &model.ProjectSubscription{SocketID: subscriber.sockedID, ClosedReason: &reason}:
+		default:
+		}
+		close(subscriber.channel)
+	}
+	for _, subscriber := range r.opsSubscribers[projectID] {
+		select {
+		case subscriber.channel <- &model.ProjectOpsSubscription{Ops: []*model.Operation{}, SocketID: subscriber.sockedID, ClosedReason: &reason}:
+		default:
+		}
+		close(subscriber.channel)
+	}
+	for _, subscriber := range r.cursorSubscribers[projectID] {
+		close(subscriber.channel)
+	}
+	for _, subscriber := range r.presenceSubscribers[projectID] {
+		close(subscriber.channel)
+	}
+	for _, subscriber := range r.commentSubscribers[projectID] {
+		close(subscriber.channel)
+	}
+
+	delete(r.projectSubscribers, projectID)
+	delete(r.opsSubscribers, projectID)
+	delete(r.cursorSubscribers, projectID)
+	delete(r.presenceSubscribers, projectID)
+	delete(r.commentSubscribers, projectID)
+	delete(r.projectPresence, projectID)
+}
+
+// subscribeToComments adds a comment subscriber for a project
+func (r *Resolver) subscribeToComments(projectID string, holder subscriptionHolder, ch chan *model.CommentEvent) string {
+	r.subscribersMutex.Lock()
+	defer r.subscribersMutex.Unlock()
+	subscriber := CommentSubscriber{
+		channel:            ch,
+		subscriptionHolder: holder,
+		sockedID:           generateRandom8DigitString(),
+	}
+	r.commentSubscribers[projectID] = append(r.commentSubscribers[projectID], subscriber)
+	return subscriber.sockedID
+}
+
+// unsubscribeFromComments removes a comment subscriber
+func (r *Resolver) unsubscribeFromComments(projectID string, socketID string) {
+	r.subscribersMutex.Lock()
+	defer r.subscribersMutex.Unlock()
+
+	subscribers := r.commentSubscribers[projectID]
+	for i, subscriber := range subscribers {
+		if subscriber.sockedID == socketID {
+			r.commentSubscribers[projectID] = append(subscribers[:i], subscribers[i+1:]...)
+			close(subscriber.channel)
+			break
+		}
+	}
+
+	if len(r.commentSubscribers[projectID]) == 0 {
+		delete(r.commentSubscribers, projectID)
+	}
+}
+
+// broadcastComment sends a comment event to all subscribers of the project
+func (r *Resolver) broadcastComment(projectID string, event *model.CommentEvent) {
+	r.subscribersMutex.RLock()
+	defer r.subscribersMutex.RUnlock()
+
+	for _, subscriber := range r.commentSubscribers[projectID] {
+		select {
+		case subscriber.channel <- event:
+		default:
+			fmt.Printf("Warning: dropped comment event for subscriber %s on project %s (channel full)\n", subscriber.sockedID, projectID)
+		}
+	}
+}
+
+// subscribeToNotifications adds a notification subscriber for a user
+func (r *Resolver) subscribeToNotifications(userID string, ch chan *model.Notification) string {
+	r.subscribersMutex.Lock()
+	defer r.subscribersMutex.Unlock()
+	subscriber := NotificationSubscriber{
+		channel:  ch,
+		sockedID: generateRandom8DigitString(),
+	}
+	r.notificationSubs[userID] = append(r.notificationSubs[userID], subscriber)
+	return subscriber.sockedID
+}
+
+// unsubscribeFromNotifications removes a notification subscriber
+func (r *Resolver) unsubscribeFromNotifications(userID string, socketID string) {
+	r.subscribersMutex.Lock()
+	defer r.subscribersMutex.Unlock()
+
+	subscribers := r.notificationSubs[userID]
+	for i, subscriber := range subscribers {
+		if subscriber.sockedID == socketID {
+			r.notificationSubs[userID] = append(subscribers[:i], subscribers[i+1:]...)
+			close(subscriber.channel)
+			break
+		}
+	}
+
+	if len(r.notificationSubs[userID]) == 0 {
+		delete(r.notificationSubs, userID)
+	}
+}
+
+// pushNotification sends a notification to the user's live subscribers
+func (r *Resolver) pushNotification(userID string, notification *model.Notification) {
+	r.subscribersMutex.RLock()
+	defer r.subscribersMutex.RUnlock()
+
+	for _, subscriber := range r.notificationSubs[userID] {
+		select {
+		case subscriber.channel <- notification:
+		default:
+			fmt.Printf("Warning: dropped notification for subscriber %s of user %s (channel full)\n", subscriber.sockedID, userID)
+		}
+	}
+}
+
+// subscribeToLibraries adds a library subscriber for a workspace
+func (r *Resolver) subscribeToLibraries(workspaceID string, userID string, ch chan *model.LibraryEvent) string {
+	r.subscribersMutex.Lock()
+	defer r.subscribersMutex.Unlock()
+	subscriber := LibrarySubscriber{
+		channel:  ch,
+		sockedID: generateRandom8DigitString(),
+		userID:   userID,
+	}
+	r.librarySubs[workspaceID] = append(r.librarySubs[workspaceID], subscriber)
+	return subscriber.sockedID
+}
+
+// unsubscribeFromLibraries removes a library subscriber
+func (r *Resolver) unsubscribeFromLibraries(workspaceID string, socketID string) {
+	r.subscribersMutex.Lock()
+	defer r.subscribersMutex.Unlock()
+
+	subscribers := r.librarySubs[workspaceID]
+	for i, subscriber := range subscribers {
+		if subscriber.sockedID == socketID {
+			r.librarySubs[workspaceID] = append(subscribers[:i], subscribers[i+1:]...)
+			close(subscriber.channel)
+			break
+		}
+	}
+
+	if len(r.librarySubs[workspaceID]) == 0 {
+		delete(r.librarySubs, workspaceID)
+	}
+}
+
+// broadcastLibrary sends a library event to all subscribers of the workspace
+func (r *Resolver) broadcastLibrary(workspaceID string, event *model.LibraryEvent) {
+	r.subscribersMutex.RLock()
+	defer r.subscribersMutex.RUnlock()
+
+	for _, subscriber := range r.librarySubs[workspaceID] {
+		select {
+		case subscriber.channel <- event:
+		default:
+			fmt.Printf("Warning: dropped library event for subscriber %s on workspace %s (channel full)\n", subscriber.sockedID, workspaceID)
+		}
+	}
+}
+
+// subscribeToWorkspaceEvents adds an event subscriber for a workspace
+func (r *Resolver) subscribeToWorkspaceEvents(workspaceID string, userID string, ch chan *model.Event) string {
+	r.subscribersMutex.Lock()
+	defer r.subscribersMutex.Unlock()
+	subscriber := EventSubscriber{
+		channel:  ch,
+		sockedID: generateRandom8DigitString(),
+		userID:   userID,
+	}
+	r.workspaceEventSubs[workspaceID] = append(r.workspaceEventSubs[workspaceID], subscriber)
+	return subscriber.sockedID
+}
+
+// subscribeToUserEvents adds an event subscriber for a user
+func (r *Resolver) subscribeToUserEvents(userID string, ch chan *model.Event) string {
+	r.subscribersMutex.Lock()
+	defer r.subscribersMutex.Unlock()
+	subscriber := EventSubscriber{
+		channel:  ch,
+		sockedID: generateRandom8DigitString(),
+		userID:   userID,
+	}
+	r.userEventSubs[userID] = append(r.userEventSubs[userID], subscriber)
+	return subscriber.sockedID
+}
+
+// unsubscribeFromEvents removes an event subscriber from the given map
+func (r *Resolver) unsubscribeFromEvents(subs map[string][]EventSubscriber, key string, socketID string) {
+	r.subscribersMutex.Lock()
+	defer r.subscribersMutex.Unlock()
+
+	subscribers := subs[key]
+	for i, subscriber := range subscribers {
+		if subscriber.sockedID == socketID {
+			subs[key] = append(subscribers[:i], subscribers[i+1:]...)
+			close(subscriber.channel)
+			break
+		}
+	}
+
+	if len(subs[key]) == 0 {
+		delete(subs, key)
+	}
+}
+
+// webhookEvents maps live events to the webhook events they trigger
+var webhookEvents = map[model.EventType]string{
+	model.EventTypeProjectCreated: models.WebhookEventProjectCreated,
+	model.EventTypeProjectDeleted: models.WebhookEventProjectDeleted,
+	model.EventTypeMemberAdded:    models.WebhookEventMemberAdded,
+	model.EventTypeMemberRemoved:  models.WebhookEventMemberRemoved,
+}
+
+// newEvent creates an event caused by the current user
+func newEvent(ctx context.Context, eventType model.EventType) *model.Event {
+	authContext := auth.ForContext(ctx)
+	return &model.Event{
+		Type:      eventType,
+		ActorID:   authContext.Sub,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+}
+
+// publishWorkspaceEvent sends an event to the workspace's subscribers. When
+// audience is non-nil only those users receive it.
+func (r *Resolver) publishWorkspaceEvent(workspaceID string, event *model.Event, audience []string) {
+	r.subscribersMutex.RLock()
+	defer r.subscribersMutex.RUnlock()
+
+	for _, subscriber := range r.workspaceEventSubs[workspaceID] {
+		if audience != nil && !slices.Contains(audience, subscriber.userID) {
+			continue
+		}
+		select {
+		case subscriber.channel <- event:
+		default:
+			fmt.Printf("Warning: dropped event for subscriber %s on workspace %s (channel full)\n", subscriber.sockedID, workspaceID)
+		}
+	}
+}
+
+// publishUserEvent sends an event to the myEvents subscribers of the users
+func (r *Resolver) publishUserEvent(event *model.Event, userIDs ...string) {
+	r.subscribersMutex.RLock()
+	defer r.subscribersMutex.RUnlock()
+
+	for _, userID := range userIDs {
+		for _, subscriber := range r.userEventSubs[userID] {
+			select {
+			case subscriber.channel <- event:
+			default:
+				fmt.Printf("Warning: dropped event for subscriber %s of user %s (channel full)\n", subscriber.sockedID, userID)
+			}
+		}
+	}
+}
+
+// publishProjectEvent routes an event about a project: workspace projects go
+// to the workspace's subscribers who can see the project, personal projects
+// to their owner and members.
+func (r *Resolver) publishProjectEvent(ctx context.Context, eventType model.EventType, project *models.Project) {
+	event := newEvent(ctx, eventType)
+	projectID := project.ID.Hex()
+	event.ProjectID = &projectID
+	event.Name = &project.Name
+	event.Description = &project.Description
+	if project.Workspace == nil {
+		r.publishUserEvent(event, append([]string{project.Owner}, project.Members...)...)
+		return
+	}
+	workspaceID := project.Workspace.Hex()
+	event.WorkspaceID = &workspaceID
+	var audience []string
+	if project.Restricted {
+		audience = append([]string{project.Owner}, project.Members...)
+	}
+	r.publishWorkspaceEvent(workspaceID, event, audience)
+	if webhookEvent, ok := webhookEvents[eventType]; ok {
+		r.Webhooks.Enqueue(ctx, *project.Workspace, webhookEvent, event)
+	}
+}
+
+// publishWorkspaceChange announces a change to the workspace itself to its
+// subscribers and to the myEvents subscriptions of everyone in it.
+func (r *Resolver) publishWorkspaceChange(ctx context.Context, eventType model.EventType, workspace *models.Workspace) {
+	event := newEvent(ctx, eventType)
+	workspaceID := workspace.ID.Hex()
+	event.WorkspaceID = &workspaceID
+	event.Name = &workspace.Name
+	event.Description = &workspace.Description
+	r.publishWorkspaceEvent(workspaceID, event, nil)
+	r.publishUserEvent(event, append([]string{workspace.Owner}, workspace.Members...)...)
+}
+
+// publishMemberEvent announces that a user joined or left a workspace or, when
+// project is set, a project.
+func (r *Resolver) publishMemberEvent(ctx context.Context, eventType model.EventType, workspaceID *bson.ObjectID, project *models.Project, userID string) {
+	event := newEvent(ctx, eventType)
+	event.UserID = &userID
+	var audience []string
+	if project != nil {
+		projectID := project.ID.Hex()
+		event.ProjectID = &projectID
+		event.Name = &project.Name
+		audience = append([]string{project.Owner, userID}, project.Members...)
+	}
+	if workspaceID == nil {
+		r.publishUserEvent(event, audience...)
+		return
+	}
+	id := workspaceID.Hex()
+	event.WorkspaceID = &id
+	if project == nil || !project.Restricted {
+		audience = nil
+	}
+	r.publishWorkspaceEvent(id, event, audience)
+	r.publishUserEvent(event, userID)
+	if webhookEvent, ok := webhookEvents[eventType]; ok {
+		r.Webhooks.Enqueue(ctx, *workspaceID, webhookEvent, event)
+	}
+}
+
+// notifyAccessLoss sends AccessRevoked to the users who can no longer reach
+// the project and ends their live sessions on it.
+func (r *Resolver) notifyAccessLoss(ctx context.Context, project *models.Project, userIDs []string) {
+	projectID := project.ID.Hex()
+	for _, userID := range userIDs {
+		accessible, err := r.Repo.Project.GetProjectByID(ctx, projectID, userID)
+		if err != nil || accessible != nil {
+			continue
+		}
+		event := newEvent(ctx, model.EventTypeAccessRevoked)
+		event.ProjectID = &projectID
+		event.UserID = &userID
+		if project.Workspace != nil {
+			workspaceID := project.Workspace.Hex()
+			event.WorkspaceID = &workspaceID
+		}
+		r.publishUserEvent(event, userID)
+	}
+	r.revalidateProjectAccess(ctx, projectID)
+}
+
+// closeWorkspaceEvents ends the workspace event and library subscriptions of
+// the given user, or of everyone when userID is empty.
+func (r *Resolver) closeWorkspaceEvents(workspaceID string, userID string) {
+	r.subscribersMutex.Lock()
+	defer r.subscribersMutex.Unlock()
+
+	libraries := r.librarySubs[workspaceID][:0]
+	for _, subscriber := range r.librarySubs[workspaceID] {
+		if userID != "" && subscriber.userID != userID {
+			libraries = append(libraries, subscriber)
+			continue
+		}
+		close(subscriber.channel)
+	}
+	if len(libraries) == 0 {
+		delete(r.librarySubs, workspaceID)
+	} else {
+		r.librarySubs[workspaceID] = libraries
+	}
+
+	remaining := r.workspaceEventSubs[workspaceID][:0]
+	for _, subscriber := range r.workspaceEventSubs[workspaceID] {
+		if userID != "" && subscriber.userID != userID {
+			remaining = append(remaining, subscriber)
+			continue
+		}
+		close(subscriber.channel)
+	}
+	if len(remaining) == 0 {
+		delete(r.workspaceEventSubs, workspaceID)
+	} else {
+		r.workspaceEventSubs[workspaceID] = remaining
+	}
+}
+
+// revalidateProjectAccess terminates the project subscriptions of users who
+// can no longer access the project. Guests are covered by their share link:
+// see terminateShareLinkSubscriptions and sweepShareLinkSubscriptions.
+func (r *Resolver) revalidateProjectAccess(ctx context.Context, projectID string) {
+	r.subscribersMutex.RLock()
+	var userIDs []string
+	addUser := func(userID string) {
+		if userID != "" && !strings.HasPrefix(userID, auth.GuestSubPrefix) && !slices.Contains(userIDs, userID) {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	for _, subscriber := range r.projectSubscribers[projectID] {
+		addUser(subscriber.userID)
+	}
+	for _, subscriber := range r.opsSubscribers[projectID] {
+		addUser(subscriber.userID)
+	}
+	for _, subscriber := range r.cursorSubscribers[projectID] {
+		addUser(subscriber.userID)
+	}
+	for _, subscriber := range r.presenceSubscribers[projectID] {
+		addUser(subscriber.userID)
+	}
+	for _, subscriber := range r.commentSubscribers[projectID] {
+		addUser(subscriber.userID)
+	}
+	r.subscribersMutex.RUnlock()
+
+	for _, userID := range userIDs {
+		project, err := r.Repo.Project.GetProjectByID(ctx, projectID, userID)
+		if err != nil {
+			fmt.Printf("Warning: failed to recheck access of %s to project %s: %v\n", userID, projectID, err)
+			continue
+		}
+		if project == nil {
+			r.terminateUserProjectSubscriptions(projectID, userID, "access revoked")
+		}
+	}
+}
+
+// hasProjectSubscribers reports whether anyone is subscribed to the project.
+func (r *Resolver) hasProjectSubscribers(projectID string) bool {
+	r.subscribersMutex.RLock()
+	defer r.subscribersMutex.RUnlock()
+	return len(r.projectSubscribers[projectID]) > 0 ||
+		len(r.opsSubscribers[projectID]) > 0 ||
+		len(r.cursorSubscribers[projectID]) > 0 ||
+		len(r.presenceSubscribers[projectID]) > 0 ||
+		len(r.commentSubscribers[projectID]) > 0
+}
+
+// revalidateWorkspaceAccess runs revalidateProjectAccess on every project of
+// the workspace that has live subscribers.
+func (r *Resolver) revalidateWorkspaceAccess(ctx context.Context, workspace *models.Workspace) {
+	projectIDs, err := r.Repo.Workspace.GetProjectIDs(ctx, workspace.ID)
+	if err != nil {
+		fmt.Printf("Warning: failed to fetch projects of workspace %s: %v\n", workspace.ID.Hex(), err)
+		return
+	}
+	for _, id := range projectIDs {
+		if r.hasProjectSubscribers(id.Hex()) {
+			r.revalidateProjectAccess(ctx, id.Hex())
+		}
+	}
+}
+
+// terminateUserProjectSubscriptions ends every subscription a user holds on a
+// project, and drops them from presence.
+func (r *Resolver) terminateUserProjectSubscriptions(projectID string, userID string, reason string) {
+	r.terminateHolderSubscriptions(projectID, func(holder subscriptionHolder) bool {
+		return holder.userID == userID
+	}, reason)
+}
+
+// terminateShareLinkSubscriptions ends every subscription that guests of a
+// share link hold on its project, and drops them from presence.
+func (r *Resolver) terminateShareLinkSubscriptions(projectID string, linkID string, reason string) {
+	r.terminateHolderSubscriptions(projectID, func(holder subscriptionHolder) bool {
+		return holder.shareLinkID == linkID
+	}, reason)
+}
+
+// shareLinkSweepInterval is how often guest subscriptions are checked against
+// the expiry of their share link.
+const shareLinkSweepInterval = time.Minute
+
+// StartShareLinkSweeper periodically ends the subscriptions of guests whose
+// share link has expired, was revoked or was deleted.
+func (r *Resolver) StartShareLinkSweeper() {
+	go func() {
+		for {
+			time.Sleep(shareLinkSweepInterval)
+			r.sweepShareLinkSubscriptions(context.Background())
+		}
+	}()
+}
+
+// sweepShareLinkSubscriptions ends the subscriptions of guests whose share
+// link can no longer be used.
+func (r *Resolver) sweepShareLinkSubscriptions(ctx context.Context) {
+	links := map[string]string{} // share link ID -> project ID
+	r.subscribersMutex.RLock()
+	addLink := func(projectID string, holder subscriptionHolder) {
+		if holder.shareLinkID != "" {
+			links[holder.shareLinkID] = projectID
+		}
+	}
+	for projectID, subscribers := range r.projectSubscribers {
+		for _, subscriber := range subscribers {
+			addLink(projectID, subscriber.subscriptionHolder)
+		}
+	}
+	for projectID, subscribers := range r.opsSubscribers {
+		for _, subscriber := range subscribers {
+			addLink(projectID, subscriber.subscriptionHolder)
+		}
+	}
+	for projectID, subscribers := range r.cursorSubscribers {
+		for _, subscriber := range subscribers {
+			addLink(projectID, subscriber.subscriptionHolder)
+		}
+	}
+	for projectID, subscribers := range r.presenceSubscribers {
+		for _, subscriber := range subscribers {
+			addLink(projectID, subscriber.subscriptionHolder)
+		}
+	}
+	for projectID, subscribers := range r.commentSubscribers {
+		for _, subscriber := range subscribers {
+			addLink(projectID, subscriber.subscriptionHolder)
+		}
+	}
+	r.subscribersMutex.RUnlock()
+
+	now := time.Now()
+	for linkID, projectID := range links {
+		link, err := r.Repo.ShareLink.GetShareLinkByID(ctx, linkID)
+		if err != nil {
+			fmt.Printf("Warning: failed to recheck share link %s: %v\n", linkID, err)
+			continue
+		}
+		switch {
+		case link == nil:
+			r.terminateShareLinkSubscriptions(projectID, linkID, "share link deleted")
+		case link.RevokedAt != "":
+			r.terminateShareLinkSubscriptions(projectID, linkID, "share link revoked")
+		case !link.Usable(now):
+			r.terminateShareLinkSubscriptions(projectID, linkID, "share link expired")
+		}
+	}
+}
+
+// terminateHolderSubscriptions ends the project subscriptions whose holder
+// matches and drops those holders from presence. Like
+// terminateProjectSubscriptions, project and ops subscribers get a last
+// message carrying the reason.
+func (r *Resolver) terminateHolderSubscriptions(projectID string, match func(subscriptionHolder) bool, reason string) {
+	var removed []string
+	r.subscribersMutex.Lock()
+	projects := r.projectSubscribers[projectID][:0]
+	for _, subscriber := range r.projectSubscribers[projectID] {
+		if !match(subscriber.subscriptionHolder) {
+			projects = append(projects, subscriber)
+			continue
+		}
+		select {
+		case subscriber.channel <- &model.ProjectSubscription{SocketID: subscriber.sockedID, ClosedReason: &reason}:
+		default:
+		}
+		close(subscriber.channel)
+		if !slices.Contains(removed, subscriber.userID) {
+			removed = append(removed, subscriber.userID)
+		}
+	}
+	if len(projects) == 0 {
+		delete(r.projectSubscribers, projectID)
+	} else {
+		r.projectSubscribers[projectID] = projects
+	}
+
+	ops := r.opsSubscribers[projectID][:0]
+	for _, subscriber := range r.opsSubscribers[projectID] {
+		if !match(subscriber.subscriptionHolder) {
+			ops = append(ops, subscriber)
+			continue
+		}
+		select {
+		case subscriber.channel <- &model.ProjectOpsSubscription{Ops: []*model.Operation{}, SocketID: subscriber.sockedID, ClosedReason: &reason}:
+		default:
+		}
+		close(subscriber.channel)
+		if !slices.Contains(removed, subscriber.userID) {
+			removed = append(removed, subscriber.userID)
+		}
+	}
+	if len(ops) == 0 {
+		delete(r.opsSubscribers, projectID)
+	} else {
+		r.opsSubscribers[projectID] = ops
+	}
+
+	cursors := r.cursorSubscribers[projectID][:0]
+	for _, subscriber := range r.cursorSubscribers[projectID] {
+		if !match(subscriber.subscriptionHolder) {
+			cursors = append(cursors, subscriber)
+			continue
+		}
+		close(subscriber.channel)
+		if !slices.Contains(removed, subscriber.userID) {
+			removed = append(removed, subscriber.userID)
+		}
+	}
+	if len(cursors) == 0 {
+		delete(r.cursorSubscribers, projectID)
+	} else {
+		r.cursorSubscribers[projectID] = cursors
+	}
+
+	presence := r.presenceSubscribers[projectID][:0]
+	for _, subscriber := range r.presenceSubscribers[projectID] {
+		if !match(subscriber.subscriptionHolder) {
+			presence = append(presence, subscriber)
+			continue
+		}
+		close(subscriber.channel)
+		if !slices.Contains(removed, subscriber.userID) {
+			removed = append(removed, subscriber.userID)
+		}
+	}
+	if len(presence) == 0 {
+		delete(r.presenceSubscribers, projectID)
+	} else {
+		r.presenceSubscribers[projectID] = presence
+	}
+
+	comments := r.commentSubscribers[projectID][:0]
+	for _, subscriber := range r.commentSubscribers[projectID] {
+		if !match(subscriber.subscriptionHolder) {
+			comments = append(comments, subscriber)
+			continue
+		}
+		close(subscriber.channel)
+		if !slices.Contains(removed, subscriber.userID) {
+			removed = append(removed, subscriber.userID)
+		}
+	}
+	if len(comments) == 0 {
+		delete(r.commentSubscribers, projectID)
+	} else {
+		r.commentSubscribers[projectID] = comments
+	}
+	r.subscribersMutex.Unlock()
+
+	for _, userID := range removed {
+		r.removePresence(projectID, userID)
+	}
+	r.broadcastPresence(projectID)
+}
@@ -0,0 +1,95 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chirag3003/collab-draw-backend/graph/model"
+	"github.com/chirag3003/collab-draw-backend/internal/auth"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"github.com/chirag3003/collab-draw-backend/internal/search"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Search is the resolver for the search field.
+func (r *queryResolver) Search(ctx context.Context, query string, workspaceID *string, limit *int32) ([]*model.SearchHit, error) {
+	authContext := auth.ForContext(ctx)
+	if authContext == nil || auth.IsGuest(ctx) {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("search query cannot be empty")
+	}
+	size := 20
+	if limit != nil && *limit > 0 && *limit < 100 {
+		size = int(*limit)
+	}
+	workspace := ""
+	if workspaceID != nil {
+		if !workspaceInScope(ctx, *workspaceID) {
+			return nil, fmt.Errorf("workspace not found")
+		}
+		workspace = *workspaceID
+	}
+
+	projects, err := r.Repo.Project.GetAccessibleProjects(ctx, authContext.Sub, workspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch projects: %v", err)
+	}
+	byID := make(map[bson.ObjectID]*models.Project, len(projects))
+	projectIDs := make([]bson.ObjectID, 0, len(projects))
+	for _, p := range projects {
+		if !projectInScope(ctx, p) {
+			continue
+		}
+		byID[p.ID] = p
+		projectIDs = append(projectIDs, p.ID)
+	}
+
+	hits, err := r.Repo.Search.Search(ctx, query, projectIDs, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %v", err)
+	}
+	result := make([]*model.SearchHit, 0, len(hits))
+	for _, hit := range hits {
+		project := byID[hit.ProjectID]
+		if project == nil {
+			continue
+		}
+		result = append(result, convertSearchHitToModel(hit, project, query))
+	}
+	return result, nil
+}
+
+func convertSearchHitToModel(hit *models.SearchHit, project *models.Project, query string) *model.SearchHit {
+	result := &model.SearchHit{
+		Kind:        model.SearchHitKind(hit.Kind),
+		ProjectID:   project.ID.Hex(),
+		ProjectName: project.Name,
+		Snippet:     search.Snippet(hit.Text, query),
+		Score:       hit.Score,
+	}
+	if project.Workspace != nil {
+		hex := project.Workspace.Hex()
+		result.WorkspaceID = &hex
+	}
+	if hit.ElementID != "" {
+		result.ElementID = &hit.ElementID
+	}
+	if hit.ThreadID != "" {
+		result.ThreadID = &hit.ThreadID
+	}
+	if hit.CommentID != "" {
+		result.CommentID = &hit.CommentID
+	}
+	if hit.Kind == models.SearchKindProject && result.Snippet == "" {
+		result.Snippet = project.Name
+	}
+	return result
+}
@@ -0,0 +1,143 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/graph/model"
+	"github.com/chirag3003/collab-draw-backend/internal/auth"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// CreateShareLink is the resolver for the createShareLink field.
+func (r *mutationResolver) CreateShareLink(ctx context.Context, input model.NewShareLink) (*model.CreateShareLinkResult, error) {
+	authContext := auth.ForContext(ctx)
+	if auth.IsGuest(ctx) {
+		return nil, fmt.Errorf("guests cannot share projects")
+	}
+	// Links hand out access, so only those who may edit the project can share it
+	project, err := r.getEditableProject(ctx, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, hash, err := auth.GenerateShareToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate share link: %v", err)
+	}
+
+	link := &models.ShareLink{
+		ProjectID:  project.ID,
+		TokenHash:  hash,
+		Permission: string(input.Permission),
+		CreatedBy:  authContext.Sub,
+	}
+	if input.Password != nil && *input.Password != "" {
+		link.PasswordHash, err = auth.HashSharePassword(*input.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash password: %v", err)
+		}
+	}
+	if input.ExpiresInHours != nil {
+		if *input.ExpiresInHours <= 0 {
+			return nil, fmt.Errorf("expiresInHours must be positive")
+		}
+		link.ExpiresAt = time.Now().Add(time.Duration(*input.ExpiresInHours) * time.Hour).Format(time.RFC3339)
+	}
+	if input.MaxUses != nil {
+		if *input.MaxUses <= 0 {
+			return nil, fmt.Errorf("maxUses must be positive")
+		}
+		link.MaxUses = int(*input.MaxUses)
+	}
+
+	err = r.Repo.ShareLink.CreateShareLink(ctx, link)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create share link: %v", err)
+	}
+	r.recordAudit(ctx, "project.create_share_link", "project", project.ID.Hex(), project.Workspace,
+		nil, bson.M{"link": link.ID.Hex(), "permission": link.Permission})
+
+	return &model.CreateShareLinkResult{
+		Token:     token,
+		ShareLink: convertShareLinkToModel(link),
+	}, nil
+}
+
+// RevokeShareLink is the resolver for the revokeShareLink field.
+func (r *mutationResolver) RevokeShareLink(ctx context.Context, id string) (bool, error) {
+	authContext := auth.ForContext(ctx)
+	if auth.IsGuest(ctx) {
+		return false, fmt.Errorf("guests cannot manage share links")
+	}
+	link, err := r.Repo.ShareLink.GetShareLinkByID(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch share link: %v", err)
+	}
+	if link == nil {
+		return false, fmt.Errorf("share link not found")
+	}
+	project, err := r.getEditableProject(ctx, link.ProjectID.Hex())
+	if err != nil {
+		return false, err
+	}
+	if project.Owner != authContext.Sub && link.CreatedBy != authContext.Sub {
+		return false, fmt.Errorf("only the project owner or link creator can revoke a share link")
+	}
+
+	revoked, err := r.Repo.ShareLink.RevokeShareLink(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke share link: %v", err)
+	}
+	if revoked {
+		r.recordAudit(ctx, "project.revoke_share_link", "project", project.ID.Hex(), project.Workspace,
+			bson.M{"link": id}, nil)
+		r.terminateShareLinkSubscriptions(project.ID.Hex(), link.ID.Hex(), "share link revoked")
+	}
+	return revoked, nil
+}
+
+// ShareLinks is the resolver for the shareLinks field.
+func (r *queryResolver) ShareLinks(ctx context.Context, projectID string) ([]*model.ShareLink, error) {
+	if auth.IsGuest(ctx) {
+		return nil, fmt.Errorf("guests cannot list share links")
+	}
+	if err := r.checkProjectAccess(ctx, projectID); err != nil {
+		return nil, err
+	}
+	links, err := r.Repo.ShareLink.GetShareLinksByProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch share links: %v", err)
+	}
+	result := []*model.ShareLink{}
+	for _, l := range links {
+		result = append(result, convertShareLinkToModel(l))
+	}
+	return result, nil
+}
+
+func convertShareLinkToModel(l *models.ShareLink) *model.ShareLink {
+	link := &model.ShareLink{
+		ID:          l.ID.Hex(),
+		ProjectID:   l.ProjectID.Hex(),
+		Permission:  model.SharePermission(l.Permission),
+		HasPassword: l.PasswordHash != "",
+		Uses:        int32(l.Uses),
+		CreatedBy:   l.CreatedBy,
+		CreatedAt:   l.CreatedAt,
+	}
+	if l.ExpiresAt != "" {
+		link.ExpiresAt = &l.ExpiresAt
+	}
+	if l.MaxUses > 0 {
+		maxUses := int32(l.MaxUses)
+		link.MaxUses = &maxUses
+	}
+	return link
+}
@@ -0,0 +1,233 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/graph/model"
+	"github.com/chirag3003/collab-draw-backend/internal/auth"
+	"github.com/chirag3003/collab-draw-backend/internal/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ProjectStats is the resolver for the projectStats field.
+func (r *queryResolver) ProjectStats(ctx context.Context, projectID string, days *int32) (*model.ProjectStats, error) {
+	if auth.ForContext(ctx) == nil {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	project, err := r.getAccessibleProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch project: %v", err)
+	}
+	if project == nil {
+		return nil, fmt.Errorf("project not found")
+	}
+
+	activity, err := r.Repo.Operation.GetActivity(ctx, []bson.ObjectID{project.ID}, activitySince(days))
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate activity: %v", err)
+	}
+	lastEdit, err := r.Repo.Operation.GetLastEdit(ctx, project.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch last edit: %v", err)
+	}
+
+	userIDs := make([]string, 0, len(activity.ByUser)+1)
+	for _, u := range activity.ByUser {
+		userIDs = append(userIDs, u.UserID)
+	}
+	if lastEdit != nil {
+		userIDs = append(userIDs, lastEdit.UserID)
+	}
+	names := r.userNames(ctx, userIDs)
+
+	counts, total := countElementsByType(project.Elements)
+	result := &model.ProjectStats{
+		ProjectID:      project.ID.Hex(),
+		ElementCount:   int32(total),
+		ElementsByType: counts,
+		TotalOps:       int32(activity.TotalOps),
+		OpsByUser:      convertUserActivityToModel(activity.ByUser, names),
+		DailyActivity:  convertDailyActivityToModel(activity.Daily),
+	}
+	if lastEdit != nil {
+		result.LastEditedAt = &lastEdit.Timestamp
+		result.LastEditedBy = &model.UserActivity{UserID: lastEdit.UserID, LastOpAt: lastEdit.Timestamp}
+		for _, u := range result.OpsByUser {
+			if u.UserID == lastEdit.UserID {
+				result.LastEditedBy.Ops = u.Ops
+			}
+		}
+		if name, ok := names[lastEdit.UserID]; ok {
+			result.LastEditedBy.FullName = &name
+		}
+	}
+	return result, nil
+}
+
+// WorkspaceActivity is the resolver for the workspaceActivity field.
+func (r *queryResolver) WorkspaceActivity(ctx context.Context, workspaceID string, days *int32) (*model.WorkspaceActivity, error) {
+	workspace, err := r.getMemberWorkspace(ctx, workspaceID, false)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := r.Repo.Project.GetAccessibleProjects(ctx, auth.ForContext(ctx).Sub, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch projects: %v", err)
+	}
+	names := make(map[bson.ObjectID]string, len(projects))
+	projectIDs := make([]bson.ObjectID, 0, len(projects))
+	for _, p := range projects {
+		if !projectInScope(ctx, p) {
+			continue
+		}
+		names[p.ID] = p.Name
+		projectIDs = append(projectIDs, p.ID)
+	}
+
+	activity, err := r.Repo.Operation.GetActivity(ctx, projectIDs, activitySince(days))
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate activity: %v", err)
+	}
+	userIDs := make([]string, 0, len(activity.ByUser))
+	for _, u := range activity.ByUser {
+		userIDs = append(userIDs, u.UserID)
+	}
+
+	result := &model.WorkspaceActivity{
+		WorkspaceID:    workspace.ID.Hex(),
+		ProjectCount:   int32(len(projectIDs)),
+		ActiveProjects: int32(len(activity.ByProject)),
+		TotalOps:       int32(activity.TotalOps),
+		ActiveEditors:  int32(activity.Editors),
+		OpsByUser:      convertUserActivityToModel(activity.ByUser, r.userNames(ctx, userIDs)),
+		DailyActivity:  convertDailyActivityToModel(activity.Daily),
+		Projects:       make([]*model.ProjectActivity, 0, len(activity.ByProject)),
+	}
+	for _, p := range activity.ByProject {
+		lastEditedAt := p.LastOpAt.Format(time.RFC3339)
+		entry := &model.ProjectActivity{
+			ProjectID:     p.ProjectID.Hex(),
+			ProjectName:   names[p.ProjectID],
+			Ops:           int32(p.Ops),
+			ActiveEditors: int32(p.Editors),
+			LastEditedAt:  &lastEditedAt,
+		}
+		if p.LastUserID != "" {
+			entry.LastEditedBy = &p.LastUserID
+		}
+		result.Projects = append(result.Projects, entry)
+	}
+	return result, nil
+}
+
+const (
+	defaultActivityDays = 30
+	maxActivityDays     = 365
+)
+
+// activitySince returns the start of the activity window, counting today as
+// the first of the requested days.
+func activitySince(days *int32) time.Time {
+	n := defaultActivityDays
+	if days != nil && *days > 0 {
+		n = min(int(*days), maxActivityDays)
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	return today.AddDate(0, 0, 1-n)
+}
+
+// countElementsByType counts the live elements of a scene by type, most common
+// first. Deletions are only visible in the element data, so the counts come
+// from the current scene rather than the op log.
+func countElementsByType(elementsJSON string) ([]*model.ElementTypeCount, int) {
+	result := []*model.ElementTypeCount{}
+	if elementsJSON == "" {
+		return result, 0
+	}
+	var elements []struct {
+		Type      string `json:"type"`
+		IsDeleted bool   `json:"isDeleted"`
+	}
+	if err := json.Unmarshal([]byte(elementsJSON), &elements); err != nil {
+		return result, 0
+	}
+	counts := map[string]int{}
+	total := 0
+	for _, el := range elements {
+		if el.IsDeleted {
+			continue
+		}
+		counts[el.Type]++
+		total++
+	}
+	for t, c := range counts {
+		result = append(result, &model.ElementTypeCount{Type: t, Count: int32(c)})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Type < result[j].Type
+	})
+	return result, total
+}
+
+// userNames looks up the full names of the given users. Names are left out
+// when the lookup fails so activity can still be shown.
+func (r *Resolver) userNames(ctx context.Context, ids []string) map[string]string {
+	names := map[string]string{}
+	var known []string
+	for _, id := range ids {
+		if id != "" {
+			known = append(known, id)
+		}
+	}
+	if len(known) == 0 {
+		return names
+	}
+	users, err := r.Repo.User.GetUsersByID(ctx, known)
+	if err != nil {
+		fmt.Printf("Warning: failed to fetch user names: %v\n", err)
+		return names
+	}
+	for _, user := range users {
+		names[user.ID] = user.FirstName + " " + user.LastName
+	}
+	return names
+}
+
+func convertUserActivityToModel(users []repository.UserActivity, names map[string]string) []*model.UserActivity {
+	result := make([]*model.UserActivity, 0, len(users))
+	for _, u := range users {
+		entry := &model.UserActivity{
+			UserID:   u.UserID,
+			Ops:      int32(u.Ops),
+			LastOpAt: u.LastOpAt.Format(time.RFC3339),
+		}
+		if name, ok := names[u.UserID]; ok {
+			entry.FullName = &name
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+func convertDailyActivityToModel(days []repository.DailyActivity) []*model.DailyActivity {
+	result := make([]*model.DailyActivity, 0, len(days))
+	for _, d := range days {
+		result = append(result, &model.DailyActivity{
+			Date:          d.Date,
+			Ops:           int32(d.Ops),
+			ActiveEditors: int32(d.Editors),
+		})
+	}
+	return result
+}
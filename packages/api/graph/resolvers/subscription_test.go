@@ -0,0 +1,165 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/graph/model"
+	"github.com/chirag3003/collab-draw-backend/internal/cleanup"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"github.com/chirag3003/collab-draw-backend/internal/repository"
+)
+
+func newTestResolver() *Resolver {
+	return NewResolver(&repository.Repository{}, cleanup.NewService(nil), nil, nil, nil, nil, nil)
+}
+
+// Subscriptions that are set up while the project is being terminated must
+// either be closed by the termination or survive it intact, and never make a
+// send on a closed channel.
+func TestSubscribeDuringTermination(t *testing.T) {
+	const projectID = "p1"
+	const rounds = 200
+	r := newTestResolver()
+
+	var wg sync.WaitGroup
+	for i := range rounds {
+		userID := fmt.Sprintf("user-%d", i%4)
+
+		wg.Add(5)
+		go func() {
+			defer wg.Done()
+			ch := make(chan *model.ProjectSubscription, 64)
+			socketID := r.subscribeToProject(projectID, subscriptionHolder{userID: userID}, "[]", ch)
+			if first := <-ch; first == nil || first.SocketID != socketID {
+				t.Errorf("first project message = %+v, want socket %s", first, socketID)
+			}
+			r.unsubscribeFromProject(projectID, socketID)
+		}()
+		go func() {
+			defer wg.Done()
+			ch := make(chan *model.ProjectOpsSubscription, 64)
+			socketID := r.subscribeToProjectOps(projectID, subscriptionHolder{userID: userID}, PresenceInfo{UserName: userID}, ch)
+			if first := <-ch; first == nil || first.SocketID != socketID {
+				t.Errorf("first ops message = %+v, want socket %s", first, socketID)
+			}
+			r.unsubscribeFromProjectOps(projectID, socketID)
+			r.removePresence(projectID, userID)
+		}()
+		go func() {
+			defer wg.Done()
+			ch := make(chan []*model.UserPresence, 16)
+			socketID := r.subscribeToPresence(projectID, subscriptionHolder{userID: userID}, ch)
+			<-ch
+			r.unsubscribeFromPresence(projectID, socketID)
+		}()
+		go func() {
+			defer wg.Done()
+			r.terminateProjectSubscriptions(projectID, "project deleted")
+		}()
+		go func() {
+			defer wg.Done()
+			r.terminateUserProjectSubscriptions(projectID, userID, "access revoked")
+		}()
+	}
+	wg.Wait()
+
+	r.terminateProjectSubscriptions(projectID, "project deleted")
+	if r.hasProjectSubscribers(projectID) || len(r.getPresenceList(projectID)) > 0 {
+		t.Error("subscribers or presence left after termination")
+	}
+}
+
+// A user whose ops subscription was terminated must not stay present.
+func TestTerminateUserRemovesPresence(t *testing.T) {
+	const projectID = "p1"
+	r := newTestResolver()
+
+	ops := make(chan *model.ProjectOpsSubscription, 64)
+	r.subscribeToProjectOps(projectID, subscriptionHolder{userID: "alice"}, PresenceInfo{UserName: "alice"}, ops)
+	r.subscribeToProjectOps(projectID, subscriptionHolder{userID: "bob"}, PresenceInfo{UserName: "bob"}, make(chan *model.ProjectOpsSubscription, 64))
+	project := make(chan *model.ProjectSubscription, 64)
+	r.subscribeToProject(projectID, subscriptionHolder{userID: "alice"}, "[]", project)
+	comments := make(chan *model.CommentEvent, 32)
+	r.subscribeToComments(projectID, subscriptionHolder{userID: "alice"}, comments)
+
+	r.terminateUserProjectSubscriptions(projectID, "alice", "access revoked")
+
+	for range ops {
+	}
+	for range project {
+	}
+	if _, open := <-comments; open {
+		t.Error("comment subscription of alice is still open")
+	}
+	presence := r.getPresenceList(projectID)
+	if len(presence) != 1 || presence[0].UserID != "bob" {
+		t.Errorf("presence = %v, want only bob", presence)
+	}
+}
+
+type fakeShareLinks struct {
+	repository.ShareLinkRepository
+	links map[string]*models.ShareLink
+}
+
+func (f fakeShareLinks) GetShareLinkByID(ctx context.Context, id string) (*models.ShareLink, error) {
+	return f.links[id], nil
+}
+
+// Guests lose their subscriptions when their share link is revoked, expires
+// or is deleted, while guests of other links and members keep theirs.
+func TestShareLinkSubscriptionsEnd(t *testing.T) {
+	const projectID = "p1"
+	r := newTestResolver()
+	r.Repo.ShareLink = fakeShareLinks{links: map[string]*models.ShareLink{
+		"active":  {ExpiresAt: time.Now().Add(time.Hour).Format(time.RFC3339)},
+		"expired": {ExpiresAt: time.Now().Add(-time.Minute).Format(time.RFC3339)},
+		"revoked": {RevokedAt: time.Now().Format(time.RFC3339)},
+	}}
+
+	subscribe := func(holder subscriptionHolder) (chan *model.ProjectOpsSubscription, chan *model.CommentEvent) {
+		ops := make(chan *model.ProjectOpsSubscription, 64)
+		r.subscribeToProjectOps(projectID, holder, PresenceInfo{UserName: holder.userID}, ops)
+		comments := make(chan *model.CommentEvent, 32)
+		r.subscribeToComments(projectID, holder, comments)
+		<-ops
+		return ops, comments
+	}
+	member, _ := subscribe(subscriptionHolder{userID: "alice"})
+	active, _ := subscribe(subscriptionHolder{userID: "guest:a", shareLinkID: "active"})
+	expired, expiredComments := subscribe(subscriptionHolder{userID: "guest:b", shareLinkID: "expired"})
+	revoked, _ := subscribe(subscriptionHolder{userID: "guest:c", shareLinkID: "revoked"})
+	deleted, _ := subscribe(subscriptionHolder{userID: "guest:d", shareLinkID: "deleted"})
+	manual, _ := subscribe(subscriptionHolder{userID: "guest:e", shareLinkID: "manual"})
+
+	r.terminateShareLinkSubscriptions(projectID, "manual", "share link revoked")
+	r.sweepShareLinkSubscriptions(context.Background())
+
+	for name, ch := range map[string]chan *model.ProjectOpsSubscription{"expired": expired, "revoked": revoked, "deleted": deleted, "manual": manual} {
+		var last *model.ProjectOpsSubscription
+		for msg := range ch {
+			last = msg
+		}
+		if last == nil || last.ClosedReason == nil {
+			t.Errorf("%s link: ops subscription ended without a reason", name)
+		}
+	}
+	if _, open := <-expiredComments; open {
+		t.Error("expired link: comment subscription is still open")
+	}
+	for name, ch := range map[string]chan *model.ProjectOpsSubscription{"member": member, "active": active} {
+		select {
+		case msg, open := <-ch:
+			t.Errorf("%s: subscription got %+v (open %v), want it untouched", name, msg, open)
+		default:
+		}
+	}
+	presence := r.getPresenceList(projectID)
+	if len(presence) != 2 {
+		t.Errorf("presence has %d users, want alice and the active link's guest", len(presence))
+	}
+}
@@ -0,0 +1,50 @@
+package resolvers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/chirag3003/collab-draw-backend/internal/filestore"
+	"github.com/go-chi/chi"
+)
+
+// thumbnailPath is where a thumbnail is served. The hash is unguessable, so
+// the path works in <img> tags without credentials, and it changes whenever
+// the thumbnail does, so it can be cached for good.
+func thumbnailPath(hash string) string {
+	return fmt.Sprintf("/thumbnails/%s.png", hash)
+}
+
+// ServeThumbnail serves a project thumbnail. Only thumbnails of projects that
+// are not trashed are served, which keeps other blobs out of reach.
+func (r *Resolver) ServeThumbnail(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	hash := strings.TrimSuffix(chi.URLParam(req, "file"), ".png")
+	used, err := r.Repo.Project.HasThumbnail(ctx, hash)
+	if err != nil {
+		http.Error(w, "failed to fetch thumbnail", http.StatusInternalServerError)
+		return
+	}
+	if !used {
+		http.Error(w, "thumbnail not found", http.StatusNotFound)
+		return
+	}
+	contents, err := r.Files.OpenBlob(ctx, hash)
+	if err != nil {
+		if errors.Is(err, filestore.ErrNotFound) {
+			http.Error(w, "thumbnail not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to read thumbnail", http.StatusInternalServerError)
+		return
+	}
+	defer contents.Close()
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_, _ = io.Copy(w, contents)
+}
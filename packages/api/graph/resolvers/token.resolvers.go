@@ -0,0 +1,132 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/graph/model"
+	"github.com/chirag3003/collab-draw-backend/internal/auth"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+)
+
+// CreateAccessToken is the resolver for the createAccessToken field.
+func (r *mutationResolver) CreateAccessToken(ctx context.Context, input model.NewAccessToken) (*model.CreateAccessTokenResult, error) {
+	authContext := auth.ForContext(ctx)
+	if auth.ScopeForContext(ctx) != nil {
+		return nil, fmt.Errorf("access tokens cannot be managed with an access token")
+	}
+	if strings.TrimSpace(input.Name) == "" {
+		return nil, fmt.Errorf("token name cannot be empty")
+	}
+
+	token, hash, err := auth.GenerateAccessToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %v", err)
+	}
+
+	accessToken := &models.AccessToken{
+		Name:              input.Name,
+		Prefix:            token[:len(auth.AccessTokenPrefix)+6],
+		TokenHash:         hash,
+		UserID:            authContext.Sub,
+		Email:             authContext.Email,
+		FullName:          authContext.Name,
+		PreferredUsername: authContext.PreferredUsername,
+		ReadOnly:          input.ReadOnly,
+	}
+
+	if input.ProjectID != nil {
+		project, err := r.Repo.Project.GetProjectByID(ctx, *input.ProjectID, authContext.Sub)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch project: %v", err)
+		}
+		if project == nil {
+			return nil, fmt.Errorf("project not found")
+		}
+		accessToken.ProjectID = &project.ID
+	}
+	if input.WorkspaceID != nil {
+		workspace, err := r.Repo.Workspace.GetWorkspaceByID(ctx, *input.WorkspaceID, authContext.Sub)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch workspace: %v", err)
+		}
+		if workspace == nil {
+			return nil, fmt.Errorf("workspace not found")
+		}
+		accessToken.WorkspaceID = &workspace.ID
+	}
+	if input.ExpiresInDays != nil {
+		if *input.ExpiresInDays <= 0 {
+			return nil, fmt.Errorf("expiresInDays must be positive")
+		}
+		accessToken.ExpiresAt = time.Now().AddDate(0, 0, int(*input.ExpiresInDays)).Format(time.RFC3339)
+	}
+
+	err = r.Repo.AccessToken.CreateToken(ctx, accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create access token: %v", err)
+	}
+
+	return &model.CreateAccessTokenResult{
+		Token:       token,
+		AccessToken: convertAccessTokenToModel(accessToken),
+	}, nil
+}
+
+// RevokeAccessToken is the resolver for the revokeAccessToken field.
+func (r *mutationResolver) RevokeAccessToken(ctx context.Context, id string) (bool, error) {
+	authContext := auth.ForContext(ctx)
+	if auth.ScopeForContext(ctx) != nil {
+		return false, fmt.Errorf("access tokens cannot be managed with an access token")
+	}
+	revoked, err := r.Repo.AccessToken.RevokeToken(ctx, id, authContext.Sub)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke access token: %v", err)
+	}
+	return revoked, nil
+}
+
+// AccessTokens is the resolver for the accessTokens field.
+func (r *queryResolver) AccessTokens(ctx context.Context) ([]*model.AccessToken, error) {
+	authContext := auth.ForContext(ctx)
+	tokens, err := r.Repo.AccessToken.GetTokensByUser(ctx, authContext.Sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch access tokens: %v", err)
+	}
+	result := []*model.AccessToken{}
+	for _, t := range tokens {
+		result = append(result, convertAccessTokenToModel(t))
+	}
+	return result, nil
+}
+
+func convertAccessTokenToModel(t *models.AccessToken) *model.AccessToken {
+	token := &model.AccessToken{
+		ID:        t.ID.Hex(),
+		Name:      t.Name,
+		Prefix:    t.Prefix,
+		ReadOnly:  t.ReadOnly,
+		CreatedAt: t.CreatedAt,
+	}
+	if t.ProjectID != nil {
+		hex := t.ProjectID.Hex()
+		token.ProjectID = &hex
+	}
+	if t.WorkspaceID != nil {
+		hex := t.WorkspaceID.Hex()
+		token.WorkspaceID = &hex
+	}
+	if t.ExpiresAt != "" {
+		token.ExpiresAt = &t.ExpiresAt
+	}
+	if t.LastUsedAt != "" {
+		token.LastUsedAt = &t.LastUsedAt
+	}
+	return token
+}
@@ -0,0 +1,57 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/graph/model"
+	"github.com/chirag3003/collab-draw-backend/internal/auth"
+	"github.com/chirag3003/collab-draw-backend/internal/cleanup"
+)
+
+// Trash is the resolver for the trash field.
+func (r *queryResolver) Trash(ctx context.Context) (*model.Trash, error) {
+	authContext := auth.ForContext(ctx)
+	projects, err := r.Repo.Project.GetTrashedProjects(ctx, authContext.Sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trashed projects: %v", err)
+	}
+	workspaces, err := r.Repo.Workspace.GetTrashedWorkspaces(ctx, authContext.Sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trashed workspaces: %v", err)
+	}
+
+	trash := &model.Trash{
+		Projects:      []*model.Project{},
+		Workspaces:    []*model.Workspace{},
+		RetentionDays: int32(cleanup.TrashRetention() / (24 * time.Hour)),
+	}
+	for _, p := range projects {
+		if !projectInScope(ctx, p) {
+			continue
+		}
+		project := convertProjectToModel(p)
+		project.DeletedAt, project.DeletedBy = &p.DeletedAt, &p.DeletedBy
+		trash.Projects = append(trash.Projects, project)
+	}
+	for _, ws := range workspaces {
+		if !workspaceInScope(ctx, ws.ID.Hex()) {
+			continue
+		}
+		trash.Workspaces = append(trash.Workspaces, &model.Workspace{
+			ID:          ws.ID.Hex(),
+			Name:        ws.Name,
+			Description: ws.Description,
+			Owner:       ws.Owner,
+			CreatedAt:   ws.CreatedAt,
+			DeletedAt:   &ws.DeletedAt,
+			DeletedBy:   &ws.DeletedBy,
+		})
+	}
+	return trash, nil
+}
@@ -0,0 +1,203 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.81
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/graph/model"
+	"github.com/chirag3003/collab-draw-backend/internal/auth"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"github.com/chirag3003/collab-draw-backend/internal/webhook"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// CreateWebhook is the resolver for the createWebhook field.
+func (r *mutationResolver) CreateWebhook(ctx context.Context, input model.NewWebhook) (*model.CreateWebhookResult, error) {
+	authContext := auth.ForContext(ctx)
+	workspace, err := r.getOwnedWorkspace(ctx, input.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := webhook.ValidateURL(ctx, input.URL)
+	if err != nil {
+		return nil, err
+	}
+	if len(input.Events) == 0 {
+		return nil, fmt.Errorf("select at least one event")
+	}
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %v", err)
+	}
+
+	hook := &models.Webhook{
+		WorkspaceID: workspace.ID,
+		URL:         target.String(),
+		Events:      make([]string, 0, len(input.Events)),
+		Secret:      secret,
+		Active:      true,
+		CreatedBy:   authContext.Sub,
+	}
+	for _, event := range input.Events {
+		hook.Events = append(hook.Events, string(event))
+	}
+	err = r.Repo.Webhook.CreateWebhook(ctx, hook)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %v", err)
+	}
+	r.recordAudit(ctx, "workspace.create_webhook", "workspace", workspace.ID.Hex(), &workspace.ID,
+		nil, bson.M{"webhook": hook.ID.Hex(), "url": hook.URL, "events": hook.Events})
+
+	return &model.CreateWebhookResult{
+		Secret:  secret,
+		Webhook: convertWebhookToModel(hook),
+	}, nil
+}
+
+// SetWebhookActive is the resolver for the setWebhookActive field.
+func (r *mutationResolver) SetWebhookActive(ctx context.Context, id string, active bool) (bool, error) {
+	hook, err := r.getOwnedWebhook(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	err = r.Repo.Webhook.SetActive(ctx, hook.ID, active)
+	if err != nil {
+		return false, fmt.Errorf("failed to update webhook: %v", err)
+	}
+	return true, nil
+}
+
+// DeleteWebhook is the resolver for the deleteWebhook field.
+func (r *mutationResolver) DeleteWebhook(ctx context.Context, id string) (bool, error) {
+	hook, err := r.getOwnedWebhook(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	err = r.Repo.Webhook.DeleteWebhook(ctx, hook.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete webhook: %v", err)
+	}
+	r.recordAudit(ctx, "workspace.delete_webhook", "workspace", hook.WorkspaceID.Hex(), &hook.WorkspaceID,
+		bson.M{"webhook": hook.ID.Hex(), "url": hook.URL}, nil)
+	return true, nil
+}
+
+// Webhooks is the resolver for the webhooks field.
+func (r *queryResolver) Webhooks(ctx context.Context, workspaceID string) ([]*model.Webhook, error) {
+	workspace, err := r.getOwnedWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	hooks, err := r.Repo.Webhook.GetWebhooksByWorkspace(ctx, workspace.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhooks: %v", err)
+	}
+	result := make([]*model.Webhook, 0, len(hooks))
+	for _, hook := range hooks {
+		result = append(result, convertWebhookToModel(hook))
+	}
+	return result, nil
+}
+
+// WebhookDeliveries is the resolver for the webhookDeliveries field.
+func (r *queryResolver) WebhookDeliveries(ctx context.Context, webhookID string, cursor *string) (*model.WebhookDeliveryPage, error) {
+	hook, err := r.getOwnedWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	after := ""
+	if cursor != nil {
+		after = *cursor
+	}
+	deliveries, next, err := r.Repo.Webhook.GetDeliveries(ctx, hook.ID, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook deliveries: %v", err)
+	}
+	page := &model.WebhookDeliveryPage{Deliveries: make([]*model.WebhookDelivery, 0, len(deliveries))}
+	for _, d := range deliveries {
+		page.Deliveries = append(page.Deliveries, convertWebhookDeliveryToModel(d))
+	}
+	if next != "" {
+		page.NextCursor = &next
+	}
+	return page, nil
+}
+
+// getOwnedWorkspace fetches a workspace owned by the current user within the
+// scope of their access token.
+func (r *Resolver) getOwnedWorkspace(ctx context.Context, workspaceID string) (*models.Workspace, error) {
+	authContext := auth.ForContext(ctx)
+	if !workspaceInScope(ctx, workspaceID) {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	workspace, err := r.Repo.Workspace.GetWorkspaceByID(ctx, workspaceID, authContext.Sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workspace: %v", err)
+	}
+	if workspace == nil || workspace.Owner != authContext.Sub {
+		return nil, fmt.Errorf("workspace not found")
+	}
+	return workspace, nil
+}
+
+// getOwnedWebhook fetches a webhook of a workspace owned by the current user.
+func (r *Resolver) getOwnedWebhook(ctx context.Context, id string) (*models.Webhook, error) {
+	hook, err := r.Repo.Webhook.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook: %v", err)
+	}
+	if hook == nil {
+		return nil, fmt.Errorf("webhook not found")
+	}
+	if _, err := r.getOwnedWorkspace(ctx, hook.WorkspaceID.Hex()); err != nil {
+		return nil, fmt.Errorf("webhook not found")
+	}
+	return hook, nil
+}
+
+func convertWebhookToModel(hook *models.Webhook) *model.Webhook {
+	result := &model.Webhook{
+		ID:          hook.ID.Hex(),
+		WorkspaceID: hook.WorkspaceID.Hex(),
+		URL:         hook.URL,
+		Events:      make([]model.WebhookEvent, 0, len(hook.Events)),
+		Active:      hook.Active,
+		CreatedBy:   hook.CreatedBy,
+		CreatedAt:   hook.CreatedAt,
+	}
+	for _, event := range hook.Events {
+		result.Events = append(result.Events, model.WebhookEvent(event))
+	}
+	return result
+}
+
+func convertWebhookDeliveryToModel(d *models.WebhookDelivery) *model.WebhookDelivery {
+	result := &model.WebhookDelivery{
+		ID:        d.ID.Hex(),
+		Event:     model.WebhookEvent(d.Event),
+		Status:    model.WebhookDeliveryStatus(d.Status),
+		Attempts:  int32(d.Attempts),
+		Payload:   d.Payload,
+		CreatedAt: d.CreatedAt,
+	}
+	if d.ResponseCode != 0 {
+		code := int32(d.ResponseCode)
+		result.ResponseCode = &code
+	}
+	if d.LastError != "" {
+		result.Error = &d.LastError
+	}
+	if d.Status == models.WebhookDeliveryPending {
+		next := d.NextAttemptAt.Format(time.RFC3339)
+		result.NextAttemptAt = &next
+	}
+	if d.DeliveredAt != "" {
+		result.DeliveredAt = &d.DeliveredAt
+	}
+	return result
+}
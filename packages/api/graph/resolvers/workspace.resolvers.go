@@ -7,15 +7,20 @@ package resolvers
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/chirag3003/collab-draw-backend/graph/model"
 	"github.com/chirag3003/collab-draw-backend/internal/auth"
 	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 // CreateWorkspace is the resolver for the createWorkspace field.
 func (r *mutationResolver) CreateWorkspace(ctx context.Context, input model.NewWorkspace) (string, error) {
 	authContext := auth.ForContext(ctx)
+	if scopeRestricted(ctx) {
+		return "", fmt.Errorf("access token cannot create workspaces")
+	}
 	workspace := models.Workspace{
 		Name:        input.Name,
 		Description: input.Description,
@@ -26,22 +31,46 @@ func (r *mutationResolver) CreateWorkspace(ctx context.Context, input model.NewW
 	if err != nil {
 		return "", fmt.Errorf("failed to create workspace: %v", err)
 	}
+	r.recordAudit(ctx, "workspace.create", "workspace", workspace.ID.Hex(), &workspace.ID,
+		nil, bson.M{"name": workspace.Name})
 	return "", nil
 }
 
 // DeleteWorkspace is the resolver for the deleteWorkspace field.
 func (r *mutationResolver) DeleteWorkspace(ctx context.Context, id string) (bool, error) {
 	authContext := auth.ForContext(ctx)
-	err := r.Repo.Workspace.DeleteWorkspace(ctx, id, authContext.Sub)
+	if !workspaceInScope(ctx, id) {
+		return false, fmt.Errorf("workspace not found")
+	}
+	workspace, err := r.Repo.Workspace.GetWorkspaceByID(ctx, id, authContext.Sub)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch workspace: %v", err)
+	}
+	if workspace == nil || workspace.Owner != authContext.Sub {
+		return false, fmt.Errorf("workspace not found")
+	}
+	projectIDs, err := r.Repo.Workspace.GetProjectIDs(ctx, workspace.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch workspace projects: %v", err)
+	}
+	err = r.Repo.Workspace.DeleteWorkspace(ctx, id, authContext.Sub)
 	if err != nil {
 		return false, fmt.Errorf("failed to delete workspace: %v", err)
 	}
+	r.Cleanup.ProjectsRemoved(projectIDs...)
+	r.recordAudit(ctx, "workspace.delete", "workspace", id, &workspace.ID,
+		bson.M{"name": workspace.Name}, nil)
+	r.publishWorkspaceChange(ctx, model.EventTypeWorkspaceDeleted, workspace)
+	r.closeWorkspaceEvents(id, "")
 	return true, nil
 }
 
 // AddMemberToWorkspace is the resolver for the addMemberToWorkspace field.
 func (r *mutationResolver) AddMemberToWorkspace(ctx context.Context, workspaceID string, email string) (bool, error) {
 	authContext := auth.ForContext(ctx)
+	if !workspaceInScope(ctx, workspaceID) {
+		return false, fmt.Errorf("workspace not found")
+	}
 	workspace, err := r.Repo.Workspace.GetWorkspaceByID(ctx, workspaceID, authContext.Sub)
 	if err != nil {
 		return false, fmt.Errorf("failed to fetch workspace: %v", err)
@@ -49,16 +78,11 @@ func (r *mutationResolver) AddMemberToWorkspace(ctx context.Context, workspaceID
 	if workspace == nil || workspace.Owner != authContext.Sub {
 		return false, fmt.Errorf("workspace not found")
 	}
-	users, err := r.Repo.User.GetUserByEmail(ctx, email)
+	// Members are never added without their consent; this sends an editor
+	// invitation which the recipient has to accept.
+	_, _, err = r.inviteToWorkspace(ctx, workspace, email, models.WorkspaceRoleEditor)
 	if err != nil {
-		return false, fmt.Errorf("failed to fetch user by email: %v", err)
-	}
-	if len(users) == 0 {
-		return false, fmt.Errorf("user with email %s not found", email)
-	}
-	err = r.Repo.Workspace.AddMemberToWorkspace(ctx, workspaceID, users[0].ID)
-	if err != nil {
-		return false, fmt.Errorf("failed to add member to workspace: %v", err)
+		return false, err
 	}
 	return true, nil
 }
@@ -66,6 +90,9 @@ func (r *mutationResolver) AddMemberToWorkspace(ctx context.Context, workspaceID
 // RemoveMemberFromWorkspace is the resolver for the removeMemberFromWorkspace field.
 func (r *mutationResolver) RemoveMemberFromWorkspace(ctx context.Context, workspaceID string, userID string) (bool, error) {
 	authContext := auth.ForContext(ctx)
+	if !workspaceInScope(ctx, workspaceID) {
+		return false, fmt.Errorf("workspace not found")
+	}
 	workspace, err := r.Repo.Workspace.GetWorkspaceByID(ctx, workspaceID, authContext.Sub)
 	if err != nil {
 		return false, fmt.Errorf("failed to fetch workspace: %v", err)
@@ -77,17 +104,119 @@ func (r *mutationResolver) RemoveMemberFromWorkspace(ctx context.Context, worksp
 	if err != nil {
 		return false, fmt.Errorf("failed to remove member from workspace: %v", err)
 	}
+	r.recordAudit(ctx, "workspace.remove_member", "workspace", workspaceID, &workspace.ID,
+		bson.M{"member": userID, "role": workspace.RoleOf(userID)}, nil)
+	r.publishMemberEvent(ctx, model.EventTypeMemberRemoved, &workspace.ID, nil, userID)
+	revoked := newEvent(ctx, model.EventTypeAccessRevoked)
+	revoked.WorkspaceID = &workspaceID
+	revoked.UserID = &userID
+	r.publishUserEvent(revoked, userID)
+	r.closeWorkspaceEvents(workspaceID, userID)
+	r.revalidateWorkspaceAccess(ctx, workspace)
+	r.notify(ctx, &models.Notification{
+		UserID:      userID,
+		Type:        models.NotificationWorkspaceRemoved,
+		WorkspaceID: &workspace.ID,
+		Message:     fmt.Sprintf("%s removed you from %s", authContext.PreferredUsername, workspace.Name),
+	})
 	return true, nil
 }
 
 // UpdateWorkspaceMetadata is the resolver for the updateWorkspaceMetadata field.
 func (r *mutationResolver) UpdateWorkspaceMetadata(ctx context.Context, id string, name string, description string) (bool, error) {
 	authContext := auth.ForContext(ctx)
+	if !workspaceInScope(ctx, id) {
+		return false, fmt.Errorf("workspace not found")
+	}
+	workspace, err := r.Repo.Workspace.GetWorkspaceByID(ctx, id, authContext.Sub)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch workspace: %v", err)
+	}
+	if workspace == nil || workspace.Owner != authContext.Sub {
+		return false, fmt.Errorf("workspace not found")
+	}
 
-	err := r.Repo.Workspace.UpdateWorkspaceMetadata(ctx, id, name, description, authContext.Sub)
+	err = r.Repo.Workspace.UpdateWorkspaceMetadata(ctx, id, name, description, authContext.Sub)
 	if err != nil {
 		return false, fmt.Errorf("failed to update workspace metadata: %v", err)
 	}
+	r.recordAudit(ctx, "workspace.update_metadata", "workspace", id, &workspace.ID,
+		bson.M{"name": workspace.Name, "description": workspace.Description},
+		bson.M{"name": name, "description": description})
+	workspace.Name, workspace.Description = name, description
+	r.publishWorkspaceChange(ctx, model.EventTypeWorkspaceRenamed, workspace)
+	return true, nil
+}
+
+// TransferWorkspaceOwnership is the resolver for the transferWorkspaceOwnership field.
+func (r *mutationResolver) TransferWorkspaceOwnership(ctx context.Context, id string, newOwnerID string) (bool, error) {
+	authContext := auth.ForContext(ctx)
+	if !workspaceInScope(ctx, id) {
+		return false, fmt.Errorf("workspace not found")
+	}
+	workspace, err := r.Repo.Workspace.GetWorkspaceByID(ctx, id, authContext.Sub)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch workspace: %v", err)
+	}
+	if workspace == nil || workspace.Owner != authContext.Sub {
+		return false, fmt.Errorf("workspace not found")
+	}
+	if !slices.Contains(workspace.Members, newOwnerID) {
+		return false, fmt.Errorf("new owner must be a member of the workspace")
+	}
+
+	err = r.Repo.Workspace.TransferOwnership(ctx, id, authContext.Sub, newOwnerID)
+	if err != nil {
+		return false, fmt.Errorf("failed to transfer workspace ownership: %v", err)
+	}
+	r.recordAudit(ctx, "workspace.transfer_ownership", "workspace", id, &workspace.ID,
+		bson.M{"owner": authContext.Sub}, bson.M{"owner": newOwnerID})
+	return true, nil
+}
+
+// LeaveWorkspace is the resolver for the leaveWorkspace field.
+func (r *mutationResolver) LeaveWorkspace(ctx context.Context, workspaceID string) (bool, error) {
+	authContext := auth.ForContext(ctx)
+	if !workspaceInScope(ctx, workspaceID) {
+		return false, fmt.Errorf("workspace not found")
+	}
+	workspace, err := r.Repo.Workspace.GetWorkspaceByID(ctx, workspaceID, authContext.Sub)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch workspace: %v", err)
+	}
+	if workspace == nil {
+		return false, fmt.Errorf("workspace not found")
+	}
+	if workspace.Owner == authContext.Sub {
+		return false, fmt.Errorf("the owner cannot leave a workspace; transfer ownership first")
+	}
+	err = r.Repo.Workspace.RemoveMemberFromWorkspace(ctx, workspaceID, authContext.Sub)
+	if err != nil {
+		return false, fmt.Errorf("failed to leave workspace: %v", err)
+	}
+	r.recordAudit(ctx, "workspace.leave", "workspace", workspaceID, &workspace.ID,
+		bson.M{"member": authContext.Sub}, nil)
+	r.publishMemberEvent(ctx, model.EventTypeMemberRemoved, &workspace.ID, nil, authContext.Sub)
+	r.closeWorkspaceEvents(workspaceID, authContext.Sub)
+	r.revalidateWorkspaceAccess(ctx, workspace)
+	return true, nil
+}
+
+// RestoreWorkspace is the resolver for the restoreWorkspace field.
+func (r *mutationResolver) RestoreWorkspace(ctx context.Context, id string) (bool, error) {
+	authContext := auth.ForContext(ctx)
+	if !workspaceInScope(ctx, id) {
+		return false, fmt.Errorf("workspace not found in trash")
+	}
+	restored, err := r.Repo.Workspace.RestoreWorkspace(ctx, id, authContext.Sub)
+	if err != nil {
+		return false, fmt.Errorf("failed to restore workspace: %v", err)
+	}
+	if !restored {
+		return false, fmt.Errorf("workspace not found in trash")
+	}
+	workspaceID, _ := bson.ObjectIDFromHex(id)
+	r.recordAudit(ctx, "workspace.restore", "workspace", id, &workspaceID, nil, nil)
 	return true, nil
 }
 
@@ -99,6 +228,9 @@ func (r *queryResolver) Workspaces(ctx context.Context) ([]*model.Workspace, err
 // Workspace is the resolver for the workspace field.
 func (r *queryResolver) Workspace(ctx context.Context, id string) (*model.Workspace, error) {
 	authContext := auth.ForContext(ctx)
+	if !workspaceInScope(ctx, id) {
+		return nil, nil
+	}
 	workspace, err := r.Repo.Workspace.GetWorkspaceByID(ctx, id, authContext.Sub)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch workspace: %v", err)
@@ -117,6 +249,7 @@ func (r *queryResolver) Workspace(ctx context.Context, id string) (*model.Worksp
 			Email:    owner.Email,
 			FullName: owner.FirstName + " " + owner.LastName,
 			ImageURL: "",
+			Role:     model.WorkspaceRoleOwner,
 		},
 	}
 
@@ -133,6 +266,7 @@ func (r *queryResolver) Workspace(ctx context.Context, id string) (*model.Worksp
 				Email:    member.Email,
 				FullName: member.FirstName + " " + member.LastName,
 				ImageURL: "",
+				Role:     model.WorkspaceRole(workspace.RoleOf(member.ID)),
 			})
 		}
 	}
@@ -156,6 +290,9 @@ func (r *queryResolver) WorkspacesByUser(ctx context.Context, userID string) ([]
 	}
 	var result []*model.Workspace
 	for _, ws := range *workspaces {
+		if !workspaceInScope(ctx, ws.ID.Hex()) {
+			continue
+		}
 		result = append(result, &model.Workspace{
 			ID:          ws.ID.Hex(),
 			Name:        ws.Name,
@@ -176,6 +313,9 @@ func (r *queryResolver) SharedWorkspacesByUser(ctx context.Context, userID strin
 	}
 	var result []*model.Workspace
 	for _, ws := range *workspaces {
+		if !workspaceInScope(ctx, ws.ID.Hex()) {
+			continue
+		}
 		result = append(result, &model.Workspace{
 			ID:          ws.ID.Hex(),
 			Name:        ws.Name,
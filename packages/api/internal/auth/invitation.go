@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"github.com/chirag3003/collab-draw-backend/internal/oidc"
+	"github.com/chirag3003/collab-draw-backend/internal/repository"
+)
+
+// processedLogins remembers which users have already been checked for
+// automatic invitations since the server started.
+var processedLogins sync.Map
+
+// acceptAutoInvitations joins a newly registered user to the workspaces they
+// were invited to before they had an account. Invitations sent to existing
+// users always wait for an explicit accept.
+func acceptAutoInvitations(ctx context.Context, claims *oidc.Claims, repo *repository.Repository) {
+	if claims.Email == "" || !claims.EmailVerified {
+		return
+	}
+	if _, seen := processedLogins.LoadOrStore(claims.Sub, true); seen {
+		return
+	}
+
+	invitations, err := repo.Invitation.GetAutoAcceptByEmail(ctx, claims.Email)
+	if err != nil {
+		log.Printf("Warning: failed to fetch invitations for %s: %v", claims.Sub, err)
+		processedLogins.Delete(claims.Sub)
+		return
+	}
+	for _, invitation := range invitations {
+		accepted, err := repo.Invitation.SetStatus(ctx, invitation.ID, models.InvitationAccepted, claims.Sub)
+		if err != nil || !accepted {
+			continue
+		}
+		err = repo.Workspace.AddMemberToWorkspace(ctx, invitation.WorkspaceID.Hex(), claims.Sub, invitation.Role)
+		if err != nil {
+			log.Printf("Warning: failed to add %s to workspace %s: %v", claims.Sub, invitation.WorkspaceID.Hex(), err)
+		}
+	}
+}
@@ -2,19 +2,34 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"os"
 	"strings"
 
 	"github.com/chirag3003/collab-draw-backend/internal/oidc"
+	"github.com/chirag3003/collab-draw-backend/internal/repository"
 )
 
 // A private key for context that only this package can access.
 type contextKey string
 
 const UserContextKey = contextKey("user")
+const ScopeContextKey = contextKey("scope")
+
+// Credentials are what a client presents to authenticate, gathered either from
+// HTTP headers or from the WebSocket init payload.
+type Credentials struct {
+	Token         string
+	SharePassword string
+	GuestID       string
+	GuestName     string
+}
 
 // Middleware verifies the Bearer token and adds OIDC claims to the context.
-func Middleware() func(http.Handler) http.Handler {
+// Personal access tokens and project share links are accepted alongside OIDC
+// tokens.
+func Middleware(repo *repository.Repository) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -23,29 +38,82 @@ func Middleware() func(http.Handler) http.Handler {
 				return
 			}
 
-			tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
-
-			idToken, err := oidc.Verifier.Verify(r.Context(), tokenStr)
+			ctx, err := Authenticate(r.Context(), Credentials{
+				Token:         strings.TrimPrefix(authHeader, "Bearer "),
+				SharePassword: r.Header.Get("X-Share-Password"),
+				GuestID:       r.Header.Get("X-Guest-ID"),
+				GuestName:     r.Header.Get("X-Guest-Name"),
+			}, repo)
 			if err != nil {
-				http.Error(w, "unauthorized: invalid token", http.StatusUnauthorized)
+				http.Error(w, "unauthorized: "+err.Error(), http.StatusUnauthorized)
 				return
 			}
-
-			var claims oidc.Claims
-			if err := idToken.Claims(&claims); err != nil {
-				http.Error(w, "unauthorized: invalid claims", http.StatusUnauthorized)
-				return
+			// Guests send this back as X-Guest-ID to keep their session
+			if session := GuestSessionForContext(ctx); session != "" {
+				w.Header().Set("X-Guest-ID", session)
 			}
 
-			ctx := context.WithValue(r.Context(), UserContextKey, &claims)
 			r = r.WithContext(ctx)
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+// Authenticate resolves credentials to an identity and returns a context
+// carrying the claims, plus the scope for access tokens and share links.
+func Authenticate(ctx context.Context, creds Credentials, repo *repository.Repository) (context.Context, error) {
+	if strings.HasPrefix(creds.Token, AccessTokenPrefix) {
+		return authenticateAccessToken(ctx, creds.Token, repo.AccessToken)
+	}
+	if strings.HasPrefix(creds.Token, ShareTokenPrefix) {
+		return authenticateShareLink(ctx, creds, repo.ShareLink)
+	}
+
+	idToken, err := oidc.Verifier.Verify(ctx, creds.Token)
+	if err != nil {
+		return ctx, errors.New("invalid token")
+	}
+
+	var claims oidc.Claims
+	if err := idToken.Claims(&claims); err != nil {
+		return ctx, errors.New("invalid claims")
+	}
+
+	acceptAutoInvitations(ctx, &claims, repo)
+
+	return context.WithValue(ctx, UserContextKey, &claims), nil
+}
+
+// IsAdmin reports whether the request was made by an administrator: a user
+// signed in with OIDC holding the realm role named by ADMIN_ROLE ("admin" by
+// default). Access tokens and share links never grant admin rights.
+func IsAdmin(ctx context.Context) bool {
+	claims := ForContext(ctx)
+	if claims == nil || ScopeForContext(ctx) != nil {
+		return false
+	}
+	role := os.Getenv("ADMIN_ROLE")
+	if role == "" {
+		role = "admin"
+	}
+	return claims.HasRole(role)
+}
+
 // ForContext finds the user from the context. REQUIRES Middleware to have run.
 func ForContext(ctx context.Context) *oidc.Claims {
 	raw, _ := ctx.Value(UserContextKey).(*oidc.Claims)
 	return raw
 }
+
+// ScopeForContext returns the scope of a request authenticated with an access
+// token or share link, or nil when the request used an OIDC token.
+func ScopeForContext(ctx context.Context) *Scope {
+	raw, _ := ctx.Value(ScopeContextKey).(*Scope)
+	return raw
+}
+
+// IsGuest reports whether the request was authenticated with a share link.
+func IsGuest(ctx context.Context) bool {
+	scope := ScopeForContext(ctx)
+	return scope != nil && scope.ShareLinkID != ""
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+const RequestInfoContextKey = contextKey("request")
+
+// RequestInfo describes where a request came from, for auditing.
+type RequestInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// WithRequestInfo stores the client IP and user agent of r in the context. It
+// runs before the WebSocket upgrade so subscriptions carry it too.
+//
+// The client IP is the peer address. X-Forwarded-For is only honoured when the
+// peer is one of TRUSTED_PROXIES, and then the right-most hop that is not a
+// trusted proxy is taken, since clients can prepend anything to the header.
+func WithRequestInfo(ctx context.Context, r *http.Request) context.Context {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+	if isTrustedProxy(ip) {
+		hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			ip = hop
+			if !isTrustedProxy(hop) {
+				break
+			}
+		}
+	}
+	return context.WithValue(ctx, RequestInfoContextKey, &RequestInfo{
+		IP:        ip,
+		UserAgent: r.UserAgent(),
+	})
+}
+
+// RequestInfoForContext returns the request info stored by WithRequestInfo.
+func RequestInfoForContext(ctx context.Context) *RequestInfo {
+	raw, _ := ctx.Value(RequestInfoContextKey).(*RequestInfo)
+	if raw == nil {
+		return &RequestInfo{}
+	}
+	return raw
+}
+
+var (
+	trustedProxiesOnce sync.Once
+	trustedProxies     []*net.IPNet
+)
+
+// isTrustedProxy reports whether ip belongs to TRUSTED_PROXIES, a comma
+// separated list of IP addresses and CIDR ranges.
+func isTrustedProxy(ip string) bool {
+	trustedProxiesOnce.Do(func() {
+		for _, entry := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			if !strings.Contains(entry, "/") {
+				if strings.Contains(entry, ":") {
+					entry += "/128"
+				} else {
+					entry += "/32"
+				}
+			}
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				log.Printf("Warning: ignoring invalid TRUSTED_PROXIES entry %q", entry)
+				continue
+			}
+			trustedProxies = append(trustedProxies, network)
+		}
+	})
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"github.com/chirag3003/collab-draw-backend/internal/oidc"
+	"github.com/chirag3003/collab-draw-backend/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ShareTokenPrefix marks share link tokens.
+const ShareTokenPrefix = "cdshare_"
+
+// GuestSubPrefix prefixes the subject of guest identities so they can never
+// collide with Keycloak user IDs.
+const GuestSubPrefix = "guest:"
+
+const maxGuestNameLength = 64
+
+// GenerateShareToken returns a new random share link token along with the
+// hash that should be stored in place of it.
+func GenerateShareToken() (string, string, error) {
+	return generateToken(ShareTokenPrefix, 24)
+}
+
+// HashSharePassword hashes a share link password for storage.
+func HashSharePassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// VerifyShareLink checks the password of a share link.
+func VerifyShareLink(link *models.ShareLink, password string) error {
+	if link.PasswordHash == "" {
+		return nil
+	}
+	if password == "" {
+		return errors.New("share link requires a password")
+	}
+	if bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)) != nil {
+		return errors.New("invalid share link password")
+	}
+	return nil
+}
+
+func authenticateShareLink(ctx context.Context, creds Credentials, links repository.ShareLinkRepository) (context.Context, error) {
+	link, err := links.GetShareLinkByHash(ctx, HashToken(creds.Token))
+	if err != nil {
+		return ctx, errors.New("failed to verify share link")
+	}
+	if link == nil {
+		return ctx, errors.New("invalid share link")
+	}
+	if err := VerifyShareLink(link, creds.SharePassword); err != nil {
+		return ctx, err
+	}
+
+	// Guests keep the session the server issued them; anything else starts a
+	// new session, which counts as one use of the link
+	session := strings.TrimSpace(creds.GuestID)
+	guestID, ok := verifyGuestSession(link.ID.Hex(), session)
+	if !ok {
+		redeemed, err := links.RedeemShareLink(ctx, link.ID)
+		if err != nil {
+			return ctx, fmt.Errorf("failed to redeem share link: %w", err)
+		}
+		if !redeemed {
+			return ctx, errors.New("share link has reached its maximum number of uses")
+		}
+		guestID = randomGuestID()
+		session = signGuestSession(link.ID.Hex(), guestID)
+	}
+
+	guestName := strings.TrimSpace(creds.GuestName)
+	if guestName == "" {
+		guestName = "Guest"
+	}
+	if runes := []rune(guestName); len(runes) > maxGuestNameLength {
+		guestName = string(runes[:maxGuestNameLength])
+	}
+
+	claims := &oidc.Claims{
+		Sub:               GuestSubPrefix + guestID,
+		Name:              guestName,
+		PreferredUsername: guestName,
+	}
+	scope := &Scope{
+		ShareLinkID:  link.ID.Hex(),
+		ProjectID:    link.ProjectID.Hex(),
+		ReadOnly:     link.Permission != models.SharePermissionEdit,
+		GuestSession: session,
+	}
+
+	ctx = context.WithValue(ctx, UserContextKey, claims)
+	ctx = context.WithValue(ctx, ScopeContextKey, scope)
+	return ctx, nil
+}
+
+// GuestSessionForContext returns the session a guest should present as their
+// guest ID on later requests, or "" for other requests.
+func GuestSessionForContext(ctx context.Context) string {
+	if scope := ScopeForContext(ctx); scope != nil {
+		return scope.GuestSession
+	}
+	return ""
+}
+
+// signGuestSession binds a guest ID to a share link, so guests cannot pick
+// another guest's identity or move theirs to a different link.
+func signGuestSession(linkID string, guestID string) string {
+	mac := hmac.New(sha256.New, guestSecret())
+	mac.Write([]byte(linkID + ":" + guestID))
+	return guestID + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifyGuestSession returns the guest ID of a session issued for the link.
+func verifyGuestSession(linkID string, session string) (string, bool) {
+	guestID, _, ok := strings.Cut(session, ".")
+	if !ok || guestID == "" {
+		return "", false
+	}
+	if !hmac.Equal([]byte(session), []byte(signGuestSession(linkID, guestID))) {
+		return "", false
+	}
+	return guestID, true
+}
+
+var (
+	guestSecretOnce sync.Once
+	guestSecretKey  []byte
+)
+
+// guestSecret returns the key guest sessions are signed with, taken from
+// GUEST_SESSION_SECRET. Without it a random key is used, so guests start new
+// sessions when the server restarts.
+func guestSecret() []byte {
+	guestSecretOnce.Do(func() {
+		if v := os.Getenv("GUEST_SESSION_SECRET"); v != "" {
+			guestSecretKey = []byte(v)
+			return
+		}
+		log.Printf("Warning: GUEST_SESSION_SECRET not set, guest sessions will not survive restarts")
+		guestSecretKey = make([]byte, 32)
+		_, _ = rand.Read(guestSecretKey)
+	})
+	return guestSecretKey
+}
+
+func randomGuestID() string {
+	n, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		return "anonymous"
+	}
+	return n.Text(36)
+}
@@ -0,0 +1,115 @@
+package cleanup
+
+import (
+	"context"
+	"sync"
+
+	"github.com/chirag3003/collab-draw-backend/internal/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ProjectRemovedFunc is called for every project that is trashed or purged,
+// so that live connections to it can be dropped.
+type ProjectRemovedFunc func(projectID string)
+
+// Service deletes projects and workspaces together with every piece of data
+// scoped to them.
+type Service struct {
+	repo *repository.Repository
+
+	listenersMutex sync.RWMutex
+	listeners      []ProjectRemovedFunc
+}
+
+func NewService(repo *repository.Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// OnProjectRemoved registers a listener for removed projects.
+func (s *Service) OnProjectRemoved(fn ProjectRemovedFunc) {
+	s.listenersMutex.Lock()
+	defer s.listenersMutex.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// ProjectsRemoved notifies listeners that the given projects are gone.
+func (s *Service) ProjectsRemoved(projectIDs ...bson.ObjectID) {
+	s.listenersMutex.RLock()
+	defer s.listenersMutex.RUnlock()
+	for _, id := range projectIDs {
+		for _, fn := range s.listeners {
+			fn(id.Hex())
+		}
+	}
+}
+
+// deleteProjectData removes everything that belongs to the given projects
+// except the project documents themselves.
+func (s *Service) deleteProjectData(ctx context.Context, projectIDs []bson.ObjectID) error {
+	if err := s.repo.Operation.DeleteByProjects(ctx, projectIDs); err != nil {
+		return err
+	}
+	if err := s.repo.ShareLink.DeleteByProjects(ctx, projectIDs); err != nil {
+		return err
+	}
+	if err := s.repo.AccessToken.DeleteByProjects(ctx, projectIDs); err != nil {
+		return err
+	}
+	if err := s.repo.Comment.DeleteByProjects(ctx, projectIDs); err != nil {
+		return err
+	}
+	if err := s.repo.Notification.DeleteByProjects(ctx, projectIDs); err != nil {
+		return err
+	}
+	if err := s.repo.File.DeleteByProjects(ctx, projectIDs); err != nil {
+		return err
+	}
+	if err := s.repo.Search.DeleteByProjects(ctx, projectIDs); err != nil {
+		return err
+	}
+	if err := s.repo.ProjectState.DeleteByProjects(ctx, projectIDs); err != nil {
+		return err
+	}
+	return nil
+}
+
+// PurgeProject permanently removes a trashed project and its data.
+func (s *Service) PurgeProject(ctx context.Context, projectID bson.ObjectID) error {
+	s.ProjectsRemoved(projectID)
+	if err := s.deleteProjectData(ctx, []bson.ObjectID{projectID}); err != nil {
+		return err
+	}
+	return s.repo.Project.PurgeProject(ctx, projectID)
+}
+
+// PurgeWorkspace permanently removes a trashed workspace, every project in it
+// and their data.
+func (s *Service) PurgeWorkspace(ctx context.Context, workspaceID bson.ObjectID) error {
+	projectIDs, err := s.repo.Workspace.GetProjectIDs(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+	s.ProjectsRemoved(projectIDs...)
+	if err := s.deleteProjectData(ctx, projectIDs); err != nil {
+		return err
+	}
+	if err := s.repo.Invitation.DeleteByWorkspace(ctx, workspaceID); err != nil {
+		return err
+	}
+	if err := s.repo.AccessToken.DeleteByWorkspace(ctx, workspaceID); err != nil {
+		return err
+	}
+	if err := s.repo.Notification.DeleteByWorkspace(ctx, workspaceID); err != nil {
+		return err
+	}
+	if err := s.repo.Webhook.DeleteByWorkspace(ctx, workspaceID); err != nil {
+		return err
+	}
+	if err := s.repo.Library.DeleteByWorkspace(ctx, workspaceID); err != nil {
+		return err
+	}
+	if err := s.repo.Folder.DeleteByWorkspace(ctx, workspaceID); err != nil {
+		return err
+	}
+	return s.repo.Workspace.PurgeWorkspace(ctx, workspaceID)
+}
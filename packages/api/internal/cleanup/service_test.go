@@ -0,0 +1,411 @@
+package cleanup
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"github.com/chirag3003/collab-draw-backend/internal/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Collections holding data scoped to projects or to workspaces.
+var (
+	projectCollections   = []string{"operations", "share_links", "access_tokens", "comments", "notifications", "files", "search", "project_state"}
+	workspaceCollections = []string{"invitations", "access_tokens", "notifications", "webhooks", "libraries", "folders"}
+)
+
+var errStore = errors.New("store unavailable")
+
+// store is an in-memory stand-in for the database. It counts the documents of
+// each collection by the project or workspace that owns them.
+type store struct {
+	mu         sync.Mutex
+	workspaces map[bson.ObjectID][]bson.ObjectID // workspace -> projects
+	trashed    map[bson.ObjectID]bool
+	docs       map[string]map[bson.ObjectID]int
+	// fail, when set, decides whether deleting a collection's documents of
+	// the given owner fails.
+	fail func(collection string, owner bson.ObjectID) error
+}
+
+func newStore() *store {
+	return &store{
+		workspaces: map[bson.ObjectID][]bson.ObjectID{},
+		trashed:    map[bson.ObjectID]bool{},
+		docs:       map[string]map[bson.ObjectID]int{},
+	}
+}
+
+// addWorkspace creates a workspace with the given number of projects, each
+// holding a document in every collection.
+func (s *store) addWorkspace(projects int) (bson.ObjectID, []bson.ObjectID) {
+	workspaceID := bson.NewObjectID()
+	s.put("workspaces", workspaceID)
+	for _, collection := range workspaceCollections {
+		s.put(collection, workspaceID)
+	}
+	var projectIDs []bson.ObjectID
+	for range projects {
+		projectID := bson.NewObjectID()
+		s.put("projects", projectID)
+		for _, collection := range projectCollections {
+			s.put(collection, projectID)
+		}
+		projectIDs = append(projectIDs, projectID)
+	}
+	s.workspaces[workspaceID] = projectIDs
+	return workspaceID, projectIDs
+}
+
+func (s *store) put(collection string, owner bson.ObjectID) {
+	if s.docs[collection] == nil {
+		s.docs[collection] = map[bson.ObjectID]int{}
+	}
+	s.docs[collection][owner]++
+}
+
+func (s *store) count(collection string, owner bson.ObjectID) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.docs[collection][owner]
+}
+
+func (s *store) delete(collection string, owners ...bson.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, owner := range owners {
+		if s.fail != nil {
+			if err := s.fail(collection, owner); err != nil {
+				return err
+			}
+		}
+	}
+	for _, owner := range owners {
+		delete(s.docs[collection], owner)
+	}
+	return nil
+}
+
+// failOnce makes the first delete from the collection fail.
+func (s *store) failOnce(collection string) {
+	failed := false
+	s.fail = func(c string, _ bson.ObjectID) error {
+		if c != collection || failed {
+			return nil
+		}
+		failed = true
+		return errStore
+	}
+}
+
+type fakeProjects struct {
+	repository.ProjectRepository
+	s *store
+}
+
+func (f fakeProjects) GetPurgeableProjects(ctx context.Context, deletedBefore time.Time) ([]*models.Project, error) {
+	f.s.mu.Lock()
+	defer f.s.mu.Unlock()
+	var projects []*models.Project
+	for id := range f.s.docs["projects"] {
+		if f.s.trashed[id] {
+			projects = append(projects, &models.Project{ID: id})
+		}
+	}
+	return projects, nil
+}
+
+func (f fakeProjects) PurgeProject(ctx context.Context, id bson.ObjectID) error {
+	return f.s.delete("projects", id)
+}
+
+type fakeWorkspaces struct {
+	repository.WorkspaceRepository
+	s *store
+}
+
+func (f fakeWorkspaces) GetPurgeableWorkspaces(ctx context.Context, deletedBefore time.Time) ([]*models.Workspace, error) {
+	f.s.mu.Lock()
+	defer f.s.mu.Unlock()
+	var workspaces []*models.Workspace
+	for id := range f.s.docs["workspaces"] {
+		if f.s.trashed[id] {
+			workspaces = append(workspaces, &models.Workspace{ID: id})
+		}
+	}
+	return workspaces, nil
+}
+
+func (f fakeWorkspaces) GetProjectIDs(ctx context.Context, id bson.ObjectID) ([]bson.ObjectID, error) {
+	f.s.mu.Lock()
+	defer f.s.mu.Unlock()
+	return slices.Clone(f.s.workspaces[id]), nil
+}
+
+func (f fakeWorkspaces) PurgeWorkspace(ctx context.Context, id bson.ObjectID) error {
+	projectIDs, _ := f.GetProjectIDs(ctx, id)
+	if err := f.s.delete("projects", projectIDs...); err != nil {
+		return err
+	}
+	return f.s.delete("workspaces", id)
+}
+
+type fakeOperations struct {
+	repository.OperationRepository
+	s *store
+}
+
+func (f fakeOperations) DeleteByProjects(ctx context.Context, ids []bson.ObjectID) error {
+	return f.s.delete("operations", ids...)
+}
+
+type fakeShareLinks struct {
+	repository.ShareLinkRepository
+	s *store
+}
+
+func (f fakeShareLinks) DeleteByProjects(ctx context.Context, ids []bson.ObjectID) error {
+	return f.s.delete("share_links", ids...)
+}
+
+type fakeAccessTokens struct {
+	repository.AccessTokenRepository
+	s *store
+}
+
+func (f fakeAccessTokens) DeleteByProjects(ctx context.Context, ids []bson.ObjectID) error {
+	return f.s.delete("access_tokens", ids...)
+}
+
+func (f fakeAccessTokens) DeleteByWorkspace(ctx context.Context, id bson.ObjectID) error {
+	return f.s.delete("access_tokens", id)
+}
+
+type fakeComments struct {
+	repository.CommentRepository
+	s *store
+}
+
+func (f fakeComments) DeleteByProjects(ctx context.Context, ids []bson.ObjectID) error {
+	return f.s.delete("comments", ids...)
+}
+
+type fakeNotifications struct {
+	repository.NotificationRepository
+	s *store
+}
+
+func (f fakeNotifications) DeleteByProjects(ctx context.Context, ids []bson.ObjectID) error {
+	return f.s.delete("notifications", ids...)
+}
+
+func (f fakeNotifications) DeleteByWorkspace(ctx context.Context, id bson.ObjectID) error {
+	return f.s.delete("notifications", id)
+}
+
+type fakeFiles struct {
+	repository.FileRepository
+	s *store
+}
+
+func (f fakeFiles) DeleteByProjects(ctx context.Context, ids []bson.ObjectID) error {
+	return f.s.delete("files", ids...)
+}
+
+type fakeSearch struct {
+	repository.SearchRepository
+	s *store
+}
+
+func (f fakeSearch) DeleteByProjects(ctx context.Context, ids []bson.ObjectID) error {
+	return f.s.delete("search", ids...)
+}
+
+type fakeProjectState struct {
+	repository.ProjectUserStateRepository
+	s *store
+}
+
+func (f fakeProjectState) DeleteByProjects(ctx context.Context, ids []bson.ObjectID) error {
+	return f.s.delete("project_state", ids...)
+}
+
+type fakeInvitations struct {
+	repository.InvitationRepository
+	s *store
+}
+
+func (f fakeInvitations) DeleteByWorkspace(ctx context.Context, id bson.ObjectID) error {
+	return f.s.delete("invitations", id)
+}
+
+type fakeWebhooks struct {
+	repository.WebhookRepository
+	s *store
+}
+
+func (f fakeWebhooks) DeleteByWorkspace(ctx context.Context, id bson.ObjectID) error {
+	return f.s.delete("webhooks", id)
+}
+
+type fakeLibraries struct {
+	repository.LibraryRepository
+	s *store
+}
+
+func (f fakeLibraries) DeleteByWorkspace(ctx context.Context, id bson.ObjectID) error {
+	return f.s.delete("libraries", id)
+}
+
+type fakeFolders struct {
+	repository.FolderRepository
+	s *store
+}
+
+func (f fakeFolders) DeleteByWorkspace(ctx context.Context, id bson.ObjectID) error {
+	return f.s.delete("folders", id)
+}
+
+func newTestService(s *store) *Service {
+	return NewService(&repository.Repository{
+		Project:      fakeProjects{s: s},
+		Workspace:    fakeWorkspaces{s: s},
+		Operation:    fakeOperations{s: s},
+		AccessToken:  fakeAccessTokens{s: s},
+		ShareLink:    fakeShareLinks{s: s},
+		Invitation:   fakeInvitations{s: s},
+		Comment:      fakeComments{s: s},
+		Notification: fakeNotifications{s: s},
+		Webhook:      fakeWebhooks{s: s},
+		Library:      fakeLibraries{s: s},
+		File:         fakeFiles{s: s},
+		Search:       fakeSearch{s: s},
+		Folder:       fakeFolders{s: s},
+		ProjectState: fakeProjectState{s: s},
+	})
+}
+
+// assertProjectData checks whether every collection still holds the
+// project's documents.
+func assertProjectData(t *testing.T, s *store, projectID bson.ObjectID, want bool) {
+	t.Helper()
+	for _, collection := range append([]string{"projects"}, projectCollections...) {
+		if got := s.count(collection, projectID) > 0; got != want {
+			t.Errorf("%s of project %s present = %v, want %v", collection, projectID.Hex(), got, want)
+		}
+	}
+}
+
+// assertWorkspaceData checks whether every collection still holds the
+// workspace's documents.
+func assertWorkspaceData(t *testing.T, s *store, workspaceID bson.ObjectID, want bool) {
+	t.Helper()
+	for _, collection := range append([]string{"workspaces"}, workspaceCollections...) {
+		if got := s.count(collection, workspaceID) > 0; got != want {
+			t.Errorf("%s of workspace %s present = %v, want %v", collection, workspaceID.Hex(), got, want)
+		}
+	}
+}
+
+func TestPurgeProject(t *testing.T) {
+	s := newStore()
+	_, projectIDs := s.addWorkspace(2)
+	svc := newTestService(s)
+	var removed []string
+	svc.OnProjectRemoved(func(projectID string) { removed = append(removed, projectID) })
+
+	if err := svc.PurgeProject(context.Background(), projectIDs[0]); err != nil {
+		t.Fatalf("PurgeProject: %v", err)
+	}
+
+	assertProjectData(t, s, projectIDs[0], false)
+	assertProjectData(t, s, projectIDs[1], true)
+	if !slices.Equal(removed, []string{projectIDs[0].Hex()}) {
+		t.Errorf("listeners saw %v, want only %s", removed, projectIDs[0].Hex())
+	}
+}
+
+func TestPurgeWorkspace(t *testing.T) {
+	s := newStore()
+	workspaceID, projectIDs := s.addWorkspace(2)
+	otherID, otherProjects := s.addWorkspace(1)
+	svc := newTestService(s)
+	var removed []string
+	svc.OnProjectRemoved(func(projectID string) { removed = append(removed, projectID) })
+
+	if err := svc.PurgeWorkspace(context.Background(), workspaceID); err != nil {
+		t.Fatalf("PurgeWorkspace: %v", err)
+	}
+
+	assertWorkspaceData(t, s, workspaceID, false)
+	for _, id := range projectIDs {
+		assertProjectData(t, s, id, false)
+	}
+	assertWorkspaceData(t, s, otherID, true)
+	assertProjectData(t, s, otherProjects[0], true)
+	if len(removed) != len(projectIDs) {
+		t.Errorf("listeners saw %d projects, want %d", len(removed), len(projectIDs))
+	}
+}
+
+// A purge that fails partway must leave the project or workspace document in
+// place, so that the next run finds it again and finishes the job.
+func TestPurgeRetriesAfterPartialFailure(t *testing.T) {
+	for _, collection := range []string{"files", "webhooks", "projects"} {
+		t.Run(collection, func(t *testing.T) {
+			s := newStore()
+			workspaceID, projectIDs := s.addWorkspace(1)
+			s.trashed[workspaceID] = true
+			s.failOnce(collection)
+			svc := newTestService(s)
+
+			err := svc.PurgeTrash(context.Background(), time.Now())
+			if !errors.Is(err, errStore) {
+				t.Fatalf("PurgeTrash = %v, want %v", err, errStore)
+			}
+			if s.count("workspaces", workspaceID) == 0 {
+				t.Fatal("workspace was removed although its purge failed")
+			}
+
+			if err := svc.PurgeTrash(context.Background(), time.Now()); err != nil {
+				t.Fatalf("retry: %v", err)
+			}
+			assertWorkspaceData(t, s, workspaceID, false)
+			assertProjectData(t, s, projectIDs[0], false)
+		})
+	}
+}
+
+func TestPurgeTrashContinuesPastFailures(t *testing.T) {
+	s := newStore()
+	_, projectIDs := s.addWorkspace(3)
+	for _, id := range projectIDs {
+		s.trashed[id] = true
+	}
+	bad := projectIDs[1]
+	s.fail = func(collection string, owner bson.ObjectID) error {
+		if collection == "comments" && owner == bad {
+			return errStore
+		}
+		return nil
+	}
+	svc := newTestService(s)
+
+	err := svc.PurgeTrash(context.Background(), time.Now())
+	if !errors.Is(err, errStore) {
+		t.Fatalf("PurgeTrash = %v, want %v", err, errStore)
+	}
+	for _, id := range projectIDs {
+		if id != bad {
+			assertProjectData(t, s, id, false)
+		}
+	}
+	if s.count("projects", bad) == 0 || s.count("comments", bad) == 0 {
+		t.Error("project was removed although its purge failed")
+	}
+}
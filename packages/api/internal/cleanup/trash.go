@@ -0,0 +1,80 @@
+package cleanup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// defaultTrashRetentionDays applies when TRASH_RETENTION_DAYS is unset.
+// A value of 0 keeps trashed items until they are restored.
+const defaultTrashRetentionDays = 30
+
+const purgeInterval = time.Hour
+
+// TrashRetention returns how long trashed projects and workspaces are kept
+// before they are purged, as configured by TRASH_RETENTION_DAYS.
+func TrashRetention() time.Duration {
+	days := defaultTrashRetentionDays
+	if v := os.Getenv("TRASH_RETENTION_DAYS"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 {
+			log.Printf("Warning: invalid TRASH_RETENTION_DAYS %q, using %d", v, defaultTrashRetentionDays)
+		} else {
+			days = parsed
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// StartTrashPurger periodically removes projects and workspaces that have been
+// in the trash for longer than the retention period.
+func (s *Service) StartTrashPurger() {
+	retention := TrashRetention()
+	if retention == 0 {
+		return
+	}
+	go func() {
+		for {
+			if err := s.PurgeTrash(context.Background(), time.Now().Add(-retention)); err != nil {
+				log.Printf("Warning: trash purge failed: %v", err)
+			}
+			time.Sleep(purgeInterval)
+		}
+	}()
+}
+
+// PurgeTrash permanently removes everything trashed before the given time.
+// A project or workspace that fails to purge is logged and skipped so that the
+// rest of the trash still empties; the failures are returned together and the
+// skipped items are retried on the next run.
+func (s *Service) PurgeTrash(ctx context.Context, deletedBefore time.Time) error {
+	var errs []error
+
+	workspaces, err := s.repo.Workspace.GetPurgeableWorkspaces(ctx, deletedBefore)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to list purgeable workspaces: %w", err))
+	}
+	for _, workspace := range workspaces {
+		if err := s.PurgeWorkspace(ctx, workspace.ID); err != nil {
+			log.Printf("Warning: failed to purge workspace %s: %v", workspace.ID.Hex(), err)
+			errs = append(errs, fmt.Errorf("workspace %s: %w", workspace.ID.Hex(), err))
+		}
+	}
+
+	projects, err := s.repo.Project.GetPurgeableProjects(ctx, deletedBefore)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to list purgeable projects: %w", err))
+	}
+	for _, project := range projects {
+		if err := s.PurgeProject(ctx, project.ID); err != nil {
+			log.Printf("Warning: failed to purge project %s: %v", project.ID.Hex(), err)
+			errs = append(errs, fmt.Errorf("project %s: %w", project.ID.Hex(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
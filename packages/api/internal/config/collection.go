@@ -4,3 +4,21 @@ const PROJECT = "projects"
 const USER = "users"
 const WORKSPACE = "workspaces"
 const OPERATIONS = "operations"
+const ACCESS_TOKENS = "access_tokens"
+const SHARE_LINKS = "share_links"
+const INVITATIONS = "invitations"
+const AUDIT_LOG = "audit_log"
+const COMMENT_THREADS = "comment_threads"
+const COMMENTS = "comments"
+const NOTIFICATIONS = "notifications"
+const NOTIFICATION_PREFERENCES = "notification_preferences"
+const WEBHOOKS = "webhooks"
+const WEBHOOK_DELIVERIES = "webhook_deliveries"
+const LIBRARIES = "libraries"
+const FILES = "files"
+const BLOBS = "blobs"
+const BLOB_BUCKET = "blob_store"
+const SEARCH_INDEX = "search_index"
+const FOLDERS = "folders"
+const PROJECT_USER_STATE = "project_user_state"
+const MIGRATIONS = "migrations"
@@ -43,3 +43,7 @@ func ConnectMongo() Connection {
 func GetCollection(name string) *mongo.Collection {
 	return connection.DB().Collection(name)
 }
+
+func GetDatabase() *mongo.Database {
+	return connection.DB()
+}
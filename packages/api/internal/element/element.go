@@ -0,0 +1,135 @@
+package element
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+const (
+	// MaxSize caps the JSON of a single element. Freehand strokes with many
+	// points are the largest; images keep their contents in files.
+	MaxSize = 512 << 10
+	// maxIDLength caps element IDs, which Excalidraw generates 21 characters long.
+	maxIDLength = 64
+	// maxCoordinate bounds positions and sizes, far beyond any real canvas.
+	maxCoordinate = 1e8
+)
+
+// types are the Excalidraw element types the server accepts.
+var types = map[string]bool{
+	"rectangle": true, "ellipse": true, "diamond": true, "line": true, "arrow": true,
+	"freedraw": true, "text": true, "image": true, "frame": true, "magicframe": true,
+	"embeddable": true, "iframe": true,
+}
+
+// IsKnownType reports whether t is an accepted element type.
+func IsKnownType(t string) bool {
+	return types[t]
+}
+
+// ValidID reports whether id can identify an element.
+func ValidID(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// Fields holds what Validate checked, for callers that need to compare it
+// with the rest of the op.
+type Fields struct {
+	ID        string
+	Type      string
+	Version   int
+	IsDeleted bool
+}
+
+// Validate checks that data is an Excalidraw element the other clients can
+// render: a JSON object of at most MaxSize bytes with an ID, a known type, a
+// positive integer version and geometry within bounds. Unknown fields are
+// allowed so newer Excalidraw versions keep working.
+func Validate(data string) (*Fields, error) {
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("element is %d bytes, more than the limit of %d", len(data), MaxSize)
+	}
+	var el map[string]any
+	if err := json.Unmarshal([]byte(data), &el); err != nil || el == nil {
+		return nil, fmt.Errorf("element is not a JSON object")
+	}
+
+	fields := &Fields{}
+	id, ok := el["id"].(string)
+	if !ok || !ValidID(id) {
+		return nil, fmt.Errorf("element id is missing or invalid")
+	}
+	fields.ID = id
+	if fields.Type, ok = el["type"].(string); !ok {
+		return nil, fmt.Errorf("element type is missing")
+	}
+	if !types[fields.Type] {
+		return nil, fmt.Errorf("unsupported element type %q", fields.Type)
+	}
+
+	version, ok := el["version"].(float64)
+	if !ok || version < 1 || version != math.Trunc(version) || version > math.MaxInt32 {
+		return nil, fmt.Errorf("element version must be a positive integer")
+	}
+	fields.Version = int(version)
+	if v, present := el["isDeleted"]; present {
+		if fields.IsDeleted, ok = v.(bool); !ok {
+			return nil, fmt.Errorf("element isDeleted must be a boolean")
+		}
+	}
+
+	for _, name := range []string{"x", "y"} {
+		if err := checkNumber(el, name, true, -maxCoordinate, maxCoordinate); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range []string{"width", "height"} {
+		if err := checkNumber(el, name, true, 0, maxCoordinate); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkNumber(el, "angle", false, -4*math.Pi, 4*math.Pi); err != nil {
+		return nil, err
+	}
+	if err := checkNumber(el, "opacity", false, 0, 100); err != nil {
+		return nil, err
+	}
+	if err := checkNumber(el, "strokeWidth", false, 0, 1000); err != nil {
+		return nil, err
+	}
+	if fields.Type == "text" {
+		if err := checkNumber(el, "fontSize", false, 0, 10000); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
+// checkNumber fails when the field is not a number between lo and hi, or
+// is missing while required.
+func checkNumber(el map[string]any, name string, required bool, lo float64, hi float64) error {
+	v, present := el[name]
+	if !present {
+		if required {
+			return fmt.Errorf("element %s is missing", name)
+		}
+		return nil
+	}
+	n, ok := v.(float64)
+	if !ok {
+		return fmt.Errorf("element %s must be a number", name)
+	}
+	if n < lo || n > hi {
+		return fmt.Errorf("element %s %g is out of range [%g, %g]", name, n, lo, hi)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package element
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// remove marks a field to drop from the base element.
+type remove struct{}
+
+// build returns a valid rectangle with the given fields changed or removed.
+func build(t *testing.T, changes map[string]any) string {
+	t.Helper()
+	el := map[string]any{
+		"id":          "r1",
+		"type":        "rectangle",
+		"version":     1,
+		"isDeleted":   false,
+		"x":           10,
+		"y":           -20,
+		"width":       100,
+		"height":      50,
+		"angle":       0,
+		"opacity":     100,
+		"strokeWidth": 2,
+	}
+	for name, v := range changes {
+		if _, ok := v.(remove); ok {
+			delete(el, name)
+			continue
+		}
+		el[name] = v
+	}
+	data, err := json.Marshal(el)
+	if err != nil {
+		t.Fatalf("failed to encode element: %v", err)
+	}
+	return string(data)
+}
+
+func TestValidateValidTypes(t *testing.T) {
+	for _, typ := range []string{
+		"rectangle", "ellipse", "diamond", "line", "arrow", "freedraw", "text",
+		"image", "frame", "magicframe", "embeddable", "iframe",
+	} {
+		changes := map[string]any{"type": typ, "version": 7}
+		if typ == "text" {
+			changes["fontSize"] = 20
+		}
+		fields, err := Validate(build(t, changes))
+		if err != nil {
+			t.Errorf("%s: Validate() = %v, want no error", typ, err)
+			continue
+		}
+		if fields.ID != "r1" || fields.Type != typ || fields.Version != 7 || fields.IsDeleted {
+			t.Errorf("%s: Validate() = %+v", typ, fields)
+		}
+	}
+}
+
+func TestValidateOptionalFields(t *testing.T) {
+	data := build(t, map[string]any{
+		"isDeleted":   remove{},
+		"angle":       remove{},
+		"opacity":     remove{},
+		"strokeWidth": remove{},
+		"customData":  map[string]any{"from": "a newer client"},
+	})
+	if _, err := Validate(data); err != nil {
+		t.Errorf("Validate() = %v, want no error", err)
+	}
+
+	fields, err := Validate(build(t, map[string]any{"isDeleted": true}))
+	if err != nil || !fields.IsDeleted {
+		t.Errorf("Validate() = %+v, %v, want a deleted element", fields, err)
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	oversized := build(t, map[string]any{"points": strings.Repeat("0", MaxSize)})
+
+	tests := []struct {
+		name   string
+		data   string
+		reason string
+	}{
+		{"oversized", oversized, "element is " + strconv.Itoa(len(oversized)) + " bytes, more than the limit of 524288"},
+		{"not json", "{", "element is not a JSON object"},
+		{"array", "[]", "element is not a JSON object"},
+		{"null", "null", "element is not a JSON object"},
+		{"NaN literal", `{"id":"r1","type":"rectangle","version":1,"x":NaN}`, "element is not a JSON object"},
+		{"number overflow", `{"id":"r1","type":"rectangle","version":1,"x":1e999}`, "element is not a JSON object"},
+		{"missing id", build(t, map[string]any{"id": remove{}}), "element id is missing or invalid"},
+		{"empty id", build(t, map[string]any{"id": ""}), "element id is missing or invalid"},
+		{"numeric id", build(t, map[string]any{"id": 5}), "element id is missing or invalid"},
+		{"id with spaces", build(t, map[string]any{"id": "a b"}), "element id is missing or invalid"},
+		{"long id", build(t, map[string]any{"id": strings.Repeat("a", 65)}), "element id is missing or invalid"},
+		{"missing type", build(t, map[string]any{"type": remove{}}), "element type is missing"},
+		{"unknown type", build(t, map[string]any{"type": "hexagon"}), `unsupported element type "hexagon"`},
+		{"missing version", build(t, map[string]any{"version": remove{}}), "element version must be a positive integer"},
+		{"zero version", build(t, map[string]any{"version": 0}), "element version must be a positive integer"},
+		{"negative version", build(t, map[string]any{"version": -3}), "element version must be a positive integer"},
+		{"fractional version", build(t, map[string]any{"version": 1.5}), "element version must be a positive integer"},
+		{"string version", build(t, map[string]any{"version": "2"}), "element version must be a positive integer"},
+		{"string isDeleted", build(t, map[string]any{"isDeleted": "true"}), "element isDeleted must be a boolean"},
+		{"numeric isDeleted", build(t, map[string]any{"isDeleted": 1}), "element isDeleted must be a boolean"},
+		{"missing x", build(t, map[string]any{"x": remove{}}), "element x is missing"},
+		{"missing height", build(t, map[string]any{"height": remove{}}), "element height is missing"},
+		{"NaN string x", build(t, map[string]any{"x": "NaN"}), "element x must be a number"},
+		{"null y", build(t, map[string]any{"y": nil}), "element y must be a number"},
+		{"x out of range", build(t, map[string]any{"x": 2e8}), "element x 2e+08 is out of range [-1e+08, 1e+08]"},
+		{"y out of range", build(t, map[string]any{"y": -2e8}), "element y -2e+08 is out of range [-1e+08, 1e+08]"},
+		{"negative width", build(t, map[string]any{"width": -1}), "element width -1 is out of range [0, 1e+08]"},
+		{"height out of range", build(t, map[string]any{"height": 1e9}), "element height 1e+09 is out of range [0, 1e+08]"},
+		{"angle out of range", build(t, map[string]any{"angle": 13}), "element angle 13 is out of range [-12.566370614359172, 12.566370614359172]"},
+		{"string angle", build(t, map[string]any{"angle": "1"}), "element angle must be a number"},
+		{"opacity out of range", build(t, map[string]any{"opacity": 101}), "element opacity 101 is out of range [0, 100]"},
+		{"negative strokeWidth", build(t, map[string]any{"strokeWidth": -1}), "element strokeWidth -1 is out of range [0, 1000]"},
+		{"fontSize out of range", build(t, map[string]any{"type": "text", "fontSize": 20000}), "element fontSize 20000 is out of range [0, 10000]"},
+		{"string fontSize", build(t, map[string]any{"type": "text", "fontSize": "big"}), "element fontSize must be a number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := Validate(tt.data)
+			if err == nil {
+				t.Fatalf("Validate() = %+v, want %q", fields, tt.reason)
+			}
+			if err.Error() != tt.reason {
+				t.Errorf("Validate() = %q, want %q", err.Error(), tt.reason)
+			}
+		})
+	}
+}
+
+// fontSize is only checked on text elements.
+func TestValidateFontSizeOnlyOnText(t *testing.T) {
+	if _, err := Validate(build(t, map[string]any{"fontSize": "big"})); err != nil {
+		t.Errorf("Validate() = %v, want fontSize ignored on a rectangle", err)
+	}
+}
+
+func TestValidID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"pQ3x_-9aZ", true},
+		{strings.Repeat("a", maxIDLength), true},
+		{"", false},
+		{strings.Repeat("a", maxIDLength+1), false},
+		{"a b", false},
+		{"a\nb", false},
+		{"é", false},
+	}
+	for _, tt := range tests {
+		if got := ValidID(tt.id); got != tt.want {
+			t.Errorf("ValidID(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package export
+
+import (
+	"encoding/json"
+)
+
+// Formats supported by the export endpoint.
+const (
+	FormatExcalidraw = "excalidraw"
+	FormatSVG        = "svg"
+	FormatPNG        = "png"
+)
+
+// ContentTypes maps each export format to its MIME type.
+var ContentTypes = map[string]string{
+	FormatExcalidraw: "application/vnd.excalidraw+json",
+	FormatSVG:        "image/svg+xml",
+	FormatPNG:        "image/png",
+}
+
+// Document is the .excalidraw file format.
+type Document struct {
+	Type     string            `json:"type"`
+	Version  int               `json:"version"`
+	Source   string            `json:"source"`
+	Elements []json.RawMessage `json:"elements"`
+	AppState map[string]any    `json:"appState"`
+	Files    map[string]any    `json:"files"`
+}
+
+// RenderExcalidraw writes the scene as a .excalidraw JSON document that can be
+// opened in Excalidraw. Elements keep every field they were stored with.
+func RenderExcalidraw(scene *Scene, source string) ([]byte, error) {
+	doc := Document{
+		Type:     "excalidraw",
+		Version:  2,
+		Source:   source,
+		Elements: make([]json.RawMessage, 0, len(scene.Elements)),
+		AppState: map[string]any{
+			"viewBackgroundColor": "#ffffff",
+			"gridSize":            nil,
+		},
+		Files: map[string]any{},
+	}
+	for _, el := range scene.Elements {
+		doc.Elements = append(doc.Elements, el.Raw)
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// LibraryDocument is the .excalidrawlib file format.
+type LibraryDocument struct {
+	Type         string        `json:"type"`
+	Version      int           `json:"version"`
+	Source       string        `json:"source"`
+	LibraryItems []LibraryItem `json:"libraryItems"`
+}
+
+// LibraryItem is one entry of a .excalidrawlib file. Tags are not part of
+// the Excalidraw format; Excalidraw ignores them but they survive re-import.
+type LibraryItem struct {
+	ID       string          `json:"id"`
+	Status   string          `json:"status"`
+	Created  int64           `json:"created"`
+	Name     string          `json:"name,omitempty"`
+	Tags     []string        `json:"tags,omitempty"`
+	Elements json.RawMessage `json:"elements"`
+}
+
+// RenderLibrary writes library items as a .excalidrawlib JSON document.
+func RenderLibrary(items []LibraryItem, source string) ([]byte, error) {
+	if items == nil {
+		items = []LibraryItem{}
+	}
+	return json.MarshalIndent(LibraryDocument{
+		Type:         "excalidrawlib",
+		Version:      2,
+		Source:       source,
+		LibraryItems: items,
+	}, "", "  ")
+}
@@ -0,0 +1,132 @@
+package export
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LinkTTL is how long a signed download URL stays valid.
+const LinkTTL = 5 * time.Minute
+
+var (
+	linkSecret     []byte
+	linkSecretOnce sync.Once
+)
+
+// Request describes what to export.
+type Request struct {
+	ProjectID  string
+	Format     string
+	Seq        *int32
+	ElementIDs []string
+	Scale      float64
+}
+
+// secret returns the key download URLs are signed with, taken from
+// EXPORT_URL_SECRET. Without it a random key is used, so URLs stop working
+// when the server restarts.
+func secret() []byte {
+	linkSecretOnce.Do(func() {
+		if v := os.Getenv("EXPORT_URL_SECRET"); v != "" {
+			linkSecret = []byte(v)
+			return
+		}
+		log.Printf("Warning: EXPORT_URL_SECRET not set, export links will not survive restarts")
+		linkSecret = make([]byte, 32)
+		_, _ = rand.Read(linkSecret)
+	})
+	return linkSecret
+}
+
+// Query encodes the request parameters, excluding the project ID which is
+// part of the path.
+func (req *Request) Query() url.Values {
+	q := url.Values{}
+	q.Set("format", req.Format)
+	if req.Seq != nil {
+		q.Set("seq", strconv.Itoa(int(*req.Seq)))
+	}
+	if len(req.ElementIDs) > 0 {
+		q.Set("elements", strings.Join(req.ElementIDs, ","))
+	}
+	if req.Scale > 0 {
+		q.Set("scale", strconv.FormatFloat(req.Scale, 'f', -1, 64))
+	}
+	return q
+}
+
+// ParseRequest reads an export request from a URL query.
+func ParseRequest(projectID string, q url.Values) (*Request, error) {
+	req := &Request{ProjectID: projectID, Format: q.Get("format")}
+	if req.Format == "" {
+		req.Format = FormatExcalidraw
+	}
+	if _, ok := ContentTypes[req.Format]; !ok {
+		return nil, errors.New("unsupported format")
+	}
+	if v := q.Get("seq"); v != "" {
+		seq, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || seq < 0 {
+			return nil, errors.New("invalid seq")
+		}
+		s := int32(seq)
+		req.Seq = &s
+	}
+	if v := q.Get("elements"); v != "" {
+		req.ElementIDs = strings.Split(v, ",")
+	}
+	if v := q.Get("scale"); v != "" {
+		scale, err := strconv.ParseFloat(v, 64)
+		if err != nil || scale <= 0 || scale > 4 {
+			return nil, errors.New("scale must be between 0 and 4")
+		}
+		req.Scale = scale
+	}
+	return req, nil
+}
+
+// SignedPath returns the path of a download URL for req that needs no other
+// credentials until it expires.
+func (req *Request) SignedPath(now time.Time) string {
+	q := req.Query()
+	q.Set("expires", strconv.FormatInt(now.Add(LinkTTL).Unix(), 10))
+	q.Set("sig", sign(req.ProjectID, q))
+	return "/export/" + url.PathEscape(req.ProjectID) + "?" + q.Encode()
+}
+
+// VerifySignature checks the signature and expiry of a signed download URL.
+func VerifySignature(projectID string, q url.Values, now time.Time) error {
+	expires, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+	if err != nil {
+		return errors.New("invalid export link")
+	}
+	if now.Unix() > expires {
+		return errors.New("export link expired")
+	}
+	signed := url.Values{}
+	for k, v := range q {
+		if k != "sig" {
+			signed[k] = v
+		}
+	}
+	if !hmac.Equal([]byte(sign(projectID, signed)), []byte(q.Get("sig"))) {
+		return errors.New("invalid export link")
+	}
+	return nil
+}
+
+func sign(projectID string, q url.Values) string {
+	mac := hmac.New(sha256.New, secret())
+	mac.Write([]byte(projectID + "?" + q.Encode()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
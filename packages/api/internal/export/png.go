@@ -0,0 +1,233 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// MaxPNGDimension caps the width and height of rendered PNGs; larger scenes
+// are scaled down to fit.
+const MaxPNGDimension = 8192
+
+// joinSegments is how many segments approximate the round joins of strokes.
+const joinSegments = 12
+
+var (
+	textFont     *opentype.Font
+	textFontErr  error
+	textFontOnce sync.Once
+)
+
+// RenderPNG rasterizes the same drawing commands as RenderSVG at the given
+// scale. Text is drawn unrotated with a single bundled font.
+func RenderPNG(scene *Scene, scale float64) ([]byte, error) {
+	x, y, w, h := scene.viewport()
+	if scale <= 0 {
+		scale = 1
+	}
+	if longest := math.Max(w, h) * scale; longest > MaxPNGDimension {
+		scale *= MaxPNGDimension / longest
+	}
+	width, height := int(math.Ceil(w*scale)), int(math.Ceil(h*scale))
+	if width < 1 || height < 1 {
+		width, height = 1, 1
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	c := &canvas{img: img, originX: x, originY: y, scale: scale}
+	for _, sh := range scene.shapes() {
+		if sh.Text != nil {
+			if err := c.text(sh); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if sh.Fill != "" && len(sh.Points) > 2 {
+			c.fill([][][2]float64{sh.Points}, sh.Fill, sh.Opacity)
+		}
+		if sh.Stroke != "" {
+			c.fill(strokeOutline(sh.Points, sh.Closed, sh.StrokeWidth/2), sh.Stroke, sh.Opacity)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode png: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderThumbnail renders the scene as a PNG no larger than size pixels on
+// its longest side. Small drawings are not scaled up.
+func RenderThumbnail(scene *Scene, size float64) ([]byte, error) {
+	_, _, w, h := scene.viewport()
+	return RenderPNG(scene, math.Min(1, size/math.Max(w, h)))
+}
+
+// canvas maps scene coordinates onto the output image.
+type canvas struct {
+	img              *image.RGBA
+	originX, originY float64
+	scale            float64
+}
+
+func (c *canvas) point(p [2]float64) (float32, float32) {
+	return float32((p[0] - c.originX) * c.scale), float32((p[1] - c.originY) * c.scale)
+}
+
+// fill paints the union of the polygons in one pass, so overlapping parts of
+// a stroke are not painted twice.
+func (c *canvas) fill(polygons [][][2]float64, col string, opacity float64) {
+	b := c.img.Bounds()
+	z := vector.NewRasterizer(b.Dx(), b.Dy())
+	for _, poly := range polygons {
+		if len(poly) < 3 {
+			continue
+		}
+		z.MoveTo(c.point(poly[0]))
+		for _, p := range poly[1:] {
+			z.LineTo(c.point(p))
+		}
+		z.ClosePath()
+	}
+	z.Draw(c.img, b, image.NewUniform(parseColor(col, opacity)), image.Point{})
+}
+
+func (c *canvas) text(sh shape) error {
+	textFontOnce.Do(func() {
+		textFont, textFontErr = opentype.Parse(goregular.TTF)
+	})
+	if textFontErr != nil {
+		return fmt.Errorf("failed to load font: %v", textFontErr)
+	}
+
+	el := sh.Text
+	face, err := opentype.NewFace(textFont, &opentype.FaceOptions{
+		Size: el.fontSize() * c.scale,
+		DPI:  72,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load font: %v", err)
+	}
+	defer face.Close()
+
+	d := &font.Drawer{Dst: c.img, Src: image.NewUniform(parseColor(sh.Stroke, sh.Opacity)), Face: face}
+	for i, line := range el.textLines() {
+		x, y := c.point([2]float64{el.textAnchor(), textBaseline(el, i)})
+		advance := d.MeasureString(line)
+		dot := fixed.Point26_6{X: fixed.Int26_6(x * 64), Y: fixed.Int26_6(y * 64)}
+		switch el.TextAlign {
+		case "center":
+			dot.X -= advance / 2
+		case "right":
+			dot.X -= advance
+		}
+		d.Dot = dot
+		d.DrawString(line)
+	}
+	return nil
+}
+
+// strokeOutline turns a polyline into polygons covering a stroke of the given
+// half width with round joins and caps. All polygons share one winding so the
+// rasterizer treats overlaps as a union.
+func strokeOutline(pts [][2]float64, closed bool, halfWidth float64) [][][2]float64 {
+	if closed && len(pts) > 1 {
+		pts = append(pts, pts[0])
+	}
+	var polys [][][2]float64
+	for i, p := range pts {
+		polys = append(polys, circle(p, halfWidth))
+		if i == 0 {
+			continue
+		}
+		q := pts[i-1]
+		dx, dy := p[0]-q[0], p[1]-q[1]
+		length := math.Hypot(dx, dy)
+		if length == 0 {
+			continue
+		}
+		nx, ny := -dy/length*halfWidth, dx/length*halfWidth
+		polys = append(polys, [][2]float64{
+			{q[0] + nx, q[1] + ny}, {p[0] + nx, p[1] + ny},
+			{p[0] - nx, p[1] - ny}, {q[0] - nx, q[1] - ny},
+		})
+	}
+	for _, poly := range polys {
+		if signedArea(poly) < 0 {
+			for i, j := 0, len(poly)-1; i < j; i, j = i+1, j-1 {
+				poly[i], poly[j] = poly[j], poly[i]
+			}
+		}
+	}
+	return polys
+}
+
+func circle(c [2]float64, r float64) [][2]float64 {
+	pts := make([][2]float64, 0, joinSegments)
+	for i := 0; i < joinSegments; i++ {
+		a := float64(i) / joinSegments * 2 * math.Pi
+		pts = append(pts, [2]float64{c[0] + r*math.Cos(a), c[1] + r*math.Sin(a)})
+	}
+	return pts
+}
+
+func signedArea(poly [][2]float64) float64 {
+	var area float64
+	for i, p := range poly {
+		q := poly[(i+1)%len(poly)]
+		area += p[0]*q[1] - q[0]*p[1]
+	}
+	return area / 2
+}
+
+// parseColor understands the hex colors Excalidraw stores plus a few CSS
+// names, falling back to black.
+func parseColor(s string, opacity float64) color.NRGBA {
+	c := color.NRGBA{A: 255}
+	switch strings.ToLower(s) {
+	case "white":
+		c = color.NRGBA{255, 255, 255, 255}
+	case "red":
+		c = color.NRGBA{255, 0, 0, 255}
+	case "green":
+		c = color.NRGBA{0, 128, 0, 255}
+	case "blue":
+		c = color.NRGBA{0, 0, 255, 255}
+	default:
+		hex := strings.TrimPrefix(s, "#")
+		if len(hex) == 3 || len(hex) == 4 {
+			var expanded strings.Builder
+			for _, r := range hex {
+				expanded.WriteString(strings.Repeat(string(r), 2))
+			}
+			hex = expanded.String()
+		}
+		if v, err := strconv.ParseUint(hex, 16, 32); err == nil {
+			switch len(hex) {
+			case 6:
+				c = color.NRGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}
+			case 8:
+				c = color.NRGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}
+			}
+		}
+	}
+	c.A = uint8(math.Round(float64(c.A) * opacity))
+	return c
+}
@@ -0,0 +1,244 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// padding is the empty space kept around the drawing in rendered exports.
+const padding = 16
+
+// Element is the subset of an Excalidraw element needed to render it. Raw
+// keeps the original JSON so .excalidraw exports round-trip unknown fields.
+type Element struct {
+	ID              string       `json:"id"`
+	Type            string       `json:"type"`
+	X               float64      `json:"x"`
+	Y               float64      `json:"y"`
+	Width           float64      `json:"width"`
+	Height          float64      `json:"height"`
+	Angle           float64      `json:"angle"`
+	StrokeColor     string       `json:"strokeColor"`
+	BackgroundColor string       `json:"backgroundColor"`
+	StrokeWidth     float64      `json:"strokeWidth"`
+	Opacity         *float64     `json:"opacity"`
+	Roundness       *struct{}    `json:"roundness"`
+	Points          [][2]float64 `json:"points"`
+	EndArrowhead    *string      `json:"endArrowhead"`
+	StartArrowhead  *string      `json:"startArrowhead"`
+	Text            string       `json:"text"`
+	FontSize        float64      `json:"fontSize"`
+	FontFamily      int          `json:"fontFamily"`
+	TextAlign       string       `json:"textAlign"`
+	LineHeight      float64      `json:"lineHeight"`
+	IsDeleted       bool         `json:"isDeleted"`
+
+	Raw json.RawMessage `json:"-"`
+}
+
+// Scene is the set of visible elements to export, in z-order.
+type Scene struct {
+	Elements []*Element
+}
+
+// NewScene parses an Excalidraw elements array. Deleted elements are dropped,
+// and when ids is non-empty only the listed elements are kept.
+func NewScene(elementsJSON string, ids []string) (*Scene, error) {
+	var raw []json.RawMessage
+	if strings.TrimSpace(elementsJSON) != "" {
+		if err := json.Unmarshal([]byte(elementsJSON), &raw); err != nil {
+			return nil, fmt.Errorf("invalid elements: %v", err)
+		}
+	}
+
+	selected := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		selected[id] = true
+	}
+
+	scene := &Scene{}
+	for _, data := range raw {
+		el := &Element{}
+		if err := json.Unmarshal(data, el); err != nil {
+			continue
+		}
+		if el.IsDeleted || (len(selected) > 0 && !selected[el.ID]) {
+			continue
+		}
+		el.Raw = data
+		scene.Elements = append(scene.Elements, el)
+	}
+	return scene, nil
+}
+
+// Bounds returns the rectangle enclosing every element, rotation included.
+func (s *Scene) Bounds() (minX, minY, maxX, maxY float64) {
+	if len(s.Elements) == 0 {
+		return 0, 0, 0, 0
+	}
+	minX, minY = math.Inf(1), math.Inf(1)
+	maxX, maxY = math.Inf(-1), math.Inf(-1)
+	for _, el := range s.Elements {
+		for _, p := range el.outline() {
+			minX, minY = math.Min(minX, p[0]), math.Min(minY, p[1])
+			maxX, maxY = math.Max(maxX, p[0]), math.Max(maxY, p[1])
+		}
+	}
+	return minX, minY, maxX, maxY
+}
+
+// viewport returns the origin and size of the rendered image.
+func (s *Scene) viewport() (x, y, width, height float64) {
+	minX, minY, maxX, maxY := s.Bounds()
+	return minX - padding, minY - padding, maxX - minX + 2*padding, maxY - minY + 2*padding
+}
+
+// center is the rotation origin of the element.
+func (el *Element) center() (float64, float64) {
+	return el.X + el.Width/2, el.Y + el.Height/2
+}
+
+// rotate turns a point around the element's center by its angle.
+func (el *Element) rotate(p [2]float64) [2]float64 {
+	if el.Angle == 0 {
+		return p
+	}
+	cx, cy := el.center()
+	sin, cos := math.Sincos(el.Angle)
+	dx, dy := p[0]-cx, p[1]-cy
+	return [2]float64{cx + dx*cos - dy*sin, cy + dx*sin + dy*cos}
+}
+
+// outline returns points in scene coordinates covering the element's extent.
+func (el *Element) outline() [][2]float64 {
+	var pts [][2]float64
+	if len(el.Points) > 0 {
+		for _, p := range el.Points {
+			pts = append(pts, [2]float64{el.X + p[0], el.Y + p[1]})
+		}
+	} else {
+		pts = [][2]float64{
+			{el.X, el.Y}, {el.X + el.Width, el.Y},
+			{el.X + el.Width, el.Y + el.Height}, {el.X, el.Y + el.Height},
+		}
+	}
+	for i := range pts {
+		pts[i] = el.rotate(pts[i])
+	}
+	return pts
+}
+
+// strokeWidth falls back to Excalidraw's default of 2.
+func (el *Element) strokeWidth() float64 {
+	if el.StrokeWidth <= 0 {
+		return 2
+	}
+	return el.StrokeWidth
+}
+
+// opacity converts Excalidraw's 0-100 opacity to a 0-1 fraction.
+func (el *Element) opacity() float64 {
+	if el.Opacity == nil {
+		return 1
+	}
+	return math.Max(0, math.Min(100, *el.Opacity)) / 100
+}
+
+// fontSize falls back to Excalidraw's default of 20.
+func (el *Element) fontSize() float64 {
+	if el.FontSize <= 0 {
+		return 20
+	}
+	return el.FontSize
+}
+
+// lineHeight is the text line height as a multiple of the font size.
+func (el *Element) lineHeight() float64 {
+	if el.LineHeight <= 0 {
+		return 1.25
+	}
+	return el.LineHeight
+}
+
+// cornerRadius matches Excalidraw's adaptive radius for rounded rectangles.
+func (el *Element) cornerRadius() float64 {
+	if el.Roundness == nil {
+		return 0
+	}
+	size := math.Min(math.Abs(el.Width), math.Abs(el.Height))
+	if size/4 < 32 {
+		return size / 4
+	}
+	return 32
+}
+
+// hasArrowhead reports whether the given end of a linear element has a head.
+func (el *Element) hasArrowhead(end bool) bool {
+	head := el.StartArrowhead
+	if end {
+		head = el.EndArrowhead
+	}
+	return head != nil && *head != ""
+}
+
+// arrowhead returns the two barbs of an arrowhead at tip, pointing away from
+// the previous point.
+func arrowhead(prev, tip [2]float64, size float64) [2][2]float64 {
+	angle := math.Atan2(tip[1]-prev[1], tip[0]-prev[0])
+	spread := math.Pi / 7
+	return [2][2]float64{
+		{tip[0] - size*math.Cos(angle-spread), tip[1] - size*math.Sin(angle-spread)},
+		{tip[0] - size*math.Cos(angle+spread), tip[1] - size*math.Sin(angle+spread)},
+	}
+}
+
+// linePoints returns the points of a linear element in scene coordinates.
+func (el *Element) linePoints() [][2]float64 {
+	pts := make([][2]float64, 0, len(el.Points))
+	for _, p := range el.Points {
+		pts = append(pts, el.rotate([2]float64{el.X + p[0], el.Y + p[1]}))
+	}
+	return pts
+}
+
+// heads returns the arrowhead barbs to draw for a linear element.
+func (el *Element) heads() [][3][2]float64 {
+	pts := el.linePoints()
+	if len(pts) < 2 {
+		return nil
+	}
+	size := math.Max(10, el.strokeWidth()*5)
+	var heads [][3][2]float64
+	if el.hasArrowhead(true) {
+		barbs := arrowhead(pts[len(pts)-2], pts[len(pts)-1], size)
+		heads = append(heads, [3][2]float64{barbs[0], pts[len(pts)-1], barbs[1]})
+	}
+	if el.hasArrowhead(false) {
+		barbs := arrowhead(pts[1], pts[0], size)
+		heads = append(heads, [3][2]float64{barbs[0], pts[0], barbs[1]})
+	}
+	return heads
+}
+
+// textLines splits the element text into lines.
+func (el *Element) textLines() []string {
+	return strings.Split(strings.ReplaceAll(el.Text, "\r\n", "\n"), "\n")
+}
+
+// textAnchor returns the x coordinate text lines are aligned to.
+func (el *Element) textAnchor() float64 {
+	switch el.TextAlign {
+	case "center":
+		return el.X + el.Width/2
+	case "right":
+		return el.X + el.Width
+	}
+	return el.X
+}
+
+// visibleColor reports whether an Excalidraw color paints anything.
+func visibleColor(c string) bool {
+	return c != "" && c != "transparent"
+}
@@ -0,0 +1,137 @@
+package export
+
+import "math"
+
+// ellipseSegments is how many straight segments approximate an ellipse.
+const ellipseSegments = 64
+
+// cornerSegments is how many straight segments approximate a rounded corner.
+const cornerSegments = 8
+
+// shape is one drawing command shared by the SVG and PNG renderers, so both
+// formats produce the same picture. Points are in scene coordinates with the
+// element's rotation already applied.
+type shape struct {
+	Points      [][2]float64
+	Closed      bool
+	Fill        string
+	Stroke      string
+	StrokeWidth float64
+	Opacity     float64
+
+	// Text shapes are drawn at the element's position instead of Points.
+	Text *Element
+}
+
+// shapes returns the drawing commands for every element in z-order.
+func (s *Scene) shapes() []shape {
+	var out []shape
+	for _, el := range s.Elements {
+		out = append(out, el.shapes()...)
+	}
+	return out
+}
+
+// shapes converts an element into drawing commands. Unsupported element
+// types, such as images and frames, are skipped.
+func (el *Element) shapes() []shape {
+	base := shape{
+		Stroke:      el.StrokeColor,
+		StrokeWidth: el.strokeWidth(),
+		Opacity:     el.opacity(),
+	}
+	if !visibleColor(base.Stroke) {
+		base.Stroke = ""
+	}
+
+	switch el.Type {
+	case "rectangle", "ellipse", "diamond":
+		var pts [][2]float64
+		switch el.Type {
+		case "rectangle":
+			pts = roundedRect(el.X, el.Y, el.Width, el.Height, el.cornerRadius())
+		case "ellipse":
+			pts = ellipse(el.X, el.Y, el.Width, el.Height)
+		default:
+			pts = [][2]float64{
+				{el.X + el.Width/2, el.Y}, {el.X + el.Width, el.Y + el.Height/2},
+				{el.X + el.Width/2, el.Y + el.Height}, {el.X, el.Y + el.Height/2},
+			}
+		}
+		for i := range pts {
+			pts[i] = el.rotate(pts[i])
+		}
+		sh := base
+		sh.Points, sh.Closed = pts, true
+		if visibleColor(el.BackgroundColor) {
+			sh.Fill = el.BackgroundColor
+		}
+		return []shape{sh}
+
+	case "line", "arrow", "freedraw":
+		pts := el.linePoints()
+		if len(pts) == 0 {
+			return nil
+		}
+		sh := base
+		sh.Points = pts
+		closed := len(pts) > 2 && pts[0] == pts[len(pts)-1]
+		if closed && el.Type != "arrow" && visibleColor(el.BackgroundColor) {
+			sh.Fill = el.BackgroundColor
+		}
+		out := []shape{sh}
+		for _, head := range el.heads() {
+			barbs := base
+			barbs.Points = head[:]
+			out = append(out, barbs)
+		}
+		return out
+
+	case "text":
+		if el.Text == "" || base.Stroke == "" {
+			return nil
+		}
+		sh := base
+		sh.Text = el
+		return []shape{sh}
+	}
+	return nil
+}
+
+// roundedRect outlines a rectangle whose corners are rounded by radius r.
+func roundedRect(x, y, w, h, r float64) [][2]float64 {
+	if w < 0 {
+		x, w = x+w, -w
+	}
+	if h < 0 {
+		y, h = y+h, -h
+	}
+	if r <= 0 {
+		return [][2]float64{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}}
+	}
+	corners := [4][3]float64{
+		{x + w - r, y + r, -math.Pi / 2},
+		{x + w - r, y + h - r, 0},
+		{x + r, y + h - r, math.Pi / 2},
+		{x + r, y + r, math.Pi},
+	}
+	pts := make([][2]float64, 0, 4*(cornerSegments+1))
+	for _, c := range corners {
+		for i := 0; i <= cornerSegments; i++ {
+			a := c[2] + float64(i)/cornerSegments*math.Pi/2
+			pts = append(pts, [2]float64{c[0] + r*math.Cos(a), c[1] + r*math.Sin(a)})
+		}
+	}
+	return pts
+}
+
+// ellipse outlines the ellipse inscribed in the given box.
+func ellipse(x, y, w, h float64) [][2]float64 {
+	cx, cy := x+w/2, y+h/2
+	pts := make([][2]float64, 0, ellipseSegments)
+	for i := 0; i < ellipseSegments; i++ {
+		a := float64(i) / ellipseSegments * 2 * math.Pi
+		pts = append(pts, [2]float64{cx + w/2*math.Cos(a), cy + h/2*math.Sin(a)})
+	}
+	return pts
+}
@@ -0,0 +1,123 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"math"
+	"strings"
+)
+
+// fontFamilies maps Excalidraw font family IDs to CSS font stacks.
+var fontFamilies = map[int]string{
+	1: "Virgil, Segoe UI Emoji",
+	2: "Helvetica, Arial, sans-serif",
+	3: "Cascadia, Consolas, monospace",
+}
+
+// RenderSVG draws the scene as a standalone SVG document on a white background.
+func RenderSVG(scene *Scene) []byte {
+	x, y, w, h := scene.viewport()
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="%s" height="%s" viewBox="%s %s %s %s">`,
+		num(w), num(h), num(x), num(y), num(w), num(h))
+	buf.WriteString("\n")
+	fmt.Fprintf(&buf, `<rect x="%s" y="%s" width="%s" height="%s" fill="#ffffff"/>`, num(x), num(y), num(w), num(h))
+	buf.WriteString("\n")
+
+	for _, sh := range scene.shapes() {
+		if sh.Text != nil {
+			writeSVGText(&buf, sh)
+			continue
+		}
+		writeSVGPath(&buf, sh)
+	}
+
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
+
+func writeSVGPath(buf *bytes.Buffer, sh shape) {
+	if len(sh.Points) == 0 || (sh.Fill == "" && sh.Stroke == "") {
+		return
+	}
+	var d strings.Builder
+	for i, p := range sh.Points {
+		if i == 0 {
+			d.WriteString("M")
+		} else {
+			d.WriteString(" L")
+		}
+		d.WriteString(num(p[0]) + " " + num(p[1]))
+	}
+	if sh.Closed {
+		d.WriteString(" Z")
+	}
+
+	fill, stroke := "none", "none"
+	if sh.Fill != "" {
+		fill = attr(sh.Fill)
+	}
+	if sh.Stroke != "" {
+		stroke = attr(sh.Stroke)
+	}
+	fmt.Fprintf(buf, `<path d="%s" fill="%s" stroke="%s" stroke-width="%s" stroke-linecap="round" stroke-linejoin="round"`,
+		d.String(), fill, stroke, num(sh.StrokeWidth))
+	if sh.Opacity < 1 {
+		fmt.Fprintf(buf, ` opacity="%s"`, num(sh.Opacity))
+	}
+	buf.WriteString("/>\n")
+}
+
+func writeSVGText(buf *bytes.Buffer, sh shape) {
+	el := sh.Text
+	anchor := "start"
+	switch el.TextAlign {
+	case "center":
+		anchor = "middle"
+	case "right":
+		anchor = "end"
+	}
+	family, ok := fontFamilies[el.FontFamily]
+	if !ok {
+		family = fontFamilies[1]
+	}
+
+	fmt.Fprintf(buf, `<text font-family="%s" font-size="%s" fill="%s" text-anchor="%s" style="white-space: pre"`,
+		attr(family), num(el.fontSize()), attr(sh.Stroke), anchor)
+	if sh.Opacity < 1 {
+		fmt.Fprintf(buf, ` opacity="%s"`, num(sh.Opacity))
+	}
+	if el.Angle != 0 {
+		cx, cy := el.center()
+		fmt.Fprintf(buf, ` transform="rotate(%s %s %s)"`, num(el.Angle*180/math.Pi), num(cx), num(cy))
+	}
+	buf.WriteString(">")
+	x := el.textAnchor()
+	for i, line := range el.textLines() {
+		fmt.Fprintf(buf, `<tspan x="%s" y="%s">%s</tspan>`, num(x), num(textBaseline(el, i)), html.EscapeString(line))
+	}
+	buf.WriteString("</text>\n")
+}
+
+// textBaseline returns the y coordinate of the baseline of the given line.
+func textBaseline(el *Element, line int) float64 {
+	size := el.fontSize()
+	return el.Y + float64(line)*size*el.lineHeight() + size
+}
+
+// num formats a coordinate compactly.
+func num(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// attr escapes a value for use inside a double-quoted attribute.
+func attr(v string) string {
+	return html.EscapeString(v)
+}
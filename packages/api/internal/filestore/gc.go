@@ -0,0 +1,115 @@
+package filestore
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	collectInterval = time.Hour
+	// collectGracePeriod protects recent uploads whose image elements have not
+	// reached the op log yet.
+	collectGracePeriod = 24 * time.Hour
+)
+
+// StartCollector periodically removes files no longer referenced by any
+// version of their project.
+func (s *Service) StartCollector() {
+	go func() {
+		for {
+			if err := s.Collect(context.Background(), time.Now().Add(-collectGracePeriod)); err != nil {
+				log.Printf("Warning: file garbage collection failed: %v", err)
+			}
+			time.Sleep(collectInterval)
+		}
+	}()
+}
+
+// Collect unlinks files created before the given time that no element in the
+// project's current state or op log refers to, then deletes blobs no file
+// links to anymore.
+func (s *Service) Collect(ctx context.Context, before time.Time) error {
+	projectIDs, err := s.repo.File.GetProjectIDs(ctx)
+	if err != nil {
+		return err
+	}
+	cutoff := before.UTC().Format(time.RFC3339)
+	for _, projectID := range projectIDs {
+		referenced, err := s.referencedFileIDs(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		files, err := s.repo.File.GetFilesByProject(ctx, projectID)
+		if err != nil {
+			return err
+		}
+		var unused []bson.ObjectID
+		for _, file := range files {
+			if !referenced[file.FileID] && file.CreatedAt < cutoff {
+				unused = append(unused, file.ID)
+			}
+		}
+		if err := s.repo.File.DeleteFiles(ctx, unused); err != nil {
+			return err
+		}
+	}
+
+	blobs, err := s.repo.File.GetOrphanedBlobs(ctx, before)
+	if err != nil {
+		return err
+	}
+	for _, blob := range blobs {
+		// Skip blobs an upload started using since they were listed
+		deleted, err := s.repo.File.DeleteBlob(ctx, blob.Hash, before)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			continue
+		}
+		if err := s.store.Delete(ctx, blob.Hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// referencedFileIDs collects the fileIds used by the project's op log and its
+// current elements, which may have been overwritten outside the op log.
+// Trashed projects keep their files until they are purged.
+func (s *Service) referencedFileIDs(ctx context.Context, projectID bson.ObjectID) (map[string]bool, error) {
+	referenced, err := s.repo.Operation.GetReferencedFileIDs(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	project, err := s.repo.Project.GetProject(ctx, projectID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		project, err = s.repo.Project.GetTrashedProject(ctx, projectID.Hex())
+		if err != nil {
+			return nil, err
+		}
+	}
+	if project == nil || project.Elements == "" {
+		return referenced, nil
+	}
+
+	var elements []struct {
+		FileID string `json:"fileId"`
+	}
+	if err := json.Unmarshal([]byte(project.Elements), &elements); err != nil {
+		return referenced, nil
+	}
+	for _, el := range elements {
+		if el.FileID != "" {
+			referenced[el.FileID] = true
+		}
+	}
+	return referenced, nil
+}
@@ -0,0 +1,54 @@
+package filestore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// GridFSStore keeps blobs in a MongoDB GridFS bucket, using the hash as the
+// GridFS file ID.
+type GridFSStore struct {
+	bucket *mongo.GridFSBucket
+}
+
+func NewGridFSStore(database *mongo.Database, bucketName string) *GridFSStore {
+	return &GridFSStore{
+		bucket: database.GridFSBucket(options.GridFSBucket().SetName(bucketName)),
+	}
+}
+
+func (s *GridFSStore) Put(ctx context.Context, hash string, data []byte) error {
+	// GridFS does not enforce unique file IDs, so skip contents already stored
+	stream, err := s.bucket.OpenDownloadStream(ctx, hash)
+	if err == nil {
+		return stream.Close()
+	}
+	if !errors.Is(err, mongo.ErrFileNotFound) {
+		return err
+	}
+	return s.bucket.UploadFromStreamWithID(ctx, hash, hash, bytes.NewReader(data))
+}
+
+func (s *GridFSStore) Open(ctx context.Context, hash string) (io.ReadCloser, error) {
+	stream, err := s.bucket.OpenDownloadStream(ctx, hash)
+	if errors.Is(err, mongo.ErrFileNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+func (s *GridFSStore) Delete(ctx context.Context, hash string) error {
+	err := s.bucket.Delete(ctx, hash)
+	if errors.Is(err, mongo.ErrFileNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,189 @@
+package filestore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"github.com/chirag3003/collab-draw-backend/internal/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// defaultMaxFileSizeMB applies when FILE_MAX_SIZE_MB is unset.
+const defaultMaxFileSizeMB = 10
+
+// maxFileIDLength bounds the Excalidraw fileId, which is normally a hash.
+const maxFileIDLength = 128
+
+var (
+	ErrTooLarge        = errors.New("file is too large")
+	ErrUnsupportedType = errors.New("unsupported file type")
+	ErrInvalidFileID   = errors.New("invalid file ID")
+	ErrFileIDConflict  = errors.New("file ID is already used for different contents")
+)
+
+// allowedTypes are the image types Excalidraw can embed.
+var allowedTypes = map[string]bool{
+	"image/png":     true,
+	"image/jpeg":    true,
+	"image/gif":     true,
+	"image/webp":    true,
+	"image/bmp":     true,
+	"image/x-icon":  true,
+	"image/svg+xml": true,
+}
+
+// Service stores the files of projects. Contents are deduplicated by their
+// hash, so a blob is shared by every project that uploads the same bytes.
+type Service struct {
+	repo  *repository.Repository
+	store BlobStore
+}
+
+func NewService(repo *repository.Repository, store BlobStore) *Service {
+	return &Service{repo: repo, store: store}
+}
+
+// MaxFileSize returns the largest accepted upload in bytes, as configured by
+// FILE_MAX_SIZE_MB.
+func MaxFileSize() int64 {
+	size := defaultMaxFileSizeMB
+	if v := os.Getenv("FILE_MAX_SIZE_MB"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			log.Printf("Warning: invalid FILE_MAX_SIZE_MB %q, using %d", v, defaultMaxFileSizeMB)
+		} else {
+			size = parsed
+		}
+	}
+	return int64(size) << 20
+}
+
+// Upload stores the contents of an Excalidraw file for a project. Uploading
+// the same contents under the same fileId again returns the existing file.
+func (s *Service) Upload(ctx context.Context, projectID bson.ObjectID, fileID string, declaredType string, body io.Reader, userID string) (*models.File, error) {
+	if fileID == "" || len(fileID) > maxFileIDLength || strings.ContainsAny(fileID, "/\\") {
+		return nil, ErrInvalidFileID
+	}
+	limit := MaxFileSize()
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %v", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, ErrTooLarge
+	}
+	mimeType, err := detectType(data, declaredType)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	existing, err := s.repo.File.GetFile(ctx, projectID, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.Hash != hash {
+			return nil, ErrFileIDConflict
+		}
+		return existing, nil
+	}
+
+	if err := s.putBlob(ctx, hash, data, mimeType); err != nil {
+		return nil, err
+	}
+
+	file := &models.File{
+		ProjectID: projectID,
+		FileID:    fileID,
+		Hash:      hash,
+		MimeType:  mimeType,
+		Size:      int64(len(data)),
+		CreatedBy: userID,
+	}
+	if err := s.repo.File.CreateFile(ctx, file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// Open returns a project's file and a reader for its contents, or nil when the
+// project has no such file.
+func (s *Service) Open(ctx context.Context, projectID bson.ObjectID, fileID string) (*models.File, io.ReadCloser, error) {
+	file, err := s.repo.File.GetFile(ctx, projectID, fileID)
+	if err != nil || file == nil {
+		return nil, nil, err
+	}
+	contents, err := s.store.Open(ctx, file.Hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, contents, nil
+}
+
+// PutBlob stores generated contents, such as thumbnails, that are not linked
+// through a project file, and returns their hash. Whatever refers to the hash
+// must be saved promptly, before garbage collection's grace period runs out.
+func (s *Service) PutBlob(ctx context.Context, data []byte, mimeType string) (string, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	if err := s.putBlob(ctx, hash, data, mimeType); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// OpenBlob returns a reader for stored contents.
+func (s *Service) OpenBlob(ctx context.Context, hash string) (io.ReadCloser, error) {
+	return s.store.Open(ctx, hash)
+}
+
+func (s *Service) putBlob(ctx context.Context, hash string, data []byte, mimeType string) error {
+	// Mark the blob as used before linking to it so garbage collection does
+	// not remove it in between. Stores skip contents they already have.
+	if err := s.repo.File.TouchBlob(ctx, &models.Blob{Hash: hash, MimeType: mimeType, Size: int64(len(data))}); err != nil {
+		return err
+	}
+	if err := s.store.Put(ctx, hash, data); err != nil {
+		return fmt.Errorf("failed to store file: %v", err)
+	}
+	return nil
+}
+
+// detectType sniffs the contents rather than trusting the declared type. SVG
+// cannot be sniffed reliably, so it is accepted when declared and the
+// contents look like markup.
+func detectType(data []byte, declaredType string) (string, error) {
+	sniffed := http.DetectContentType(data)
+	if allowedTypes[sniffed] {
+		return sniffed, nil
+	}
+	declared := strings.TrimSpace(strings.Split(declaredType, ";")[0])
+	if declared == "image/svg+xml" && bytes.Contains(bytes.ToLower(data[:min(len(data), 1024)]), []byte("<svg")) {
+		return declared, nil
+	}
+	return "", ErrUnsupportedType
+}
+
+// validHash reports whether s is a hex SHA-256 hash, which keeps blob keys
+// safe to use in file paths.
+func validHash(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
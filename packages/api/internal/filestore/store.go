@@ -0,0 +1,42 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chirag3003/collab-draw-backend/internal/config"
+	"github.com/chirag3003/collab-draw-backend/internal/db"
+)
+
+// ErrNotFound is returned when a blob is not in the store.
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore keeps file contents addressed by the SHA-256 hash of their bytes.
+// Writing the same hash twice is harmless and deleting a missing blob is not
+// an error.
+type BlobStore interface {
+	Put(ctx context.Context, hash string, data []byte) error
+	Open(ctx context.Context, hash string) (io.ReadCloser, error)
+	Delete(ctx context.Context, hash string) error
+}
+
+// NewStoreFromEnv creates the blob store selected by FILE_STORE: "gridfs"
+// (the default) keeps blobs in MongoDB, "local" keeps them on disk under
+// FILE_STORE_DIR.
+func NewStoreFromEnv() (BlobStore, error) {
+	switch os.Getenv("FILE_STORE") {
+	case "", "gridfs":
+		return NewGridFSStore(db.GetDatabase(), config.BLOB_BUCKET), nil
+	case "local":
+		dir := os.Getenv("FILE_STORE_DIR")
+		if dir == "" {
+			dir = "data/files"
+		}
+		return NewLocalStore(dir)
+	default:
+		return nil, fmt.Errorf("unknown FILE_STORE %q", os.Getenv("FILE_STORE"))
+	}
+}
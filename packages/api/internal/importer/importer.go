@@ -0,0 +1,384 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/chirag3003/collab-draw-backend/internal/element"
+)
+
+const (
+	// MaxFileSize caps a single uploaded file, zip archives included.
+	MaxFileSize = 50 << 20
+	// maxZipEntries caps how many files a bulk import may contain.
+	maxZipEntries = 500
+	// maxZipTotalSize caps the uncompressed size of a zip archive.
+	maxZipTotalSize = 200 << 20
+)
+
+// Kinds of importable files, decided by their extension.
+const (
+	KindDrawing = "drawing"
+	KindLibrary = "library"
+	KindZip     = "zip"
+)
+
+// File is one file taken from an upload or a zip archive.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Drawing is a validated .excalidraw file.
+type Drawing struct {
+	Elements []Element
+}
+
+// Element is a normalized Excalidraw element ready to be stored.
+type Element struct {
+	ID      string
+	Version int
+	Data    string
+}
+
+// Library is a validated .excalidrawlib file.
+type Library struct {
+	Items []LibraryItem
+}
+
+// LibraryItem is one reusable group of elements from a library.
+type LibraryItem struct {
+	ID       string
+	Name     string
+	Tags     []string
+	Elements string
+}
+
+// KindOf returns the kind of file based on its extension, or "" when it is not
+// importable.
+func KindOf(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".excalidraw":
+		return KindDrawing
+	case ".excalidrawlib":
+		return KindLibrary
+	case ".zip":
+		return KindZip
+	}
+	return ""
+}
+
+// BaseName returns the file name without directories and extension, used as
+// the default name of imported projects and libraries.
+func BaseName(name string) string {
+	base := path.Base(strings.ReplaceAll(name, "\\", "/"))
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+// ReadAll reads an upload, failing when it exceeds MaxFileSize.
+func ReadAll(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxFileSize {
+		return nil, fmt.Errorf("file is larger than %d MB", MaxFileSize>>20)
+	}
+	return data, nil
+}
+
+// ParseDrawing validates a .excalidraw document and normalizes its elements.
+// Deleted elements are dropped and elements are given the fields Excalidraw
+// requires when they are missing. Embedded image files are not imported.
+func ParseDrawing(data []byte) (*Drawing, error) {
+	var doc struct {
+		Type     string            `json:"type"`
+		Elements []json.RawMessage `json:"elements"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("not a valid .excalidraw file: %v", err)
+	}
+	if doc.Type != "excalidraw" {
+		return nil, errors.New("not a valid .excalidraw file: missing type \"excalidraw\"")
+	}
+
+	elements, err := normalizeElements(doc.Elements)
+	if err != nil {
+		return nil, err
+	}
+	drawing := &Drawing{}
+	for _, el := range elements {
+		encoded, err := json.Marshal(el)
+		if err != nil {
+			return nil, err
+		}
+		version, _ := el["version"].(float64)
+		drawing.Elements = append(drawing.Elements, Element{
+			ID:      el["id"].(string),
+			Version: int(version),
+			Data:    string(encoded),
+		})
+	}
+	return drawing, nil
+}
+
+// ParseLibrary validates a .excalidrawlib document. Version 1 libraries, which
+// store bare element lists, are converted to version 2 library items.
+func ParseLibrary(data []byte) (*Library, error) {
+	type item struct {
+		ID       string            `json:"id"`
+		Name     string            `json:"name"`
+		Tags     []string          `json:"tags"`
+		Elements []json.RawMessage `json:"elements"`
+	}
+	var doc struct {
+		Type         string              `json:"type"`
+		LibraryItems []item              `json:"libraryItems"`
+		Library      [][]json.RawMessage `json:"library"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("not a valid .excalidrawlib file: %v", err)
+	}
+	if doc.Type != "excalidrawlib" {
+		return nil, errors.New("not a valid .excalidrawlib file: missing type \"excalidrawlib\"")
+	}
+
+	items := doc.LibraryItems
+	for _, elements := range doc.Library {
+		items = append(items, item{Elements: elements})
+	}
+
+	library := &Library{}
+	for i, it := range items {
+		if len(it.Elements) == 0 {
+			return nil, fmt.Errorf("library item %d has no elements", i)
+		}
+		normalized, err := normalizeElements(it.Elements)
+		if err != nil {
+			return nil, fmt.Errorf("library item %d: %v", i, err)
+		}
+		encoded, err := json.Marshal(normalized)
+		if err != nil {
+			return nil, err
+		}
+		library.Items = append(library.Items, LibraryItem{
+			ID:       it.ID,
+			Name:     it.Name,
+			Tags:     it.Tags,
+			Elements: string(encoded),
+		})
+	}
+	return library, nil
+}
+
+// NormalizeElements validates a JSON array of elements the same way imported
+// files are, returning the normalized array.
+func NormalizeElements(elementsJSON string) (string, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte(elementsJSON), &raw); err != nil {
+		return "", fmt.Errorf("elements must be a JSON array: %v", err)
+	}
+	if len(raw) == 0 {
+		return "", errors.New("elements must not be empty")
+	}
+	normalized, err := normalizeElements(raw)
+	if err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(normalized)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// ReadZip extracts the importable files of a zip archive. Directories, other
+// file types and macOS metadata are skipped.
+func ReadZip(data []byte) ([]File, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not a valid zip archive: %v", err)
+	}
+
+	var files []File
+	var total int64
+	for _, entry := range reader.File {
+		name := entry.Name
+		if entry.FileInfo().IsDir() || strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), ".") {
+			continue
+		}
+		kind := KindOf(name)
+		if kind != KindDrawing && kind != KindLibrary {
+			continue
+		}
+		if len(files) == maxZipEntries {
+			return nil, fmt.Errorf("archive contains more than %d files", maxZipEntries)
+		}
+
+		rc, err := entry.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", name, err)
+		}
+		// Limit what is actually decompressed rather than trusting the header
+		content, err := io.ReadAll(io.LimitReader(rc, maxZipTotalSize-total+1))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", name, err)
+		}
+		total += int64(len(content))
+		if total > maxZipTotalSize {
+			return nil, fmt.Errorf("archive is larger than %d MB uncompressed", maxZipTotalSize>>20)
+		}
+		files = append(files, File{Name: name, Data: content})
+	}
+	return files, nil
+}
+
+// normalizeElements validates elements and fills in defaults. Element IDs must
+// be unique; deleted elements are dropped.
+func normalizeElements(raw []json.RawMessage) ([]map[string]any, error) {
+	seen := make(map[string]bool, len(raw))
+	elements := make([]map[string]any, 0, len(raw))
+	for i, data := range raw {
+		var el map[string]any
+		if err := json.Unmarshal(data, &el); err != nil || el == nil {
+			return nil, fmt.Errorf("element %d is not an object", i)
+		}
+		id, _ := el["id"].(string)
+		if id == "" {
+			return nil, fmt.Errorf("element %d has no id", i)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("duplicate element id %q", id)
+		}
+		seen[id] = true
+		elType, _ := el["type"].(string)
+		if !element.IsKnownType(elType) {
+			return nil, fmt.Errorf("element %q has unsupported type %q", id, elType)
+		}
+		for _, field := range []string{"x", "y"} {
+			if _, ok := el[field].(float64); !ok {
+				return nil, fmt.Errorf("element %q has no numeric %s", id, field)
+			}
+		}
+		if deleted, _ := el["isDeleted"].(bool); deleted {
+			continue
+		}
+
+		setDefault(el, "version", float64(1))
+		setDefault(el, "versionNonce", float64(0))
+		setDefault(el, "isDeleted", false)
+		setDefault(el, "width", float64(0))
+		setDefault(el, "height", float64(0))
+		setDefault(el, "angle", float64(0))
+		setDefault(el, "strokeColor", "#1e1e1e")
+		setDefault(el, "backgroundColor", "transparent")
+		setDefault(el, "strokeWidth", float64(2))
+		setDefault(el, "opacity", float64(100))
+		setDefault(el, "groupIds", []any{})
+		elements = append(elements, el)
+	}
+	return elements, nil
+}
+
+func setDefault(el map[string]any, key string, value any) {
+	if _, ok := el[key]; !ok {
+		el[key] = value
+	}
+}
+
+// CopyElements prepares a project's elements to seed a new project. Every
+// element and group gets a fresh ID, and the references between elements
+// (containers, frames, bindings) are rewritten to match. Deleted elements are
+// dropped and versions restart at 1.
+func CopyElements(elementsJSON string) ([]Element, error) {
+	if strings.TrimSpace(elementsJSON) == "" {
+		return nil, nil
+	}
+	var raw []map[string]any
+	if err := json.Unmarshal([]byte(elementsJSON), &raw); err != nil {
+		return nil, fmt.Errorf("elements must be a JSON array: %v", err)
+	}
+
+	ids := make(map[string]string, len(raw))
+	for _, el := range raw {
+		if id, _ := el["id"].(string); id != "" {
+			ids[id] = newID()
+		}
+	}
+	groups := make(map[string]string)
+	remap := func(el map[string]any, key string) {
+		if id, ok := el[key].(string); ok {
+			if mapped, ok := ids[id]; ok {
+				el[key] = mapped
+			} else {
+				// Dangling references would point at nothing in the copy
+				el[key] = nil
+			}
+		}
+	}
+
+	elements := make([]Element, 0, len(raw))
+	for _, el := range raw {
+		id, _ := el["id"].(string)
+		if el == nil || id == "" {
+			continue
+		}
+		if deleted, _ := el["isDeleted"].(bool); deleted {
+			continue
+		}
+		el["id"] = ids[id]
+		el["version"] = float64(1)
+		remap(el, "containerId")
+		remap(el, "frameId")
+		for _, key := range []string{"startBinding", "endBinding"} {
+			if binding, ok := el[key].(map[string]any); ok {
+				remap(binding, "elementId")
+			}
+		}
+		if bound, ok := el["boundElements"].([]any); ok {
+			kept := make([]any, 0, len(bound))
+			for _, b := range bound {
+				if ref, ok := b.(map[string]any); ok {
+					refID, _ := ref["id"].(string)
+					if mapped, ok := ids[refID]; ok {
+						ref["id"] = mapped
+						kept = append(kept, ref)
+					}
+				}
+			}
+			el["boundElements"] = kept
+		}
+		if groupIDs, ok := el["groupIds"].([]any); ok {
+			for i, g := range groupIDs {
+				group, _ := g.(string)
+				if _, ok := groups[group]; !ok {
+					groups[group] = newID()
+				}
+				groupIDs[i] = groups[group]
+			}
+		}
+
+		encoded, err := json.Marshal(el)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, Element{ID: ids[id], Version: 1, Data: string(encoded)})
+	}
+	return elements, nil
+}
+
+// newID returns a random element ID in the same alphabet Excalidraw uses.
+func newID() string {
+	b := make([]byte, 15)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
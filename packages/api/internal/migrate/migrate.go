@@ -0,0 +1,73 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/repository"
+)
+
+// lease is how long a claimed migration is left to an instance before
+// another one may take it over.
+const lease = 10 * time.Minute
+
+type migration struct {
+	name string
+	run  func(ctx context.Context, repo *repository.Repository) error
+}
+
+// migrations run in order, each once per database.
+var migrations = []migration{
+	{name: "strip_inherited_project_members", run: stripInheritedProjectMembers},
+	{name: "backfill_op_times", run: backfillOpTimes},
+}
+
+// Run applies the migrations that have not run yet. It is called before the
+// server accepts requests, since some migrations close access gaps.
+func Run(ctx context.Context, repo *repository.Repository) error {
+	for _, m := range migrations {
+		claimed, err := repo.Migration.Claim(ctx, m.name, lease)
+		if err != nil {
+			return fmt.Errorf("failed to claim migration %s: %v", m.name, err)
+		}
+		if !claimed {
+			continue
+		}
+		log.Printf("Running migration %s", m.name)
+		if err := m.run(ctx, repo); err != nil {
+			if releaseErr := repo.Migration.Release(ctx, m.name); releaseErr != nil {
+				log.Printf("Warning: failed to release migration %s: %v", m.name, releaseErr)
+			}
+			return fmt.Errorf("migration %s failed: %v", m.name, err)
+		}
+		if err := repo.Migration.Complete(ctx, m.name); err != nil {
+			return fmt.Errorf("failed to record migration %s: %v", m.name, err)
+		}
+	}
+	return nil
+}
+
+// stripInheritedProjectMembers drops the workspace members that projects
+// created before project-level membership copied into their member list, so
+// that only explicit grants remain there.
+func stripInheritedProjectMembers(ctx context.Context, repo *repository.Repository) error {
+	changed, err := repo.Workspace.StripInheritedProjectMembers(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("Removed inherited workspace members from %d projects", changed)
+	return nil
+}
+
+// backfillOpTimes gives ops logged before they carried a date one, so that
+// activity queries can select them by time.
+func backfillOpTimes(ctx context.Context, repo *repository.Repository) error {
+	changed, err := repo.Operation.BackfillOpTimes(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("Backfilled the date of %d ops", changed)
+	return nil
+}
@@ -0,0 +1,96 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/repository"
+)
+
+type fakeMigrations struct {
+	claimed   map[string]bool
+	completed map[string]bool
+}
+
+func (f *fakeMigrations) Claim(ctx context.Context, name string, lease time.Duration) (bool, error) {
+	if f.claimed[name] || f.completed[name] {
+		return false, nil
+	}
+	f.claimed[name] = true
+	return true, nil
+}
+
+func (f *fakeMigrations) Complete(ctx context.Context, name string) error {
+	f.completed[name] = true
+	return nil
+}
+
+func (f *fakeMigrations) Release(ctx context.Context, name string) error {
+	delete(f.claimed, name)
+	return nil
+}
+
+type fakeWorkspaces struct {
+	repository.WorkspaceRepository
+	runs int
+	err  error
+}
+
+func (f *fakeWorkspaces) StripInheritedProjectMembers(ctx context.Context) (int64, error) {
+	f.runs++
+	return 0, f.err
+}
+
+type fakeOperations struct {
+	repository.OperationRepository
+	runs int
+}
+
+func (f *fakeOperations) BackfillOpTimes(ctx context.Context) (int64, error) {
+	f.runs++
+	return 0, nil
+}
+
+func TestRunAppliesOnce(t *testing.T) {
+	migrations := &fakeMigrations{claimed: map[string]bool{}, completed: map[string]bool{}}
+	workspaces := &fakeWorkspaces{}
+	operations := &fakeOperations{}
+	repo := &repository.Repository{Migration: migrations, Workspace: workspaces, Operation: operations}
+
+	for range 2 {
+		if err := Run(context.Background(), repo); err != nil {
+			t.Fatalf("Run() = %v", err)
+		}
+	}
+	if workspaces.runs != 1 || operations.runs != 1 {
+		t.Errorf("migrations ran %d and %d times, want 1", workspaces.runs, operations.runs)
+	}
+	for _, name := range []string{"strip_inherited_project_members", "backfill_op_times"} {
+		if !migrations.completed[name] {
+			t.Errorf("migration %s was not recorded as completed", name)
+		}
+	}
+}
+
+func TestRunReleasesFailedMigration(t *testing.T) {
+	migrations := &fakeMigrations{claimed: map[string]bool{}, completed: map[string]bool{}}
+	workspaces := &fakeWorkspaces{err: errors.New("connection reset")}
+	operations := &fakeOperations{}
+	repo := &repository.Repository{Migration: migrations, Workspace: workspaces, Operation: operations}
+
+	if err := Run(context.Background(), repo); err == nil {
+		t.Fatal("Run() succeeded, want the migration error")
+	}
+	workspaces.err = nil
+	if err := Run(context.Background(), repo); err != nil {
+		t.Fatalf("retry: Run() = %v", err)
+	}
+	if workspaces.runs != 2 || !migrations.completed["strip_inherited_project_members"] {
+		t.Errorf("migration ran %d times, completed %v; want a successful retry", workspaces.runs, migrations.completed)
+	}
+	if operations.runs != 1 {
+		t.Errorf("later migration ran %d times, want once after the retry", operations.runs)
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+import "go.mongodb.org/mongo-driver/v2/bson"
+
+// AccessToken is a personal access token used for scripting against the API.
+// Only the SHA-256 hash of the token is stored; the plain value is shown once
+// when the token is created.
+type AccessToken struct {
+	ID                bson.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Name              string         `bson:"name" json:"name"`
+	Prefix            string         `bson:"prefix" json:"prefix"`
+	TokenHash         string         `bson:"token_hash" json:"-"`
+	UserID            string         `bson:"user_id" json:"userId"`
+	Email             string         `bson:"email" json:"email"`
+	FullName          string         `bson:"full_name" json:"fullName"`
+	PreferredUsername string         `bson:"preferred_username" json:"preferredUsername"`
+	ProjectID         *bson.ObjectID `bson:"project_id,omitempty" json:"projectId,omitempty"`
+	WorkspaceID       *bson.ObjectID `bson:"workspace_id,omitempty" json:"workspaceId,omitempty"`
+	ReadOnly          bool           `bson:"read_only" json:"readOnly"`
+	ExpiresAt         string         `bson:"expires_at,omitempty" json:"expiresAt,omitempty"`
+	LastUsedAt        string         `bson:"last_used_at,omitempty" json:"lastUsedAt,omitempty"`
+	RevokedAt         string         `bson:"revoked_at,omitempty" json:"revokedAt,omitempty"`
+	CreatedAt         string         `bson:"created_at" json:"createdAt"`
+}
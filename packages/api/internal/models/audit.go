@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// AuditEntry records a security-relevant or destructive action. Entries are
+// append-only.
+type AuditEntry struct {
+	ID          bson.ObjectID  `bson:"_id,omitempty" json:"id"`
+	ActorID     string         `bson:"actor_id" json:"actorId"`
+	ActorName   string         `bson:"actor_name" json:"actorName"`
+	Action      string         `bson:"action" json:"action"`
+	TargetType  string         `bson:"target_type" json:"targetType"` // project, workspace
+	TargetID    string         `bson:"target_id" json:"targetId"`
+	WorkspaceID *bson.ObjectID `bson:"workspace_id,omitempty" json:"workspaceId,omitempty"`
+	Before      bson.M         `bson:"before,omitempty" json:"before,omitempty"`
+	After       bson.M         `bson:"after,omitempty" json:"after,omitempty"`
+	IP          string         `bson:"ip,omitempty" json:"ip,omitempty"`
+	UserAgent   string         `bson:"user_agent,omitempty" json:"userAgent,omitempty"`
+	CreatedAt   time.Time      `bson:"created_at" json:"createdAt"`
+}
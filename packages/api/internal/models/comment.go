@@ -0,0 +1,31 @@
+package models
+
+import "go.mongodb.org/mongo-driver/v2/bson"
+
+// CommentThread is a discussion pinned to a project, either on an element or
+// at a point on the canvas.
+type CommentThread struct {
+	ID         bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	ProjectID  bson.ObjectID `bson:"project_id" json:"projectId"`
+	ElementID  string        `bson:"element_id,omitempty" json:"elementId,omitempty"`
+	X          *float64      `bson:"x,omitempty" json:"x,omitempty"`
+	Y          *float64      `bson:"y,omitempty" json:"y,omitempty"`
+	Orphaned   bool          `bson:"orphaned" json:"orphaned"` // the anchored element was deleted
+	CreatedBy  string        `bson:"created_by" json:"createdBy"`
+	ResolvedBy string        `bson:"resolved_by,omitempty" json:"resolvedBy,omitempty"`
+	ResolvedAt string        `bson:"resolved_at,omitempty" json:"resolvedAt,omitempty"`
+	CreatedAt  string        `bson:"created_at" json:"createdAt"`
+	UpdatedAt  string        `bson:"updated_at" json:"updatedAt"`
+}
+
+type Comment struct {
+	ID         bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	ThreadID   bson.ObjectID `bson:"thread_id" json:"threadId"`
+	ProjectID  bson.ObjectID `bson:"project_id" json:"projectId"`
+	AuthorID   string        `bson:"author_id" json:"authorId"`
+	AuthorName string        `bson:"author_name" json:"authorName"`
+	Body       string        `bson:"body" json:"body"`
+	Mentions   []string      `bson:"mentions" json:"mentions"` // user IDs
+	CreatedAt  string        `bson:"created_at" json:"createdAt"`
+	EditedAt   string        `bson:"edited_at,omitempty" json:"editedAt,omitempty"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// File links an Excalidraw fileId used by a project's image elements to the
+// stored contents. Identical contents are stored once as a Blob.
+type File struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	ProjectID bson.ObjectID `bson:"project_id" json:"projectId"`
+	FileID    string        `bson:"file_id" json:"fileId"`
+	Hash      string        `bson:"hash" json:"hash"` // SHA-256 of the contents
+	MimeType  string        `bson:"mime_type" json:"mimeType"`
+	Size      int64         `bson:"size" json:"size"`
+	CreatedBy string        `bson:"created_by" json:"createdBy"`
+	CreatedAt string        `bson:"created_at" json:"createdAt"` // UTC, compared as a string
+}
+
+// Blob records contents kept in the blob store, keyed by their hash.
+type Blob struct {
+	Hash       string    `bson:"_id" json:"hash"`
+	MimeType   string    `bson:"mime_type" json:"mimeType"`
+	Size       int64     `bson:"size" json:"size"`
+	CreatedAt  string    `bson:"created_at" json:"createdAt"`
+	LastUsedAt time.Time `bson:"last_used_at" json:"lastUsedAt"` // bumped whenever a file is linked to it
+}
@@ -0,0 +1,26 @@
+package models
+
+import "go.mongodb.org/mongo-driver/v2/bson"
+
+// Folder groups projects within a workspace. Folders nest; a nil ParentID
+// places the folder at the top level.
+type Folder struct {
+	ID          bson.ObjectID  `bson:"_id,omitempty" json:"id"`
+	WorkspaceID bson.ObjectID  `bson:"workspace_id" json:"workspaceId"`
+	ParentID    *bson.ObjectID `bson:"parent_id,omitempty" json:"parentId,omitempty"`
+	Name        string         `bson:"name" json:"name"`
+	CreatedBy   string         `bson:"created_by" json:"createdBy"`
+	CreatedAt   string         `bson:"created_at" json:"createdAt"`
+	UpdatedAt   string         `bson:"updated_at" json:"updatedAt"`
+}
+
+// ProjectUserState is what a single user keeps about a project: whether they
+// starred it and when they last opened it.
+type ProjectUserState struct {
+	ID           bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID       string        `bson:"user_id" json:"userId"`
+	ProjectID    bson.ObjectID `bson:"project_id" json:"projectId"`
+	Favorite     bool          `bson:"favorite" json:"favorite"`
+	FavoritedAt  string        `bson:"favorited_at,omitempty" json:"favoritedAt,omitempty"`
+	LastOpenedAt string        `bson:"last_opened_at,omitempty" json:"lastOpenedAt,omitempty"`
+}
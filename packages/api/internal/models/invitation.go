@@ -0,0 +1,28 @@
+package models
+
+import "go.mongodb.org/mongo-driver/v2/bson"
+
+const (
+	InvitationPending  = "PENDING"
+	InvitationAccepted = "ACCEPTED"
+	InvitationDeclined = "DECLINED"
+	InvitationRevoked  = "REVOKED"
+)
+
+// Invitation is a pending offer for someone to join a workspace. Invitations
+// sent to emails without a Keycloak account are accepted automatically when
+// that account first logs in.
+type Invitation struct {
+	ID          bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	WorkspaceID bson.ObjectID `bson:"workspace_id" json:"workspaceId"`
+	Email       string        `bson:"email" json:"email"`
+	Role        string        `bson:"role" json:"role"`
+	TokenHash   string        `bson:"token_hash" json:"-"`
+	InvitedBy   string        `bson:"invited_by" json:"invitedBy"`
+	Status      string        `bson:"status" json:"status"` // PENDING, ACCEPTED, DECLINED, REVOKED
+	AutoAccept  bool          `bson:"auto_accept" json:"autoAccept"`
+	ExpiresAt   string        `bson:"expires_at" json:"expiresAt"`
+	RespondedBy string        `bson:"responded_by,omitempty" json:"respondedBy,omitempty"`
+	RespondedAt string        `bson:"responded_at,omitempty" json:"respondedAt,omitempty"`
+	CreatedAt   string        `bson:"created_at" json:"createdAt"`
+}
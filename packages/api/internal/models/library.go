@@ -0,0 +1,29 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Library is a named collection of reusable shapes shared within a workspace.
+type Library struct {
+	ID          bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	WorkspaceID bson.ObjectID `bson:"workspace_id" json:"workspaceId"`
+	Name        string        `bson:"name" json:"name"`
+	Description string        `bson:"description" json:"description"`
+	Items       []LibraryItem `bson:"items" json:"items"`
+	CreatedBy   string        `bson:"created_by" json:"createdBy"`
+	CreatedAt   string        `bson:"created_at" json:"createdAt"`
+	UpdatedAt   string        `bson:"updated_at" json:"updatedAt"`
+}
+
+// LibraryItem is one group of elements in a library, what Excalidraw inserts
+// when the item is picked.
+type LibraryItem struct {
+	ID        string   `bson:"id" json:"id"`
+	Name      string   `bson:"name" json:"name"`
+	Tags      []string `bson:"tags" json:"tags"`
+	Elements  string   `bson:"elements" json:"elements"` // JSON array of Excalidraw elements
+	CreatedBy string   `bson:"created_by" json:"createdBy"`
+	CreatedAt string   `bson:"created_at" json:"createdAt"`
+	UpdatedAt string   `bson:"updated_at" json:"updatedAt"`
+}
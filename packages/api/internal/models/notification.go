@@ -0,0 +1,51 @@
+package models
+
+import "go.mongodb.org/mongo-driver/v2/bson"
+
+const (
+	NotificationWorkspaceInvitation = "WORKSPACE_INVITATION"
+	NotificationWorkspaceJoined     = "WORKSPACE_JOINED"
+	NotificationWorkspaceRemoved    = "WORKSPACE_REMOVED"
+	NotificationProjectShared       = "PROJECT_SHARED"
+	NotificationCommentMention      = "COMMENT_MENTION"
+)
+
+// Notification is an entry in a user's inbox.
+type Notification struct {
+	ID          bson.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID      string         `bson:"user_id" json:"userId"`
+	Type        string         `bson:"type" json:"type"`
+	ActorID     string         `bson:"actor_id" json:"actorId"`
+	ActorName   string         `bson:"actor_name" json:"actorName"`
+	WorkspaceID *bson.ObjectID `bson:"workspace_id,omitempty" json:"workspaceId,omitempty"`
+	ProjectID   *bson.ObjectID `bson:"project_id,omitempty" json:"projectId,omitempty"`
+	TargetID    string         `bson:"target_id,omitempty" json:"targetId,omitempty"` // invitation or comment thread
+	Message     string         `bson:"message" json:"message"`
+	ReadAt      string         `bson:"read_at,omitempty" json:"readAt,omitempty"`
+	CreatedAt   string         `bson:"created_at" json:"createdAt"`
+}
+
+// NotificationPreferences lists what a user does not want to be notified
+// about.
+type NotificationPreferences struct {
+	UserID          string          `bson:"_id" json:"userId"`
+	MutedTypes      []string        `bson:"muted_types" json:"mutedTypes"`
+	MutedWorkspaces []bson.ObjectID `bson:"muted_workspaces" json:"mutedWorkspaces"`
+}
+
+// Mutes reports whether the preferences suppress the notification.
+func (p *NotificationPreferences) Mutes(n *Notification) bool {
+	for _, t := range p.MutedTypes {
+		if t == n.Type {
+			return true
+		}
+	}
+	if n.WorkspaceID != nil {
+		for _, ws := range p.MutedWorkspaces {
+			if ws == *n.WorkspaceID {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -1,35 +1,51 @@
 package models
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 type Project struct {
-	ID          bson.ObjectID  `bson:"_id,omitempty" json:"id"`
-	Name        string         `bson:"name" json:"name"`
-	Description string         `bson:"description" json:"description"`
-	Owner       string         `bson:"owner" json:"owner"`
-	Members     []string       `bson:"members" json:"members"`
-	Workspace   *bson.ObjectID `bson:"workspace,omitempty" json:"workspace,omitempty"`
-	Personal    bool           `bson:"personal" json:"personal"`
-	Elements    string         `bson:"elements" json:"elements"`
-	HeadSeq     int64          `bson:"head_seq" json:"headSeq"`
-	CreatedAt   string         `bson:"created_at" json:"createdAt"`
-	UpdatedAt   string         `bson:"updated_at" json:"updatedAt"`
+	ID            bson.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Name          string         `bson:"name" json:"name"`
+	Description   string         `bson:"description" json:"description"`
+	Owner         string         `bson:"owner" json:"owner"`
+	Members       []string       `bson:"members" json:"members"` // direct members, on top of workspace members
+	Workspace     *bson.ObjectID `bson:"workspace,omitempty" json:"workspace,omitempty"`
+	FolderID      *bson.ObjectID `bson:"folder_id,omitempty" json:"folderId,omitempty"`
+	Tags          []string       `bson:"tags,omitempty" json:"tags,omitempty"`
+	Restricted    bool           `bson:"restricted" json:"restricted"` // workspace members need to be direct members
+	Personal      bool           `bson:"personal" json:"personal"`
+	IsTemplate    bool           `bson:"is_template" json:"isTemplate"` // offered as a starting point in its workspace
+	Elements      string         `bson:"elements" json:"elements"`
+	HeadSeq       int64          `bson:"head_seq" json:"headSeq"`
+	ElementCount  int            `bson:"element_count" json:"elementCount"`
+	ThumbnailHash string         `bson:"thumbnail_hash,omitempty" json:"thumbnailHash,omitempty"` // blob of the latest preview image
+	ThumbnailAt   string         `bson:"thumbnail_at,omitempty" json:"thumbnailAt,omitempty"`
+	CreatedAt     string         `bson:"created_at" json:"createdAt"`
+	UpdatedAt     string         `bson:"updated_at" json:"updatedAt"`
+
+	DeletedAt            string `bson:"deleted_at,omitempty" json:"deletedAt,omitempty"`
+	DeletedBy            string `bson:"deleted_by,omitempty" json:"deletedBy,omitempty"`
+	TrashedWithWorkspace bool   `bson:"trashed_with_workspace,omitempty" json:"trashedWithWorkspace,omitempty"` // restored together with its workspace
 }
 
 type Operation struct {
-	ID         bson.ObjectID `bson:"_id,omitempty" json:"id"`
-	ProjectID  bson.ObjectID `bson:"project_id" json:"projectId"`
-	Seq        int64         `bson:"seq" json:"seq"`
-	ClientSeq  int           `bson:"client_seq" json:"clientSeq"`
-	SocketID   string        `bson:"socket_id" json:"socketId"`
-	Type       string        `bson:"type" json:"type"` // ADD, UPDATE, DELETE
-	ElementID  string        `bson:"element_id" json:"elementId"`
-	ElementVer int           `bson:"element_ver" json:"elementVer"`
-	BaseSeq    int           `bson:"base_seq" json:"baseSeq"`
-	Data       *string       `bson:"data,omitempty" json:"data,omitempty"`
-	Timestamp  string        `bson:"timestamp" json:"timestamp"`
+	ID          bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	ProjectID   bson.ObjectID `bson:"project_id" json:"projectId"`
+	Seq         int64         `bson:"seq" json:"seq"`
+	ClientSeq   int           `bson:"client_seq" json:"clientSeq"`
+	SocketID    string        `bson:"socket_id" json:"socketId"`
+	UserID      string        `bson:"user_id,omitempty" json:"userId,omitempty"` // unset on ops logged before it was recorded
+	Type        string        `bson:"type" json:"type"`                          // ADD, UPDATE, DELETE
+	ElementID   string        `bson:"element_id" json:"elementId"`
+	ElementType string        `bson:"element_type,omitempty" json:"elementType,omitempty"` // Excalidraw type from data, unset for DELETE
+	ElementVer  int           `bson:"element_ver" json:"elementVer"`
+	BaseSeq     int           `bson:"base_seq" json:"baseSeq"`
+	Data        *string       `bson:"data,omitempty" json:"data,omitempty"`
+	Timestamp   string        `bson:"timestamp" json:"timestamp"`
+	At          time.Time     `bson:"at" json:"-"` // Timestamp as a date, for range queries
 }
 
 type OperationInput struct {
@@ -0,0 +1,31 @@
+package models
+
+import "go.mongodb.org/mongo-driver/v2/bson"
+
+// Kinds of search entries.
+const (
+	SearchKindProject = "PROJECT"
+	SearchKindElement = "ELEMENT"
+	SearchKindComment = "COMMENT"
+)
+
+// SearchEntry is one searchable piece of a project: its name and description,
+// a text element or a comment. Entries are only ever looked up through the
+// projects a user can access.
+type SearchEntry struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	ProjectID bson.ObjectID `bson:"project_id" json:"projectId"`
+	Kind      string        `bson:"kind" json:"kind"`
+	ElementID string        `bson:"element_id,omitempty" json:"elementId,omitempty"` // the text element, or the element a comment is pinned to
+	ThreadID  string        `bson:"thread_id,omitempty" json:"threadId,omitempty"`
+	CommentID string        `bson:"comment_id,omitempty" json:"commentId,omitempty"`
+	Name      string        `bson:"name,omitempty" json:"name,omitempty"` // project name, weighted above text
+	Text      string        `bson:"text" json:"text"`
+	UpdatedAt string        `bson:"updated_at" json:"updatedAt"`
+}
+
+// SearchHit is a matching entry with its text search relevance.
+type SearchHit struct {
+	SearchEntry `bson:",inline"`
+	Score       float64 `bson:"score" json:"score"`
+}
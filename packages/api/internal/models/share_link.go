@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	SharePermissionView = "VIEW"
+	SharePermissionEdit = "EDIT"
+)
+
+// ShareLink grants anonymous guests access to exactly one project. Like access
+// tokens, only the hash of the link token is stored.
+type ShareLink struct {
+	ID           bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	ProjectID    bson.ObjectID `bson:"project_id" json:"projectId"`
+	TokenHash    string        `bson:"token_hash" json:"-"`
+	Permission   string        `bson:"permission" json:"permission"` // VIEW, EDIT
+	PasswordHash string        `bson:"password_hash,omitempty" json:"-"`
+	ExpiresAt    string        `bson:"expires_at,omitempty" json:"expiresAt,omitempty"`
+	MaxUses      int           `bson:"max_uses" json:"maxUses"`
+	Uses         int           `bson:"uses" json:"uses"`
+	CreatedBy    string        `bson:"created_by" json:"createdBy"`
+	CreatedAt    string        `bson:"created_at" json:"createdAt"`
+	RevokedAt    string        `bson:"revoked_at,omitempty" json:"revokedAt,omitempty"`
+}
+
+// Usable reports whether guests may still use the link at the given time: it
+// is neither revoked nor expired. A link with an unreadable expiry is treated
+// as expired.
+func (l *ShareLink) Usable(now time.Time) bool {
+	if l.RevokedAt != "" {
+		return false
+	}
+	if l.ExpiresAt == "" {
+		return true
+	}
+	expiresAt, err := time.Parse(time.RFC3339, l.ExpiresAt)
+	return err == nil && now.Before(expiresAt)
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	WebhookEventOpsApplied     = "OPS_APPLIED"
+	WebhookEventProjectCreated = "PROJECT_CREATED"
+	WebhookEventProjectDeleted = "PROJECT_DELETED"
+	WebhookEventMemberAdded    = "MEMBER_ADDED"
+	WebhookEventMemberRemoved  = "MEMBER_REMOVED"
+	WebhookEventCommentCreated = "COMMENT_CREATED"
+)
+
+const (
+	WebhookDeliveryPending   = "PENDING"
+	WebhookDeliverySucceeded = "SUCCEEDED"
+	WebhookDeliveryFailed    = "FAILED"
+)
+
+// Webhook posts workspace events to an external URL. The secret is kept in
+// plain text because it is needed to sign every delivery.
+type Webhook struct {
+	ID          bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	WorkspaceID bson.ObjectID `bson:"workspace_id" json:"workspaceId"`
+	URL         string        `bson:"url" json:"url"`
+	Events      []string      `bson:"events" json:"events"`
+	Secret      string        `bson:"secret" json:"-"`
+	Active      bool          `bson:"active" json:"active"`
+	CreatedBy   string        `bson:"created_by" json:"createdBy"`
+	CreatedAt   string        `bson:"created_at" json:"createdAt"`
+}
+
+// WebhookDelivery is one event queued for a webhook, kept afterwards as the
+// delivery log.
+type WebhookDelivery struct {
+	ID            bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	WebhookID     bson.ObjectID `bson:"webhook_id" json:"webhookId"`
+	WorkspaceID   bson.ObjectID `bson:"workspace_id" json:"workspaceId"`
+	Event         string        `bson:"event" json:"event"`
+	Payload       string        `bson:"payload" json:"payload"`
+	Status        string        `bson:"status" json:"status"` // PENDING, SUCCEEDED, FAILED
+	Attempts      int           `bson:"attempts" json:"attempts"`
+	NextAttemptAt time.Time     `bson:"next_attempt_at" json:"nextAttemptAt"`
+	ResponseCode  int           `bson:"response_code,omitempty" json:"responseCode,omitempty"`
+	LastError     string        `bson:"last_error,omitempty" json:"lastError,omitempty"`
+	CreatedAt     string        `bson:"created_at" json:"createdAt"`
+	DeliveredAt   string        `bson:"delivered_at,omitempty" json:"deliveredAt,omitempty"`
+}
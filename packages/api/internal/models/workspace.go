@@ -2,11 +2,37 @@ package models
 
 import "go.mongodb.org/mongo-driver/v2/bson"
 
+const (
+	WorkspaceRoleOwner  = "OWNER"
+	WorkspaceRoleEditor = "EDITOR"
+	WorkspaceRoleViewer = "VIEWER"
+)
+
 type Workspace struct {
-	ID          bson.ObjectID `bson:"_id,omitempty" json:"id"`
-	Name        string        `bson:"name" json:"name"`
-	Description string        `bson:"description" json:"description"`
-	Owner       string        `bson:"owner_id" json:"ownerId"`
-	Members     []string      `bson:"members" json:"members"`
-	CreatedAt   string        `bson:"created_at" json:"createdAt"`
+	ID          bson.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Name        string            `bson:"name" json:"name"`
+	Description string            `bson:"description" json:"description"`
+	Owner       string            `bson:"owner_id" json:"ownerId"`
+	Members     []string          `bson:"members" json:"members"`
+	Roles       map[string]string `bson:"roles,omitempty" json:"roles,omitempty"` // userID -> role, EDITOR when unset
+	CreatedAt   string            `bson:"created_at" json:"createdAt"`
+	DeletedAt   string            `bson:"deleted_at,omitempty" json:"deletedAt,omitempty"`
+	DeletedBy   string            `bson:"deleted_by,omitempty" json:"deletedBy,omitempty"`
+}
+
+// RoleOf returns the role of a user in the workspace, or "" when the user is
+// not part of it.
+func (w *Workspace) RoleOf(userID string) string {
+	if w.Owner == userID {
+		return WorkspaceRoleOwner
+	}
+	for _, member := range w.Members {
+		if member == userID {
+			if role, ok := w.Roles[userID]; ok {
+				return role
+			}
+			return WorkspaceRoleEditor
+		}
+	}
+	return ""
 }
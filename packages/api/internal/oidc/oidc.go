@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 
 	gooidc "github.com/coreos/go-oidc/v3/oidc"
 )
@@ -17,10 +18,19 @@ var (
 type Claims struct {
 	Sub               string `json:"sub"`
 	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
 	Name              string `json:"name"`
 	PreferredUsername string `json:"preferred_username"`
 	GivenName         string `json:"given_name"`
 	FamilyName        string `json:"family_name"`
+	RealmAccess       struct {
+		Roles []string `json:"roles"`
+	} `json:"realm_access"`
+}
+
+// HasRole reports whether the user holds the Keycloak realm role.
+func (c *Claims) HasRole(role string) bool {
+	return slices.Contains(c.RealmAccess.Roles, role)
 }
 
 // Init sets up the OIDC provider and verifier using Keycloak discovery.
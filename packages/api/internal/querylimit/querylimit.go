@@ -0,0 +1,112 @@
+package querylimit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	defaultMaxComplexity = 1000
+	defaultMaxDepth      = 12
+)
+
+// MaxComplexity returns the highest query complexity accepted, as configured
+// by GRAPHQL_MAX_COMPLEXITY.
+func MaxComplexity() int {
+	return envInt("GRAPHQL_MAX_COMPLEXITY", defaultMaxComplexity)
+}
+
+// MaxDepth returns the deepest field nesting accepted, as configured by
+// GRAPHQL_MAX_DEPTH.
+func MaxDepth() int {
+	return envInt("GRAPHQL_MAX_DEPTH", defaultMaxDepth)
+}
+
+// IsProduction reports whether APP_ENV is set to production.
+func IsProduction() bool {
+	return strings.EqualFold(os.Getenv("APP_ENV"), "production")
+}
+
+func envInt(name string, fallback int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(v)
+	if err != nil || parsed <= 0 {
+		log.Printf("Warning: invalid %s %q, using %d", name, v, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// DepthLimit is a gqlgen extension rejecting operations that nest fields more
+// than Max levels deep. Introspection fields are not counted, so tools can
+// still load the schema where introspection is enabled.
+type DepthLimit struct {
+	Max int
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = DepthLimit{}
+
+func (d DepthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+func (d DepthLimit) Validate(graphql.ExecutableSchema) error {
+	if d.Max <= 0 {
+		return fmt.Errorf("depth limit must be positive")
+	}
+	return nil
+}
+
+func (d DepthLimit) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	if rc.Operation == nil {
+		return nil
+	}
+	if depth := selectionDepth(rc.Operation.SelectionSet, map[string]bool{}); depth > d.Max {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, d.Max)
+		err.Extensions = map[string]any{"code": "DEPTH_LIMIT_EXCEEDED"}
+		return err
+	}
+	return nil
+}
+
+// selectionDepth returns how many levels of fields the selection set nests.
+// visiting guards against fragments that spread themselves, which validation
+// rejects but which must not hang the server before that.
+func selectionDepth(set ast.SelectionSet, visiting map[string]bool) int {
+	depth := 0
+	for _, selection := range set {
+		var d int
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name, "__") {
+				continue
+			}
+			d = 1 + selectionDepth(s.SelectionSet, visiting)
+		case *ast.InlineFragment:
+			d = selectionDepth(s.SelectionSet, visiting)
+		case *ast.FragmentSpread:
+			if s.Definition == nil || visiting[s.Name] {
+				continue
+			}
+			visiting[s.Name] = true
+			d = selectionDepth(s.Definition.SelectionSet, visiting)
+			delete(visiting, s.Name)
+		}
+		depth = max(depth, d)
+	}
+	return depth
+}
@@ -0,0 +1,119 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/chirag3003/collab-draw-backend/internal/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Limits caps what the live projects of a workspace may hold. Zero means
+// unlimited.
+type Limits struct {
+	MaxProjects     int
+	MaxElements     int
+	MaxStorageBytes int64
+}
+
+// LimitsFromEnv reads WORKSPACE_MAX_PROJECTS, WORKSPACE_MAX_ELEMENTS and
+// WORKSPACE_MAX_STORAGE_MB. Unset or invalid settings leave the limit off.
+func LimitsFromEnv() Limits {
+	return Limits{
+		MaxProjects:     envInt("WORKSPACE_MAX_PROJECTS"),
+		MaxElements:     envInt("WORKSPACE_MAX_ELEMENTS"),
+		MaxStorageBytes: int64(envInt("WORKSPACE_MAX_STORAGE_MB")) << 20,
+	}
+}
+
+func envInt(name string) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return 0
+	}
+	parsed, err := strconv.Atoi(v)
+	if err != nil || parsed < 0 {
+		log.Printf("Warning: invalid %s %q, leaving it unlimited", name, v)
+		return 0
+	}
+	return parsed
+}
+
+// ExceededError reports a change that would take a workspace over a limit.
+type ExceededError struct {
+	Resource string // "projects", "elements" or "storage"
+	Limit    int64
+	Used     int64
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("workspace %s quota exceeded (%d of %d used)", e.Resource, e.Used, e.Limit)
+}
+
+// Service checks changes to a workspace against its limits. Projects outside
+// any workspace are not limited.
+type Service struct {
+	repo   *repository.Repository
+	limits Limits
+}
+
+func NewService(repo *repository.Repository, limits Limits) *Service {
+	return &Service{repo: repo, limits: limits}
+}
+
+func (s *Service) Limits() Limits {
+	return s.limits
+}
+
+func (s *Service) Usage(ctx context.Context, workspaceID bson.ObjectID) (*repository.WorkspaceUsage, error) {
+	return s.repo.Project.GetWorkspaceUsage(ctx, workspaceID)
+}
+
+// CheckProjects returns an ExceededError when adding n projects to the
+// workspace would exceed its project limit.
+func (s *Service) CheckProjects(ctx context.Context, workspaceID *bson.ObjectID, n int) error {
+	if workspaceID == nil || s.limits.MaxProjects == 0 || n <= 0 {
+		return nil
+	}
+	usage, err := s.Usage(ctx, *workspaceID)
+	if err != nil {
+		return err
+	}
+	return check("projects", int64(usage.Projects), int64(n), int64(s.limits.MaxProjects))
+}
+
+// CheckElements returns an ExceededError when adding n elements to the
+// workspace would exceed its element limit.
+func (s *Service) CheckElements(ctx context.Context, workspaceID *bson.ObjectID, n int) error {
+	if workspaceID == nil || s.limits.MaxElements == 0 || n <= 0 {
+		return nil
+	}
+	usage, err := s.Usage(ctx, *workspaceID)
+	if err != nil {
+		return err
+	}
+	return check("elements", int64(usage.Elements), int64(n), int64(s.limits.MaxElements))
+}
+
+// CheckStorage returns an ExceededError when storing size more bytes of files
+// in the workspace would exceed its storage limit.
+func (s *Service) CheckStorage(ctx context.Context, workspaceID *bson.ObjectID, size int64) error {
+	if workspaceID == nil || s.limits.MaxStorageBytes == 0 || size <= 0 {
+		return nil
+	}
+	usage, err := s.Usage(ctx, *workspaceID)
+	if err != nil {
+		return err
+	}
+	return check("storage", usage.StorageBytes, size, s.limits.MaxStorageBytes)
+}
+
+func check(resource string, used int64, adding int64, limit int64) error {
+	if used+adding > limit {
+		return &ExceededError{Resource: resource, Limit: limit, Used: used}
+	}
+	return nil
+}
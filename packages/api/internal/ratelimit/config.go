@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Limit is a token bucket holding up to Burst requests, refilled at Rate
+// requests per second. A zero Limit is not enforced.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l Limit) enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// Rule limits calls to one root field, per user and per project.
+type Rule struct {
+	PerUser    Limit
+	PerProject Limit
+	ProjectArg string // argument holding the project ID, for PerProject
+}
+
+type Config struct {
+	Rules                         map[string]Rule // keyed by root field name
+	MaxSubscriptionsPerConnection int             // 0 is unlimited
+}
+
+// DefaultConfig leaves room for a busy collaborative session while stopping
+// runaway clients.
+func DefaultConfig() Config {
+	return Config{
+		Rules: map[string]Rule{
+			"applyOps": {
+				PerUser:    Limit{Rate: 20, Burst: 60},
+				PerProject: Limit{Rate: 100, Burst: 200},
+				ProjectArg: "projectID",
+			},
+			"updateCursor": {
+				PerUser:    Limit{Rate: 30, Burst: 30},
+				PerProject: Limit{Rate: 200, Burst: 200},
+				ProjectArg: "projectID",
+			},
+			"updateProject": {
+				PerUser:    Limit{Rate: 2, Burst: 10},
+				PerProject: Limit{Rate: 5, Burst: 20},
+				ProjectArg: "id",
+			},
+			"createProject": {
+				PerUser: Limit{Rate: 0.2, Burst: 20},
+			},
+			"duplicateProject": {
+				PerUser: Limit{Rate: 0.2, Burst: 10},
+			},
+			"importProject": {
+				PerUser: Limit{Rate: 0.1, Burst: 5},
+			},
+		},
+		MaxSubscriptionsPerConnection: 50,
+	}
+}
+
+// ConfigFromEnv returns DefaultConfig with the overrides of RATE_LIMITS and
+// MAX_SUBSCRIPTIONS_PER_CONNECTION applied. RATE_LIMITS lists rules separated
+// by semicolons, each a field name followed by its limits as rate/burst:
+//
+//	applyOps=user:20/60,project:100/200;updateCursor=user:10/10
+//
+// Limits left out of a rule keep their defaults, and 0/0 turns one off.
+func ConfigFromEnv() Config {
+	config := DefaultConfig()
+	if v := os.Getenv("RATE_LIMITS"); v != "" {
+		for _, spec := range strings.Split(v, ";") {
+			if strings.TrimSpace(spec) == "" {
+				continue
+			}
+			if err := config.apply(spec); err != nil {
+				log.Printf("Warning: ignoring RATE_LIMITS entry %q: %v", spec, err)
+			}
+		}
+	}
+	if v := os.Getenv("MAX_SUBSCRIPTIONS_PER_CONNECTION"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 {
+			log.Printf("Warning: invalid MAX_SUBSCRIPTIONS_PER_CONNECTION %q, using %d", v, config.MaxSubscriptionsPerConnection)
+		} else {
+			config.MaxSubscriptionsPerConnection = parsed
+		}
+	}
+	return config
+}
+
+func (c *Config) apply(spec string) error {
+	field, limits, ok := strings.Cut(strings.TrimSpace(spec), "=")
+	if !ok || field == "" {
+		return fmt.Errorf("expected field=limits")
+	}
+	rule := c.Rules[field]
+	for _, part := range strings.Split(limits, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return fmt.Errorf("expected user:rate/burst or project:rate/burst")
+		}
+		limit, err := parseLimit(value)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "user":
+			rule.PerUser = limit
+		case "project":
+			if rule.ProjectArg == "" {
+				rule.ProjectArg = "projectID"
+			}
+			rule.PerProject = limit
+		default:
+			return fmt.Errorf("unknown key %q", key)
+		}
+	}
+	c.Rules[field] = rule
+	return nil
+}
+
+func parseLimit(value string) (Limit, error) {
+	rate, burst, ok := strings.Cut(value, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("expected rate/burst, got %q", value)
+	}
+	r, err := strconv.ParseFloat(rate, 64)
+	if err != nil || r < 0 {
+		return Limit{}, fmt.Errorf("invalid rate %q", rate)
+	}
+	b, err := strconv.Atoi(burst)
+	if err != nil || b < 0 {
+		return Limit{}, fmt.Errorf("invalid burst %q", burst)
+	}
+	return Limit{Rate: r, Burst: b}, nil
+}
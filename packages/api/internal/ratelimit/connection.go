@@ -0,0 +1,42 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+)
+
+type contextKey struct{}
+
+// connection counts the open subscriptions of one WebSocket connection.
+type connection struct {
+	mu            sync.Mutex
+	subscriptions int
+}
+
+// WithConnection marks the context as belonging to a new WebSocket
+// connection so its subscriptions can be counted. It is meant to be called
+// from the transport's InitFunc.
+func WithConnection(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, &connection{})
+}
+
+func connectionForContext(ctx context.Context) *connection {
+	conn, _ := ctx.Value(contextKey{}).(*connection)
+	return conn
+}
+
+func (c *connection) acquire(limit int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.subscriptions >= limit {
+		return false
+	}
+	c.subscriptions++
+	return true
+}
+
+func (c *connection) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subscriptions--
+}
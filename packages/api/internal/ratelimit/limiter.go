@@ -0,0 +1,188 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/chirag3003/collab-draw-backend/internal/auth"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// sweepInterval is how often buckets that have refilled completely are
+// dropped, since they behave exactly like new ones.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit
+}
+
+// refill adds the tokens earned since the last update.
+func (b *bucket) refill(now time.Time) {
+	b.tokens = math.Min(float64(b.limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*b.limit.Rate)
+	b.updated = now
+}
+
+// wait returns how long until the bucket holds a whole token.
+func (b *bucket) wait() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second))
+}
+
+// Limiter is a gqlgen extension applying token bucket rate limits to root
+// fields and capping the subscriptions of each WebSocket connection.
+type Limiter struct {
+	config Config
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+	graphql.FieldInterceptor
+} = &Limiter{}
+
+func New(config Config) *Limiter {
+	return &Limiter{
+		config:    config,
+		buckets:   map[string]*bucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *Limiter) ExtensionName() string {
+	return "RateLimit"
+}
+
+func (l *Limiter) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation rejects subscriptions beyond the connection's limit and
+// frees the slot once a subscription ends.
+func (l *Limiter) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	op := graphql.GetOperationContext(ctx).Operation
+	conn := connectionForContext(ctx)
+	if op == nil || op.Operation != ast.Subscription || conn == nil || l.config.MaxSubscriptionsPerConnection == 0 {
+		return next(ctx)
+	}
+	if !conn.acquire(l.config.MaxSubscriptionsPerConnection) {
+		return graphql.OneShot(&graphql.Response{Errors: gqlerror.List{{
+			Message: fmt.Sprintf("too many subscriptions on this connection (limit %d)", l.config.MaxSubscriptionsPerConnection),
+			Extensions: map[string]any{
+				"code":  "SUBSCRIPTION_LIMIT",
+				"limit": l.config.MaxSubscriptionsPerConnection,
+			},
+		}}})
+	}
+	// The transport cancels the context when the subscription stops
+	go func() {
+		<-ctx.Done()
+		conn.release()
+	}()
+	return next(ctx)
+}
+
+// InterceptField takes a token from the user's and the project's bucket for
+// root fields with a rule, failing the field when either is empty.
+func (l *Limiter) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || fc.Parent != nil {
+		return next(ctx)
+	}
+	rule, ok := l.config.Rules[fc.Field.Name]
+	if !ok {
+		return next(ctx)
+	}
+
+	var keys []string
+	var limits []Limit
+	if rule.PerUser.enabled() {
+		keys = append(keys, "user:"+fc.Field.Name+":"+userKey(ctx))
+		limits = append(limits, rule.PerUser)
+	}
+	if projectID, ok := fc.Args[rule.ProjectArg].(string); ok && rule.PerProject.enabled() {
+		keys = append(keys, "project:"+fc.Field.Name+":"+projectID)
+		limits = append(limits, rule.PerProject)
+	}
+	if wait := l.take(keys, limits); wait > 0 {
+		// Round up so clients retrying on time find a token
+		retryAfter := int(math.Ceil(wait.Seconds()))
+		return nil, &gqlerror.Error{
+			Message: fmt.Sprintf("rate limit exceeded for %s, retry in %ds", fc.Field.Name, retryAfter),
+			Path:    fc.Path(),
+			Extensions: map[string]any{
+				"code":       "RATE_LIMITED",
+				"retryAfter": retryAfter,
+			},
+		}
+	}
+	return next(ctx)
+}
+
+// take removes a token from every bucket, or from none of them when one is
+// empty, in which case it returns how long until all of them have a token.
+func (l *Limiter) take(keys []string, limits []Limit) time.Duration {
+	if len(keys) == 0 {
+		return 0
+	}
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	var wait time.Duration
+	buckets := make([]*bucket, len(keys))
+	for i, key := range keys {
+		b := l.buckets[key]
+		if b == nil || b.limit != limits[i] {
+			b = &bucket{tokens: float64(limits[i].Burst), updated: now, limit: limits[i]}
+			l.buckets[key] = b
+		}
+		b.refill(now)
+		wait = max(wait, b.wait())
+		buckets[i] = b
+	}
+	if wait > 0 {
+		return wait
+	}
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return 0
+}
+
+// sweep drops full buckets once per sweepInterval to keep memory bounded.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// userKey identifies the caller: the user, or the client IP for guests and
+// anonymous requests. Guests are keyed by IP since starting a new guest
+// session would otherwise give them a fresh bucket.
+func userKey(ctx context.Context) string {
+	if claims := auth.ForContext(ctx); claims != nil && claims.Sub != "" && !auth.IsGuest(ctx) {
+		return claims.Sub
+	}
+	return "ip:" + auth.RequestInfoForContext(ctx).IP
+}
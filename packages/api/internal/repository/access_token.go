@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/config"
+	"github.com/chirag3003/collab-draw-backend/internal/db"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type accessTokenRepository struct {
+	tokens *mongo.Collection
+}
+
+type AccessTokenRepository interface {
+	CreateToken(ctx context.Context, data *models.AccessToken) error
+	GetTokensByUser(ctx context.Context, userID string) ([]*models.AccessToken, error)
+	GetTokenByHash(ctx context.Context, hash string) (*models.AccessToken, error)
+	TouchToken(ctx context.Context, id bson.ObjectID) error
+	RevokeToken(ctx context.Context, id string, userID string) (bool, error)
+	DeleteByProjects(ctx context.Context, projectIDs []bson.ObjectID) error
+	DeleteByWorkspace(ctx context.Context, workspaceID bson.ObjectID) error
+}
+
+func NewAccessTokenRepository() AccessTokenRepository {
+	tokens := db.GetCollection(config.ACCESS_TOKENS)
+
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+	}
+	_, _ = tokens.Indexes().CreateMany(context.Background(), indexModels)
+
+	return &accessTokenRepository{
+		tokens: tokens,
+	}
+}
+
+func (r *accessTokenRepository) CreateToken(ctx context.Context, data *models.AccessToken) error {
+	data.CreatedAt = time.Now().Format(time.RFC3339)
+	res, err := r.tokens.InsertOne(ctx, data)
+	if err != nil {
+		return err
+	}
+	if id, ok := res.InsertedID.(bson.ObjectID); ok {
+		data.ID = id
+	}
+	return nil
+}
+
+func (r *accessTokenRepository) GetTokensByUser(ctx context.Context, userID string) ([]*models.AccessToken, error) {
+	var tokens []*models.AccessToken
+	findOpts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.tokens.Find(ctx, bson.M{
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+	}, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// GetTokenByHash returns the active token with the given hash, or nil when the
+// token is unknown, revoked or expired.
+func (r *accessTokenRepository) GetTokenByHash(ctx context.Context, hash string) (*models.AccessToken, error) {
+	var token models.AccessToken
+	err := r.tokens.FindOne(ctx, bson.M{
+		"token_hash": hash,
+		"revoked_at": bson.M{"$exists": false},
+	}).Decode(&token)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if token.ExpiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, token.ExpiresAt)
+		if err != nil || time.Now().After(expiresAt) {
+			return nil, nil
+		}
+	}
+	return &token, nil
+}
+
+func (r *accessTokenRepository) TouchToken(ctx context.Context, id bson.ObjectID) error {
+	_, err := r.tokens.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{
+			"last_used_at": time.Now().Format(time.RFC3339),
+		},
+	})
+	return err
+}
+
+func (r *accessTokenRepository) RevokeToken(ctx context.Context, id string, userID string) (bool, error) {
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+	res, err := r.tokens.UpdateOne(ctx, bson.M{
+		"_id":        ID,
+		"user_id":    userID,
+		"revoked_at": bson.M{"$exists": false},
+	}, bson.M{
+		"$set": bson.M{
+			"revoked_at": time.Now().Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// DeleteByProjects removes tokens scoped to any of the given projects.
+func (r *accessTokenRepository) DeleteByProjects(ctx context.Context, projectIDs []bson.ObjectID) error {
+	if len(projectIDs) == 0 {
+		return nil
+	}
+	_, err := r.tokens.DeleteMany(ctx, bson.M{"project_id": bson.M{"$in": projectIDs}})
+	return err
+}
+
+// DeleteByWorkspace removes tokens scoped to the workspace.
+func (r *accessTokenRepository) DeleteByWorkspace(ctx context.Context, workspaceID bson.ObjectID) error {
+	_, err := r.tokens.DeleteMany(ctx, bson.M{"workspace_id": workspaceID})
+	return err
+}
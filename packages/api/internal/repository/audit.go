@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/config"
+	"github.com/chirag3003/collab-draw-backend/internal/db"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// defaultAuditRetentionDays applies when AUDIT_LOG_RETENTION_DAYS is unset.
+// A value of 0 keeps entries forever.
+const defaultAuditRetentionDays = 365
+
+const auditPageSize = 50
+
+type auditRepository struct {
+	entries *mongo.Collection
+}
+
+// AuditFilter narrows an audit log query. Empty fields match everything.
+type AuditFilter struct {
+	ActorID  string
+	Action   string
+	TargetID string
+	Since    *time.Time
+	Until    *time.Time
+}
+
+type AuditRepository interface {
+	Record(ctx context.Context, entry *models.AuditEntry) error
+	GetByWorkspace(ctx context.Context, workspaceID string, filter AuditFilter, cursor string) ([]*models.AuditEntry, string, error)
+}
+
+func NewAuditRepository() AuditRepository {
+	entries := db.GetCollection(config.AUDIT_LOG)
+
+	indexModels := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "workspace_id", Value: 1},
+				{Key: "_id", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "target_id", Value: 1},
+				{Key: "_id", Value: -1},
+			},
+		},
+	}
+	_, _ = entries.Indexes().CreateMany(context.Background(), indexModels)
+
+	ensureAuditRetention(entries)
+
+	return &auditRepository{
+		entries: entries,
+	}
+}
+
+// ensureAuditRetention keeps the TTL index on created_at in line with
+// AUDIT_LOG_RETENTION_DAYS, updating it in place when the setting changes.
+func ensureAuditRetention(entries *mongo.Collection) {
+	days := defaultAuditRetentionDays
+	if v := os.Getenv("AUDIT_LOG_RETENTION_DAYS"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 {
+			log.Printf("Warning: invalid AUDIT_LOG_RETENTION_DAYS %q, using %d", v, defaultAuditRetentionDays)
+		} else {
+			days = parsed
+		}
+	}
+
+	const indexName = "created_at_ttl"
+	ctx := context.Background()
+	if days == 0 {
+		_ = entries.Indexes().DropOne(ctx, indexName)
+		return
+	}
+
+	expireAfter := int32(days * 24 * 60 * 60)
+	_, err := entries.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "created_at", Value: 1}},
+		Options: options.Index().SetName(indexName).SetExpireAfterSeconds(expireAfter),
+	})
+	if err == nil {
+		return
+	}
+	// The index already exists with another retention
+	err = entries.Database().RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: entries.Name()},
+		{Key: "index", Value: bson.M{"name": indexName, "expireAfterSeconds": expireAfter}},
+	}).Err()
+	if err != nil {
+		log.Printf("Warning: failed to update audit log retention: %v", err)
+	}
+}
+
+func (r *auditRepository) Record(ctx context.Context, entry *models.AuditEntry) error {
+	entry.CreatedAt = time.Now().UTC()
+	res, err := r.entries.InsertOne(ctx, entry)
+	if err != nil {
+		return err
+	}
+	if id, ok := res.InsertedID.(bson.ObjectID); ok {
+		entry.ID = id
+	}
+	return nil
+}
+
+// GetByWorkspace returns a page of entries for the workspace, newest first.
+// The returned cursor is empty on the last page.
+func (r *auditRepository) GetByWorkspace(ctx context.Context, workspaceID string, filter AuditFilter, cursor string) ([]*models.AuditEntry, string, error) {
+	ID, err := bson.ObjectIDFromHex(workspaceID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := bson.M{"workspace_id": ID}
+	if filter.ActorID != "" {
+		query["actor_id"] = filter.ActorID
+	}
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+	if filter.TargetID != "" {
+		query["target_id"] = filter.TargetID
+	}
+	if filter.Since != nil || filter.Until != nil {
+		createdAt := bson.M{}
+		if filter.Since != nil {
+			createdAt["$gte"] = *filter.Since
+		}
+		if filter.Until != nil {
+			createdAt["$lte"] = *filter.Until
+		}
+		query["created_at"] = createdAt
+	}
+	if cursor != "" {
+		after, err := bson.ObjectIDFromHex(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		query["_id"] = bson.M{"$lt": after}
+	}
+
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(auditPageSize + 1)
+	res, err := r.entries.Find(ctx, query, findOpts)
+	if err != nil {
+		return nil, "", err
+	}
+	var entries []*models.AuditEntry
+	if err = res.All(ctx, &entries); err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(entries) > auditPageSize {
+		entries = entries[:auditPageSize]
+		nextCursor = entries[len(entries)-1].ID.Hex()
+	}
+	return entries, nextCursor, nil
+}
@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/config"
+	"github.com/chirag3003/collab-draw-backend/internal/db"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type commentRepository struct {
+	threads  *mongo.Collection
+	comments *mongo.Collection
+}
+
+type CommentRepository interface {
+	CreateThread(ctx context.Context, thread *models.CommentThread, first *models.Comment) error
+	GetThread(ctx context.Context, id string) (*models.CommentThread, error)
+	GetThreadsByProject(ctx context.Context, projectID bson.ObjectID, includeResolved bool) ([]*models.CommentThread, error)
+	GetComment(ctx context.Context, id string) (*models.Comment, error)
+	GetCommentsByThreads(ctx context.Context, threadIDs []bson.ObjectID) ([]*models.Comment, error)
+	AddComment(ctx context.Context, comment *models.Comment) error
+	EditComment(ctx context.Context, id bson.ObjectID, authorID string, body string, mentions []string) (bool, error)
+	DeleteComment(ctx context.Context, comment *models.Comment) error
+	SetResolved(ctx context.Context, threadID bson.ObjectID, resolved bool, userID string) (bool, error)
+	SetOrphaned(ctx context.Context, projectID bson.ObjectID, elementIDs []string, orphaned bool) ([]bson.ObjectID, error)
+	DeleteByProjects(ctx context.Context, projectIDs []bson.ObjectID) error
+}
+
+func NewCommentRepository() CommentRepository {
+	threads := db.GetCollection(config.COMMENT_THREADS)
+	comments := db.GetCollection(config.COMMENTS)
+
+	_, _ = threads.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "project_id", Value: 1},
+			{Key: "element_id", Value: 1},
+		},
+	})
+	_, _ = comments.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "thread_id", Value: 1},
+			{Key: "created_at", Value: 1},
+		},
+	})
+
+	return &commentRepository{
+		threads:  threads,
+		comments: comments,
+	}
+}
+
+// CreateThread stores a new thread together with its first comment.
+func (r *commentRepository) CreateThread(ctx context.Context, thread *models.CommentThread, first *models.Comment) error {
+	now := time.Now().Format(time.RFC3339)
+	thread.CreatedAt = now
+	thread.UpdatedAt = now
+	res, err := r.threads.InsertOne(ctx, thread)
+	if err != nil {
+		return err
+	}
+	if id, ok := res.InsertedID.(bson.ObjectID); ok {
+		thread.ID = id
+	}
+	first.ThreadID = thread.ID
+	first.ProjectID = thread.ProjectID
+	return r.AddComment(ctx, first)
+}
+
+func (r *commentRepository) GetThread(ctx context.Context, id string) (*models.CommentThread, error) {
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	var thread models.CommentThread
+	err = r.threads.FindOne(ctx, bson.M{"_id": ID}).Decode(&thread)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &thread, nil
+}
+
+func (r *commentRepository) GetThreadsByProject(ctx context.Context, projectID bson.ObjectID, includeResolved bool) ([]*models.CommentThread, error) {
+	filter := bson.M{"project_id": projectID}
+	if !includeResolved {
+		filter["resolved_at"] = bson.M{"$exists": false}
+	}
+	cursor, err := r.threads.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var threads []*models.CommentThread
+	if err = cursor.All(ctx, &threads); err != nil {
+		return nil, err
+	}
+	return threads, nil
+}
+
+func (r *commentRepository) GetComment(ctx context.Context, id string) (*models.Comment, error) {
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	var comment models.Comment
+	err = r.comments.FindOne(ctx, bson.M{"_id": ID}).Decode(&comment)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *commentRepository) GetCommentsByThreads(ctx context.Context, threadIDs []bson.ObjectID) ([]*models.Comment, error) {
+	var comments []*models.Comment
+	if len(threadIDs) == 0 {
+		return comments, nil
+	}
+	cursor, err := r.comments.Find(ctx, bson.M{"thread_id": bson.M{"$in": threadIDs}},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (r *commentRepository) AddComment(ctx context.Context, comment *models.Comment) error {
+	comment.CreatedAt = time.Now().Format(time.RFC3339)
+	if comment.Mentions == nil {
+		comment.Mentions = []string{}
+	}
+	res, err := r.comments.InsertOne(ctx, comment)
+	if err != nil {
+		return err
+	}
+	if id, ok := res.InsertedID.(bson.ObjectID); ok {
+		comment.ID = id
+	}
+	_, err = r.threads.UpdateOne(ctx, bson.M{"_id": comment.ThreadID}, bson.M{
+		"$set": bson.M{"updated_at": comment.CreatedAt},
+	})
+	return err
+}
+
+// EditComment replaces the body of a comment written by the given author.
+func (r *commentRepository) EditComment(ctx context.Context, id bson.ObjectID, authorID string, body string, mentions []string) (bool, error) {
+	if mentions == nil {
+		mentions = []string{}
+	}
+	res, err := r.comments.UpdateOne(ctx, bson.M{"_id": id, "author_id": authorID}, bson.M{
+		"$set": bson.M{
+			"body":      body,
+			"mentions":  mentions,
+			"edited_at": time.Now().Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// DeleteComment removes a comment, and its thread when it was the last one.
+func (r *commentRepository) DeleteComment(ctx context.Context, comment *models.Comment) error {
+	_, err := r.comments.DeleteOne(ctx, bson.M{"_id": comment.ID})
+	if err != nil {
+		return err
+	}
+	remaining, err := r.comments.CountDocuments(ctx, bson.M{"thread_id": comment.ThreadID})
+	if err != nil {
+		return err
+	}
+	if remaining == 0 {
+		_, err = r.threads.DeleteOne(ctx, bson.M{"_id": comment.ThreadID})
+	}
+	return err
+}
+
+func (r *commentRepository) SetResolved(ctx context.Context, threadID bson.ObjectID, resolved bool, userID string) (bool, error) {
+	now := time.Now().Format(time.RFC3339)
+	// Only flip threads that are in the opposite state
+	filter := bson.M{"_id": threadID, "resolved_at": bson.M{"$exists": !resolved}}
+	var update bson.M
+	if resolved {
+		update = bson.M{"$set": bson.M{"resolved_at": now, "resolved_by": userID, "updated_at": now}}
+	} else {
+		update = bson.M{
+			"$set":   bson.M{"updated_at": now},
+			"$unset": bson.M{"resolved_at": "", "resolved_by": ""},
+		}
+	}
+	res, err := r.threads.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// SetOrphaned flags the threads anchored to the given elements and returns the
+// IDs of the threads whose flag changed.
+func (r *commentRepository) SetOrphaned(ctx context.Context, projectID bson.ObjectID, elementIDs []string, orphaned bool) ([]bson.ObjectID, error) {
+	if len(elementIDs) == 0 {
+		return nil, nil
+	}
+	filter := bson.M{
+		"project_id": projectID,
+		"element_id": bson.M{"$in": elementIDs},
+		"orphaned":   !orphaned,
+	}
+	cursor, err := r.threads.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var threads []struct {
+		ID bson.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &threads); err != nil {
+		return nil, err
+	}
+	if len(threads) == 0 {
+		return nil, nil
+	}
+	ids := make([]bson.ObjectID, 0, len(threads))
+	for _, t := range threads {
+		ids = append(ids, t.ID)
+	}
+	_, err = r.threads.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}}, bson.M{
+		"$set": bson.M{
+			"orphaned":   orphaned,
+			"updated_at": time.Now().Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *commentRepository) DeleteByProjects(ctx context.Context, projectIDs []bson.ObjectID) error {
+	if len(projectIDs) == 0 {
+		return nil
+	}
+	_, err := r.comments.DeleteMany(ctx, bson.M{"project_id": bson.M{"$in": projectIDs}})
+	if err != nil {
+		return err
+	}
+	_, err = r.threads.DeleteMany(ctx, bson.M{"project_id": bson.M{"$in": projectIDs}})
+	return err
+}
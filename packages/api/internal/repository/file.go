@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/config"
+	"github.com/chirag3003/collab-draw-backend/internal/db"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type fileRepository struct {
+	files *mongo.Collection
+	blobs *mongo.Collection
+}
+
+type FileRepository interface {
+	CreateFile(ctx context.Context, data *models.File) error
+	GetFile(ctx context.Context, projectID bson.ObjectID, fileID string) (*models.File, error)
+	GetFilesByProject(ctx context.Context, projectID bson.ObjectID) ([]*models.File, error)
+	GetProjectIDs(ctx context.Context) ([]bson.ObjectID, error)
+	DeleteFiles(ctx context.Context, ids []bson.ObjectID) error
+	DeleteByProjects(ctx context.Context, projectIDs []bson.ObjectID) error
+	TouchBlob(ctx context.Context, blob *models.Blob) error
+	GetOrphanedBlobs(ctx context.Context, unusedSince time.Time) ([]*models.Blob, error)
+	DeleteBlob(ctx context.Context, hash string, unusedSince time.Time) (bool, error)
+}
+
+func NewFileRepository() FileRepository {
+	files := db.GetCollection(config.FILES)
+	_, _ = files.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "project_id", Value: 1},
+				{Key: "file_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "hash", Value: 1}},
+		},
+	})
+
+	return &fileRepository{
+		files: files,
+		blobs: db.GetCollection(config.BLOBS),
+	}
+}
+
+func (r *fileRepository) CreateFile(ctx context.Context, data *models.File) error {
+	data.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	res, err := r.files.InsertOne(ctx, data)
+	if err != nil {
+		return err
+	}
+	if id, ok := res.InsertedID.(bson.ObjectID); ok {
+		data.ID = id
+	}
+	return nil
+}
+
+func (r *fileRepository) GetFile(ctx context.Context, projectID bson.ObjectID, fileID string) (*models.File, error) {
+	var file models.File
+	err := r.files.FindOne(ctx, bson.M{"project_id": projectID, "file_id": fileID}).Decode(&file)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &file, nil
+}
+
+func (r *fileRepository) GetFilesByProject(ctx context.Context, projectID bson.ObjectID) ([]*models.File, error) {
+	var files []*models.File
+	cursor, err := r.files.Find(ctx, bson.M{"project_id": projectID})
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// GetProjectIDs returns every project that has files.
+func (r *fileRepository) GetProjectIDs(ctx context.Context) ([]bson.ObjectID, error) {
+	var ids []bson.ObjectID
+	err := r.files.Distinct(ctx, "project_id", bson.M{}).Decode(&ids)
+	return ids, err
+}
+
+func (r *fileRepository) DeleteFiles(ctx context.Context, ids []bson.ObjectID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := r.files.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
+
+// DeleteByProjects unlinks every file of the given projects. The contents are
+// removed later by garbage collection once no other project uses them.
+func (r *fileRepository) DeleteByProjects(ctx context.Context, projectIDs []bson.ObjectID) error {
+	if len(projectIDs) == 0 {
+		return nil
+	}
+	_, err := r.files.DeleteMany(ctx, bson.M{"project_id": bson.M{"$in": projectIDs}})
+	return err
+}
+
+// TouchBlob records the blob, or marks an existing one as just used so garbage
+// collection leaves it alone while a file is being linked to it.
+func (r *fileRepository) TouchBlob(ctx context.Context, blob *models.Blob) error {
+	now := time.Now()
+	_, err := r.blobs.UpdateOne(ctx,
+		bson.M{"_id": blob.Hash},
+		bson.M{
+			"$set": bson.M{"last_used_at": now},
+			"$setOnInsert": bson.M{
+				"mime_type":  blob.MimeType,
+				"size":       blob.Size,
+				"created_at": now.UTC().Format(time.RFC3339),
+			},
+		},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+// GetOrphanedBlobs returns blobs no file or project thumbnail links to that
+// have not been used since the given time.
+func (r *fileRepository) GetOrphanedBlobs(ctx context.Context, unusedSince time.Time) ([]*models.Blob, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"last_used_at": bson.M{"$lt": unusedSince}}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         config.FILES,
+			"localField":   "_id",
+			"foreignField": "hash",
+			"as":           "files",
+			"pipeline":     bson.A{bson.M{"$limit": 1}},
+		}}},
+		{{Key: "$match", Value: bson.M{"files": bson.M{"$size": 0}}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         config.PROJECT,
+			"localField":   "_id",
+			"foreignField": "thumbnail_hash",
+			"as":           "thumbnails",
+			"pipeline":     bson.A{bson.M{"$limit": 1}, bson.M{"$project": bson.M{"_id": 1}}},
+		}}},
+		{{Key: "$match", Value: bson.M{"thumbnails": bson.M{"$size": 0}}}},
+		{{Key: "$project", Value: bson.M{"files": 0, "thumbnails": 0}}},
+	}
+	cursor, err := r.blobs.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var blobs []*models.Blob
+	if err = cursor.All(ctx, &blobs); err != nil {
+		return nil, err
+	}
+	return blobs, nil
+}
+
+// DeleteBlob removes the blob record unless it was used since the given time,
+// and reports whether it did.
+func (r *fileRepository) DeleteBlob(ctx context.Context, hash string, unusedSince time.Time) (bool, error) {
+	res, err := r.blobs.DeleteOne(ctx, bson.M{"_id": hash, "last_used_at": bson.M{"$lt": unusedSince}})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
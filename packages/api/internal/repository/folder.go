@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/config"
+	"github.com/chirag3003/collab-draw-backend/internal/db"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type folderRepository struct {
+	folders  *mongo.Collection
+	projects *mongo.Collection
+}
+
+type FolderRepository interface {
+	CreateFolder(ctx context.Context, data *models.Folder) error
+	GetFolder(ctx context.Context, id string) (*models.Folder, error)
+	GetFoldersByWorkspace(ctx context.Context, workspaceID bson.ObjectID) ([]*models.Folder, error)
+	RenameFolder(ctx context.Context, id bson.ObjectID, name string) error
+	MoveFolder(ctx context.Context, id bson.ObjectID, parentID *bson.ObjectID) error
+	DeleteFolder(ctx context.Context, folder *models.Folder) error
+	DeleteByWorkspace(ctx context.Context, workspaceID bson.ObjectID) error
+}
+
+func NewFolderRepository() FolderRepository {
+	folders := db.GetCollection(config.FOLDERS)
+	_, _ = folders.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "workspace_id", Value: 1},
+				{Key: "parent_id", Value: 1},
+			},
+		},
+	})
+	return &folderRepository{
+		folders:  folders,
+		projects: db.GetCollection(config.PROJECT),
+	}
+}
+
+func (r *folderRepository) CreateFolder(ctx context.Context, data *models.Folder) error {
+	data.CreatedAt = time.Now().Format(time.RFC3339)
+	data.UpdatedAt = data.CreatedAt
+	res, err := r.folders.InsertOne(ctx, data)
+	if err != nil {
+		return err
+	}
+	if id, ok := res.InsertedID.(bson.ObjectID); ok {
+		data.ID = id
+	}
+	return nil
+}
+
+func (r *folderRepository) GetFolder(ctx context.Context, id string) (*models.Folder, error) {
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	var folder models.Folder
+	err = r.folders.FindOne(ctx, bson.M{"_id": ID}).Decode(&folder)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &folder, nil
+}
+
+func (r *folderRepository) GetFoldersByWorkspace(ctx context.Context, workspaceID bson.ObjectID) ([]*models.Folder, error) {
+	cursor, err := r.folders.Find(ctx, bson.M{"workspace_id": workspaceID},
+		options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	folders := []*models.Folder{}
+	if err = cursor.All(ctx, &folders); err != nil {
+		return nil, err
+	}
+	return folders, nil
+}
+
+func (r *folderRepository) RenameFolder(ctx context.Context, id bson.ObjectID, name string) error {
+	_, err := r.folders.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{
+			"name":       name,
+			"updated_at": time.Now().Format(time.RFC3339),
+		},
+	})
+	return err
+}
+
+// MoveFolder sets the folder's parent; a nil parent moves it to the top level.
+// Callers are responsible for not creating cycles.
+func (r *folderRepository) MoveFolder(ctx context.Context, id bson.ObjectID, parentID *bson.ObjectID) error {
+	update := bson.M{"$set": bson.M{"updated_at": time.Now().Format(time.RFC3339)}}
+	if parentID == nil {
+		update["$unset"] = bson.M{"parent_id": ""}
+	} else {
+		update["$set"].(bson.M)["parent_id"] = *parentID
+	}
+	_, err := r.folders.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// DeleteFolder removes a folder. Its subfolders and projects move up to the
+// folder's parent rather than being deleted with it.
+func (r *folderRepository) DeleteFolder(ctx context.Context, folder *models.Folder) error {
+	parent := bson.M{"$unset": bson.M{"parent_id": ""}}
+	project := bson.M{"$unset": bson.M{"folder_id": ""}}
+	if folder.ParentID != nil {
+		parent = bson.M{"$set": bson.M{"parent_id": *folder.ParentID}}
+		project = bson.M{"$set": bson.M{"folder_id": *folder.ParentID}}
+	}
+	if _, err := r.folders.UpdateMany(ctx, bson.M{"parent_id": folder.ID}, parent); err != nil {
+		return err
+	}
+	if _, err := r.projects.UpdateMany(ctx, bson.M{"folder_id": folder.ID}, project); err != nil {
+		return err
+	}
+	_, err := r.folders.DeleteOne(ctx, bson.M{"_id": folder.ID})
+	return err
+}
+
+func (r *folderRepository) DeleteByWorkspace(ctx context.Context, workspaceID bson.ObjectID) error {
+	_, err := r.folders.DeleteMany(ctx, bson.M{"workspace_id": workspaceID})
+	return err
+}
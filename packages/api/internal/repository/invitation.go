@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/config"
+	"github.com/chirag3003/collab-draw-backend/internal/db"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type invitationRepository struct {
+	invitations *mongo.Collection
+}
+
+type InvitationRepository interface {
+	CreateInvitation(ctx context.Context, data *models.Invitation) error
+	GetInvitationByID(ctx context.Context, id string) (*models.Invitation, error)
+	GetPendingByWorkspace(ctx context.Context, workspaceID string) ([]*models.Invitation, error)
+	GetPendingByEmail(ctx context.Context, email string) ([]*models.Invitation, error)
+	GetAutoAcceptByEmail(ctx context.Context, email string) ([]*models.Invitation, error)
+	RevokePending(ctx context.Context, workspaceID bson.ObjectID, email string) error
+	SetStatus(ctx context.Context, id bson.ObjectID, status string, userID string) (bool, error)
+	DeleteByWorkspace(ctx context.Context, workspaceID bson.ObjectID) error
+}
+
+func NewInvitationRepository() InvitationRepository {
+	invitations := db.GetCollection(config.INVITATIONS)
+
+	indexModels := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "workspace_id", Value: 1},
+				{Key: "status", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "email", Value: 1},
+				{Key: "status", Value: 1},
+			},
+		},
+	}
+	_, _ = invitations.Indexes().CreateMany(context.Background(), indexModels)
+
+	return &invitationRepository{
+		invitations: invitations,
+	}
+}
+
+// NormalizeEmail lower-cases and trims an email so lookups are case-insensitive.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func (r *invitationRepository) CreateInvitation(ctx context.Context, data *models.Invitation) error {
+	data.Email = NormalizeEmail(data.Email)
+	data.Status = models.InvitationPending
+	data.CreatedAt = time.Now().Format(time.RFC3339)
+	res, err := r.invitations.InsertOne(ctx, data)
+	if err != nil {
+		return err
+	}
+	if id, ok := res.InsertedID.(bson.ObjectID); ok {
+		data.ID = id
+	}
+	return nil
+}
+
+func (r *invitationRepository) GetInvitationByID(ctx context.Context, id string) (*models.Invitation, error) {
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	var invitation models.Invitation
+	err = r.invitations.FindOne(ctx, bson.M{"_id": ID}).Decode(&invitation)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// pendingFilter matches invitations that can still be accepted.
+func pendingFilter() bson.M {
+	return bson.M{
+		"status":     models.InvitationPending,
+		"expires_at": bson.M{"$gt": time.Now().Format(time.RFC3339)},
+	}
+}
+
+func (r *invitationRepository) findPending(ctx context.Context, filter bson.M) ([]*models.Invitation, error) {
+	for k, v := range pendingFilter() {
+		filter[k] = v
+	}
+	var invitations []*models.Invitation
+	findOpts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.invitations.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &invitations); err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (r *invitationRepository) GetPendingByWorkspace(ctx context.Context, workspaceID string) ([]*models.Invitation, error) {
+	ID, err := bson.ObjectIDFromHex(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return r.findPending(ctx, bson.M{"workspace_id": ID})
+}
+
+func (r *invitationRepository) GetPendingByEmail(ctx context.Context, email string) ([]*models.Invitation, error) {
+	return r.findPending(ctx, bson.M{"email": NormalizeEmail(email)})
+}
+
+func (r *invitationRepository) GetAutoAcceptByEmail(ctx context.Context, email string) ([]*models.Invitation, error) {
+	return r.findPending(ctx, bson.M{"email": NormalizeEmail(email), "auto_accept": true})
+}
+
+// RevokePending revokes any pending invitation for the email, so that
+// re-inviting someone replaces their previous invitation.
+func (r *invitationRepository) RevokePending(ctx context.Context, workspaceID bson.ObjectID, email string) error {
+	_, err := r.invitations.UpdateMany(ctx, bson.M{
+		"workspace_id": workspaceID,
+		"email":        NormalizeEmail(email),
+		"status":       models.InvitationPending,
+	}, bson.M{
+		"$set": bson.M{
+			"status":       models.InvitationRevoked,
+			"responded_at": time.Now().Format(time.RFC3339),
+		},
+	})
+	return err
+}
+
+// SetStatus moves a pending invitation to its final status. It returns false
+// when the invitation is no longer pending.
+func (r *invitationRepository) SetStatus(ctx context.Context, id bson.ObjectID, status string, userID string) (bool, error) {
+	res, err := r.invitations.UpdateOne(ctx, bson.M{
+		"_id":    id,
+		"status": models.InvitationPending,
+	}, bson.M{
+		"$set": bson.M{
+			"status":       status,
+			"responded_by": userID,
+			"responded_at": time.Now().Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+func (r *invitationRepository) DeleteByWorkspace(ctx context.Context, workspaceID bson.ObjectID) error {
+	_, err := r.invitations.DeleteMany(ctx, bson.M{"workspace_id": workspaceID})
+	return err
+}
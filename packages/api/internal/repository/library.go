@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/config"
+	"github.com/chirag3003/collab-draw-backend/internal/db"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type libraryRepository struct {
+	libraries *mongo.Collection
+}
+
+type LibraryRepository interface {
+	CreateLibrary(ctx context.Context, data *models.Library) error
+	GetLibrary(ctx context.Context, id string) (*models.Library, error)
+	GetLibrariesByWorkspace(ctx context.Context, workspaceID bson.ObjectID, tag string) ([]*models.Library, error)
+	UpdateLibrary(ctx context.Context, id bson.ObjectID, name string, description string) error
+	AddItems(ctx context.Context, id bson.ObjectID, items []models.LibraryItem) error
+	UpdateItem(ctx context.Context, id bson.ObjectID, item *models.LibraryItem) (bool, error)
+	RemoveItem(ctx context.Context, id bson.ObjectID, itemID string) (bool, error)
+	DeleteLibrary(ctx context.Context, id bson.ObjectID) error
+	DeleteByWorkspace(ctx context.Context, workspaceID bson.ObjectID) error
+}
+
+func NewLibraryRepository() LibraryRepository {
+	libraries := db.GetCollection(config.LIBRARIES)
+	_, _ = libraries.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "workspace_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{
+				{Key: "workspace_id", Value: 1},
+				{Key: "items.tags", Value: 1},
+			},
+		},
+	})
+	return &libraryRepository{
+		libraries: libraries,
+	}
+}
+
+func (r *libraryRepository) CreateLibrary(ctx context.Context, data *models.Library) error {
+	data.CreatedAt = time.Now().Format(time.RFC3339)
+	data.UpdatedAt = data.CreatedAt
+	if data.Items == nil {
+		data.Items = []models.LibraryItem{}
+	}
+	res, err := r.libraries.InsertOne(ctx, data)
+	if err != nil {
+		return err
+	}
+	if id, ok := res.InsertedID.(bson.ObjectID); ok {
+		data.ID = id
+	}
+	return nil
+}
+
+func (r *libraryRepository) GetLibrary(ctx context.Context, id string) (*models.Library, error) {
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	var library models.Library
+	err = r.libraries.FindOne(ctx, bson.M{"_id": ID}).Decode(&library)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &library, nil
+}
+
+// GetLibrariesByWorkspace returns the libraries of a workspace sorted by name.
+// When tag is set only libraries with an item carrying it are returned.
+func (r *libraryRepository) GetLibrariesByWorkspace(ctx context.Context, workspaceID bson.ObjectID, tag string) ([]*models.Library, error) {
+	filter := bson.M{"workspace_id": workspaceID}
+	if tag != "" {
+		filter["items.tags"] = tag
+	}
+	cursor, err := r.libraries.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var libraries []*models.Library
+	if err = cursor.All(ctx, &libraries); err != nil {
+		return nil, err
+	}
+	return libraries, nil
+}
+
+func (r *libraryRepository) UpdateLibrary(ctx context.Context, id bson.ObjectID, name string, description string) error {
+	_, err := r.libraries.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{
+			"name":        name,
+			"description": description,
+			"updated_at":  time.Now().Format(time.RFC3339),
+		},
+	})
+	return err
+}
+
+// AddItems appends items to a library, stamping their timestamps.
+func (r *libraryRepository) AddItems(ctx context.Context, id bson.ObjectID, items []models.LibraryItem) error {
+	now := time.Now().Format(time.RFC3339)
+	for i := range items {
+		items[i].CreatedAt = now
+		items[i].UpdatedAt = now
+	}
+	_, err := r.libraries.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$push": bson.M{"items": bson.M{"$each": items}},
+		"$set":  bson.M{"updated_at": now},
+	})
+	return err
+}
+
+// UpdateItem replaces the name, tags and elements of an item and reports
+// whether the item exists.
+func (r *libraryRepository) UpdateItem(ctx context.Context, id bson.ObjectID, item *models.LibraryItem) (bool, error) {
+	now := time.Now().Format(time.RFC3339)
+	item.UpdatedAt = now
+	res, err := r.libraries.UpdateOne(ctx, bson.M{"_id": id, "items.id": item.ID}, bson.M{
+		"$set": bson.M{
+			"items.$.name":       item.Name,
+			"items.$.tags":       item.Tags,
+			"items.$.elements":   item.Elements,
+			"items.$.updated_at": now,
+			"updated_at":         now,
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// RemoveItem deletes an item and reports whether it existed.
+func (r *libraryRepository) RemoveItem(ctx context.Context, id bson.ObjectID, itemID string) (bool, error) {
+	res, err := r.libraries.UpdateOne(ctx, bson.M{"_id": id, "items.id": itemID}, bson.M{
+		"$pull": bson.M{"items": bson.M{"id": itemID}},
+		"$set":  bson.M{"updated_at": time.Now().Format(time.RFC3339)},
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+func (r *libraryRepository) DeleteLibrary(ctx context.Context, id bson.ObjectID) error {
+	_, err := r.libraries.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// DeleteByWorkspace removes every library of a workspace.
+func (r *libraryRepository) DeleteByWorkspace(ctx context.Context, workspaceID bson.ObjectID) error {
+	_, err := r.libraries.DeleteMany(ctx, bson.M{"workspace_id": workspaceID})
+	return err
+}
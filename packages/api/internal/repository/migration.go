@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/config"
+	"github.com/chirag3003/collab-draw-backend/internal/db"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type migrationRepository struct {
+	migrations *mongo.Collection
+}
+
+// MigrationRepository records which data migrations have run, so that each
+// runs once even with several instances starting together.
+type MigrationRepository interface {
+	Claim(ctx context.Context, name string, lease time.Duration) (bool, error)
+	Complete(ctx context.Context, name string) error
+	Release(ctx context.Context, name string) error
+}
+
+func NewMigrationRepository() MigrationRepository {
+	return &migrationRepository{migrations: db.GetCollection(config.MIGRATIONS)}
+}
+
+// Claim reserves a migration for this instance. It returns false when the
+// migration has completed, or another instance claimed it less than lease ago.
+func (r *migrationRepository) Claim(ctx context.Context, name string, lease time.Duration) (bool, error) {
+	now := time.Now().UTC()
+	_, err := r.migrations.UpdateOne(ctx, bson.M{
+		"_id":          name,
+		"completed_at": bson.M{"$exists": false},
+		"started_at":   bson.M{"$lt": now.Add(-lease)},
+	}, bson.M{
+		"$set": bson.M{"started_at": now},
+	}, options.UpdateOne().SetUpsert(true))
+	if err != nil {
+		// The upsert collides with a record that did not match the filter
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *migrationRepository) Complete(ctx context.Context, name string) error {
+	_, err := r.migrations.UpdateOne(ctx, bson.M{"_id": name}, bson.M{
+		"$set": bson.M{"completed_at": time.Now().UTC()},
+	})
+	return err
+}
+
+// Release gives up a claim so the migration is retried on the next start.
+func (r *migrationRepository) Release(ctx context.Context, name string) error {
+	_, err := r.migrations.DeleteOne(ctx, bson.M{"_id": name, "completed_at": bson.M{"$exists": false}})
+	return err
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/config"
+	"github.com/chirag3003/collab-draw-backend/internal/db"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const notificationPageSize = 30
+
+type notificationRepository struct {
+	notifications *mongo.Collection
+	preferences   *mongo.Collection
+}
+
+type NotificationRepository interface {
+	CreateNotification(ctx context.Context, data *models.Notification) error
+	GetNotifications(ctx context.Context, userID string, unreadOnly bool, cursor string) ([]*models.Notification, string, error)
+	CountUnread(ctx context.Context, userID string) (int64, error)
+	MarkRead(ctx context.Context, userID string, ids []bson.ObjectID) (int64, error)
+	GetPreferences(ctx context.Context, userID string) (*models.NotificationPreferences, error)
+	SetPreferences(ctx context.Context, prefs *models.NotificationPreferences) error
+	DeleteByProjects(ctx context.Context, projectIDs []bson.ObjectID) error
+	DeleteByWorkspace(ctx context.Context, workspaceID bson.ObjectID) error
+}
+
+func NewNotificationRepository() NotificationRepository {
+	notifications := db.GetCollection(config.NOTIFICATIONS)
+
+	_, _ = notifications.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "user_id", Value: 1},
+			{Key: "_id", Value: -1},
+		},
+	})
+
+	return &notificationRepository{
+		notifications: notifications,
+		preferences:   db.GetCollection(config.NOTIFICATION_PREFERENCES),
+	}
+}
+
+func (r *notificationRepository) CreateNotification(ctx context.Context, data *models.Notification) error {
+	data.CreatedAt = time.Now().Format(time.RFC3339)
+	res, err := r.notifications.InsertOne(ctx, data)
+	if err != nil {
+		return err
+	}
+	if id, ok := res.InsertedID.(bson.ObjectID); ok {
+		data.ID = id
+	}
+	return nil
+}
+
+// GetNotifications returns a page of the user's notifications, newest first.
+// The returned cursor is empty on the last page.
+func (r *notificationRepository) GetNotifications(ctx context.Context, userID string, unreadOnly bool, cursor string) ([]*models.Notification, string, error) {
+	query := bson.M{"user_id": userID}
+	if unreadOnly {
+		query["read_at"] = bson.M{"$exists": false}
+	}
+	if cursor != "" {
+		after, err := bson.ObjectIDFromHex(cursor)
+		if err != nil {
+			return nil, "", errors.New("invalid cursor")
+		}
+		query["_id"] = bson.M{"$lt": after}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(notificationPageSize + 1)
+	res, err := r.notifications.Find(ctx, query, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	var notifications []*models.Notification
+	if err = res.All(ctx, &notifications); err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(notifications) > notificationPageSize {
+		notifications = notifications[:notificationPageSize]
+		next = notifications[len(notifications)-1].ID.Hex()
+	}
+	return notifications, next, nil
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, userID string) (int64, error) {
+	return r.notifications.CountDocuments(ctx, bson.M{
+		"user_id": userID,
+		"read_at": bson.M{"$exists": false},
+	})
+}
+
+// MarkRead marks the given notifications as read, or all of them when ids is
+// empty.
+func (r *notificationRepository) MarkRead(ctx context.Context, userID string, ids []bson.ObjectID) (int64, error) {
+	filter := bson.M{
+		"user_id": userID,
+		"read_at": bson.M{"$exists": false},
+	}
+	if len(ids) > 0 {
+		filter["_id"] = bson.M{"$in": ids}
+	}
+	res, err := r.notifications.UpdateMany(ctx, filter, bson.M{
+		"$set": bson.M{"read_at": time.Now().Format(time.RFC3339)},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// GetPreferences returns the user's preferences, or empty ones when they never
+// changed them.
+func (r *notificationRepository) GetPreferences(ctx context.Context, userID string) (*models.NotificationPreferences, error) {
+	prefs := models.NotificationPreferences{
+		UserID:          userID,
+		MutedTypes:      []string{},
+		MutedWorkspaces: []bson.ObjectID{},
+	}
+	err := r.preferences.FindOne(ctx, bson.M{"_id": userID}).Decode(&prefs)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+func (r *notificationRepository) SetPreferences(ctx context.Context, prefs *models.NotificationPreferences) error {
+	_, err := r.preferences.ReplaceOne(ctx, bson.M{"_id": prefs.UserID}, prefs, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *notificationRepository) DeleteByProjects(ctx context.Context, projectIDs []bson.ObjectID) error {
+	if len(projectIDs) == 0 {
+		return nil
+	}
+	_, err := r.notifications.DeleteMany(ctx, bson.M{"project_id": bson.M{"$in": projectIDs}})
+	return err
+}
+
+func (r *notificationRepository) DeleteByWorkspace(ctx context.Context, workspaceID bson.ObjectID) error {
+	_, err := r.notifications.DeleteMany(ctx, bson.M{"workspace_id": workspaceID})
+	return err
+}
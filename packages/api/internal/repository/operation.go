@@ -2,12 +2,15 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/chirag3003/collab-draw-backend/internal/config"
 	"github.com/chirag3003/collab-draw-backend/internal/db"
+	"github.com/chirag3003/collab-draw-backend/internal/element"
 	"github.com/chirag3003/collab-draw-backend/internal/models"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
@@ -29,10 +32,46 @@ type OpInput struct {
 }
 
 type OperationRepository interface {
-	ApplyOps(ctx context.Context, projectID string, socketID string, ops []OpInput, userID string) (*ApplyOpsResult, error)
+	ApplyOps(ctx context.Context, projectID string, socketID string, userID string, ops []OpInput) (*ApplyOpsResult, error)
 	GetOpsSince(ctx context.Context, projectID string, sinceSeq int32, limit *int32) ([]*models.Operation, error)
 	GetOpsRange(ctx context.Context, projectID string, fromSeq int32, toSeq int32) ([]*models.Operation, error)
 	ReconstructStateAt(ctx context.Context, projectID string, seq int32, userID string) (string, int64, string, error)
+	DeleteByProjects(ctx context.Context, projectIDs []bson.ObjectID) error
+	GetReferencedFileIDs(ctx context.Context, projectID bson.ObjectID) (map[string]bool, error)
+	GetActivity(ctx context.Context, projectIDs []bson.ObjectID, since time.Time) (*Activity, error)
+	GetLastEdit(ctx context.Context, projectID bson.ObjectID) (*models.Operation, error)
+	GetActivityFeed(ctx context.Context, query FeedQuery) (*FeedPage, error)
+	BackfillOpTimes(ctx context.Context) (int64, error)
+}
+
+// Activity summarizes the ops logged on a set of projects. Ops logged before
+// user IDs were recorded count towards an empty user ID.
+type Activity struct {
+	TotalOps  int               `bson:"total"`
+	Editors   int               `bson:"editors"`
+	ByUser    []UserActivity    `bson:"by_user"`    // most ops first
+	Daily     []DailyActivity   `bson:"daily"`      // oldest day first, days without ops are left out
+	ByProject []ProjectActivity `bson:"by_project"` // most ops first
+}
+
+type UserActivity struct {
+	UserID   string    `bson:"_id"`
+	Ops      int       `bson:"ops"`
+	LastOpAt time.Time `bson:"last_op_at"`
+}
+
+type DailyActivity struct {
+	Date    string `bson:"_id"` // YYYY-MM-DD in UTC
+	Ops     int    `bson:"ops"`
+	Editors int    `bson:"editors"`
+}
+
+type ProjectActivity struct {
+	ProjectID  bson.ObjectID `bson:"_id"`
+	Ops        int           `bson:"ops"`
+	Editors    int           `bson:"editors"`
+	LastUserID string        `bson:"last_user_id"`
+	LastOpAt   time.Time     `bson:"last_op_at"`
 }
 
 type ApplyOpsResult struct {
@@ -66,6 +105,12 @@ func NewOperationRepository() OperationRepository {
 				{Key: "seq", Value: -1},
 			},
 		},
+		{
+			Keys: bson.D{
+				{Key: "project_id", Value: 1},
+				{Key: "at", Value: -1},
+			},
+		},
 	}
 	_, _ = ops.Indexes().CreateMany(context.Background(), indexModels)
 
@@ -75,28 +120,54 @@ func NewOperationRepository() OperationRepository {
 	}
 }
 
-func (r *operationRepository) ApplyOps(ctx context.Context, projectID string, socketID string, ops []OpInput, userID string) (*ApplyOpsResult, error) {
+// Limits on a single ApplyOps call. Larger changes are sent in several
+// batches.
+const (
+	MaxBatchOps   = 1000
+	MaxBatchBytes = 8 << 20 // total size of the element data
+)
+
+// ApplyOps appends ops to the project's log on behalf of the user. Callers are
+// responsible for checking that the user may edit the project. Malformed ops
+// are rejected with the reason, and a batch over the limits is refused whole.
+func (r *operationRepository) ApplyOps(ctx context.Context, projectID string, socketID string, userID string, ops []OpInput) (*ApplyOpsResult, error) {
 	projID, err := bson.ObjectIDFromHex(projectID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid project ID: %v", err)
 	}
+	if len(ops) > MaxBatchOps {
+		return nil, fmt.Errorf("batch has %d ops, more than the limit of %d", len(ops), MaxBatchOps)
+	}
+	if size := batchBytes(ops); size > MaxBatchBytes {
+		return nil, fmt.Errorf("batch carries %d bytes of element data, more than the limit of %d", size, MaxBatchBytes)
+	}
+
+	// Reject malformed ops before claiming sequence numbers for the rest
+	result := &ApplyOpsResult{Ack: true}
+	valid := make([]OpInput, 0, len(ops))
+	for _, op := range ops {
+		if reason := validateOp(op); reason != "" {
+			result.Rejected = append(result.Rejected, RejectedOp{
+				ClientSeq: op.ClientSeq,
+				ElementID: op.ElementID,
+				Reason:    reason,
+			})
+			continue
+		}
+		valid = append(valid, op)
+	}
+	ops = valid
 
 	batchSize := int64(len(ops))
 	if batchSize == 0 {
-		return &ApplyOpsResult{Ack: true, ServerSeq: 0}, nil
+		return result, nil
 	}
 
 	// Atomically claim sequence numbers using FindOneAndUpdate with $inc
 	var updatedProject models.Project
 	err = r.projects.FindOneAndUpdate(
 		ctx,
-		bson.M{
-			"_id": projID,
-			"$or": bson.A{
-				bson.M{"owner": userID},
-				bson.M{"members": userID},
-			},
-		},
+		bson.M{"_id": projID},
 		bson.M{
 			"$inc": bson.M{"head_seq": batchSize},
 			"$set": bson.M{"updated_at": time.Now().Format(time.RFC3339)},
@@ -111,10 +182,7 @@ func (r *operationRepository) ApplyOps(ctx context.Context, projectID string, so
 	// Our claimed range is [head_seq - batchSize + 1, head_seq]
 	startSeq := updatedProject.HeadSeq - batchSize + 1
 
-	result := &ApplyOpsResult{
-		Ack:       true,
-		ServerSeq: updatedProject.HeadSeq,
-	}
+	result.ServerSeq = updatedProject.HeadSeq
 
 	// For conflict detection: find the latest op for each element referenced in this batch
 	elementIDs := make([]string, 0, len(ops))
@@ -182,18 +250,21 @@ func (r *operationRepository) ApplyOps(ctx context.Context, projectID string, so
 			}
 		}
 
-		now := time.Now().Format(time.RFC3339Nano)
+		now := time.Now()
 		opDoc := &models.Operation{
-			ProjectID:  projID,
-			Seq:        seq,
-			ClientSeq:  int(op.ClientSeq),
-			SocketID:   socketID,
-			Type:       op.Type,
-			ElementID:  op.ElementID,
-			ElementVer: int(op.ElementVer),
-			BaseSeq:    int(op.BaseSeq),
-			Data:       op.Data,
-			Timestamp:  now,
+			ProjectID:   projID,
+			Seq:         seq,
+			ClientSeq:   int(op.ClientSeq),
+			SocketID:    socketID,
+			UserID:      userID,
+			Type:        op.Type,
+			ElementID:   op.ElementID,
+			ElementType: elementType(op.Data),
+			ElementVer:  int(op.ElementVer),
+			BaseSeq:     int(op.BaseSeq),
+			Data:        op.Data,
+			Timestamp:   now.Format(time.RFC3339Nano),
+			At:          now.UTC(),
 		}
 		acceptedOps = append(acceptedOps, opDoc)
 		docsToInsert = append(docsToInsert, opDoc)
@@ -299,8 +370,9 @@ func (r *operationRepository) applyOpsToElements(ctx context.Context, projID bso
 
 	_, err = r.projects.UpdateOne(ctx, bson.M{"_id": projID}, bson.M{
 		"$set": bson.M{
-			"elements":   string(elemBytes),
-			"updated_at": time.Now().Format(time.RFC3339),
+			"elements":      string(elemBytes),
+			"element_count": CountElements(string(elemBytes)),
+			"updated_at":    time.Now().Format(time.RFC3339),
 		},
 	})
 	return err
@@ -429,3 +501,394 @@ func (r *operationRepository) ReconstructStateAt(ctx context.Context, projectID
 
 	return string(elemBytes), lastSeq, lastTimestamp, nil
 }
+
+// GetActivity aggregates the ops logged on the projects since the given time.
+func (r *operationRepository) GetActivity(ctx context.Context, projectIDs []bson.ObjectID, since time.Time) (*Activity, error) {
+	activity := &Activity{ByUser: []UserActivity{}, Daily: []DailyActivity{}, ByProject: []ProjectActivity{}}
+	if len(projectIDs) == 0 {
+		return activity, nil
+	}
+	user := bson.M{"$ifNull": bson.A{"$user_id", ""}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"project_id": bson.M{"$in": projectIDs}, "at": bson.M{"$gte": since}}}},
+		{{Key: "$facet", Value: bson.M{
+			"total": bson.A{
+				bson.M{"$group": bson.M{"_id": nil, "ops": bson.M{"$sum": 1}, "users": bson.M{"$addToSet": user}}},
+			},
+			"by_user": bson.A{
+				bson.M{"$group": bson.M{"_id": user, "ops": bson.M{"$sum": 1}, "last_op_at": bson.M{"$max": "$at"}}},
+				bson.M{"$sort": bson.D{{Key: "ops", Value: -1}, {Key: "_id", Value: 1}}},
+			},
+			"daily": bson.A{
+				bson.M{"$group": bson.M{
+					"_id": bson.M{"day": bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$at"}}, "user": user},
+					"ops": bson.M{"$sum": 1},
+				}},
+				bson.M{"$group": bson.M{"_id": "$_id.day", "ops": bson.M{"$sum": "$ops"}, "editors": bson.M{"$sum": 1}}},
+				bson.M{"$sort": bson.M{"_id": 1}},
+			},
+			"by_project": bson.A{
+				bson.M{"$sort": bson.M{"seq": -1}},
+				bson.M{"$group": bson.M{
+					"_id":          "$project_id",
+					"ops":          bson.M{"$sum": 1},
+					"users":        bson.M{"$addToSet": user},
+					"last_user_id": bson.M{"$first": user},
+					"last_op_at":   bson.M{"$first": "$at"},
+				}},
+				bson.M{"$addFields": bson.M{"editors": bson.M{"$size": "$users"}}},
+				bson.M{"$sort": bson.D{{Key: "ops", Value: -1}, {Key: "_id", Value: 1}}},
+			},
+		}}},
+	}
+	cursor, err := r.operations.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var results []struct {
+		Total []struct {
+			Ops   int      `bson:"ops"`
+			Users []string `bson:"users"`
+		} `bson:"total"`
+		ByUser    []UserActivity    `bson:"by_user"`
+		Daily     []DailyActivity   `bson:"daily"`
+		ByProject []ProjectActivity `bson:"by_project"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return activity, nil
+	}
+	result := results[0]
+	if len(result.Total) > 0 {
+		activity.TotalOps = result.Total[0].Ops
+		activity.Editors = len(result.Total[0].Users)
+	}
+	if result.ByUser != nil {
+		activity.ByUser = result.ByUser
+	}
+	if result.Daily != nil {
+		activity.Daily = result.Daily
+	}
+	if result.ByProject != nil {
+		activity.ByProject = result.ByProject
+	}
+	return activity, nil
+}
+
+// FeedWindow is the span of time the ops of one user on one project are
+// grouped into a single feed event.
+const FeedWindow = time.Hour
+
+// FeedMaxAge is how far back the activity feed goes.
+const FeedMaxAge = 30 * 24 * time.Hour
+
+// FeedQuery selects a page of the activity feed of a set of projects.
+type FeedQuery struct {
+	ProjectIDs    []bson.ObjectID
+	ExcludeUserID string // leave out the ops of this user, when set
+	After         string // cursor of the last event of the previous page
+	Limit         int
+}
+
+// FeedEvent groups the ops one user made on one project within a FeedWindow.
+type FeedEvent struct {
+	UserID          string
+	ProjectID       bson.ObjectID
+	Ops             int
+	ElementsChanged int // distinct elements touched by the ops
+	ElementsAdded   int
+	ElementsDeleted int
+	ElementTypes    []string
+	StartedAt       time.Time
+	EndedAt         time.Time
+	Cursor          string
+}
+
+// FeedPage is one page of the activity feed, newest events first.
+type FeedPage struct {
+	Events      []FeedEvent
+	HasNextPage bool
+}
+
+type feedCursor struct {
+	Window    time.Time     `json:"w"`
+	ProjectID bson.ObjectID `json:"p"`
+	UserID    string        `json:"u"`
+}
+
+func encodeFeedCursor(c feedCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeFeedCursor(cursor string) (*feedCursor, error) {
+	var c feedCursor
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil || c.Window.IsZero() {
+		return nil, errors.New("invalid cursor")
+	}
+	return &c, nil
+}
+
+// GetActivityFeed groups the ops logged on the projects in the last FeedMaxAge
+// into events per user, project and FeedWindow, newest window first.
+func (r *operationRepository) GetActivityFeed(ctx context.Context, query FeedQuery) (*FeedPage, error) {
+	page := &FeedPage{Events: []FeedEvent{}}
+	if len(query.ProjectIDs) == 0 {
+		return page, nil
+	}
+	// Start on a window boundary so the oldest event is not cut short
+	at := bson.M{"$gte": time.Now().Add(-FeedMaxAge).Truncate(FeedWindow)}
+	var after *feedCursor
+	if query.After != "" {
+		var err error
+		if after, err = decodeFeedCursor(query.After); err != nil {
+			return nil, err
+		}
+		// Later windows were all returned on earlier pages
+		at["$lt"] = after.Window.Add(FeedWindow)
+	}
+	match := bson.M{"project_id": bson.M{"$in": query.ProjectIDs}, "at": at}
+	if query.ExcludeUserID != "" {
+		match["user_id"] = bson.M{"$ne": query.ExcludeUserID}
+	}
+
+	user := bson.M{"$ifNull": bson.A{"$user_id", ""}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"window":  bson.M{"$dateTrunc": bson.M{"date": "$at", "unit": "hour"}},
+				"project": "$project_id",
+				"user":    user,
+			},
+			"ops":      bson.M{"$sum": 1},
+			"elements": bson.M{"$addToSet": "$element_id"},
+			"added":    bson.M{"$addToSet": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$type", "ADD"}}, "$element_id", "$$REMOVE"}}},
+			"deleted":  bson.M{"$addToSet": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$type", "DELETE"}}, "$element_id", "$$REMOVE"}}},
+			"types":    bson.M{"$addToSet": "$element_type"},
+			"started":  bson.M{"$min": "$at"},
+			"ended":    bson.M{"$max": "$at"},
+		}}},
+	}
+	if after != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"$or": bson.A{
+			bson.M{"_id.window": bson.M{"$lt": after.Window}},
+			bson.M{"_id.window": after.Window, "_id.project": bson.M{"$gt": after.ProjectID}},
+			bson.M{"_id.window": after.Window, "_id.project": after.ProjectID, "_id.user": bson.M{"$gt": after.UserID}},
+		}}}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: bson.D{
+			{Key: "_id.window", Value: -1},
+			{Key: "_id.project", Value: 1},
+			{Key: "_id.user", Value: 1},
+		}}},
+		bson.D{{Key: "$limit", Value: query.Limit + 1}},
+	)
+
+	cursor, err := r.operations.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		ID struct {
+			Window    time.Time     `bson:"window"`
+			ProjectID bson.ObjectID `bson:"project"`
+			UserID    string        `bson:"user"`
+		} `bson:"_id"`
+		Ops      int       `bson:"ops"`
+		Elements []string  `bson:"elements"`
+		Added    []string  `bson:"added"`
+		Deleted  []string  `bson:"deleted"`
+		Types    []string  `bson:"types"`
+		Started  time.Time `bson:"started"`
+		Ended    time.Time `bson:"ended"`
+	}
+	if err = cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	if len(rows) > query.Limit {
+		rows = rows[:query.Limit]
+		page.HasNextPage = true
+	}
+	for _, row := range rows {
+		types := []string{}
+		for _, t := range row.Types {
+			if t != "" {
+				types = append(types, t)
+			}
+		}
+		page.Events = append(page.Events, FeedEvent{
+			UserID:          row.ID.UserID,
+			ProjectID:       row.ID.ProjectID,
+			Ops:             row.Ops,
+			ElementsChanged: len(row.Elements),
+			ElementsAdded:   len(row.Added),
+			ElementsDeleted: len(row.Deleted),
+			ElementTypes:    types,
+			StartedAt:       row.Started,
+			EndedAt:         row.Ended,
+			Cursor: encodeFeedCursor(feedCursor{
+				Window:    row.ID.Window,
+				ProjectID: row.ID.ProjectID,
+				UserID:    row.ID.UserID,
+			}),
+		})
+	}
+	return page, nil
+}
+
+// GetLastEdit returns the latest op logged on the project, or nil when it has
+// none.
+func (r *operationRepository) GetLastEdit(ctx context.Context, projectID bson.ObjectID) (*models.Operation, error) {
+	var op models.Operation
+	err := r.operations.FindOne(ctx, bson.M{"project_id": projectID},
+		options.FindOne().SetSort(bson.D{{Key: "seq", Value: -1}}).SetProjection(bson.M{"data": 0}),
+	).Decode(&op)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &op, nil
+}
+
+// BackfillOpTimes sets the date of ops logged before it was recorded from
+// their timestamp string. Returns the number of ops changed.
+func (r *operationRepository) BackfillOpTimes(ctx context.Context) (int64, error) {
+	res, err := r.operations.UpdateMany(ctx, bson.M{"at": bson.M{"$exists": false}}, mongo.Pipeline{
+		// Timestamps are stored as RFC 3339 strings in the server's time zone
+		{{Key: "$set", Value: bson.M{"at": bson.M{"$dateFromString": bson.M{"dateString": "$timestamp"}}}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// validateOp returns why the op cannot be applied, or "" when it is well formed.
+// ADD and UPDATE ops carry the whole element, which must describe the op's
+// element at the op's version; DELETE ops may carry it too.
+func validateOp(op OpInput) string {
+	if !element.ValidID(op.ElementID) {
+		return fmt.Sprintf("invalid elementID %q", op.ElementID)
+	}
+	switch op.Type {
+	case "ADD", "UPDATE":
+		if op.Data == nil {
+			return fmt.Sprintf("%s op requires element data", op.Type)
+		}
+	case "DELETE":
+	default:
+		return fmt.Sprintf("unknown op type %q", op.Type)
+	}
+	if op.ElementVer < 1 {
+		return fmt.Sprintf("elementVer must be positive, got %d", op.ElementVer)
+	}
+	if op.Data == nil {
+		return ""
+	}
+
+	fields, err := element.Validate(*op.Data)
+	if err != nil {
+		return err.Error()
+	}
+	switch {
+	case fields.ID != op.ElementID:
+		return fmt.Sprintf("element id %q does not match elementID %q", fields.ID, op.ElementID)
+	case fields.Version != int(op.ElementVer):
+		return fmt.Sprintf("element version %d does not match elementVer %d", fields.Version, op.ElementVer)
+	case op.Type == "ADD" && fields.IsDeleted:
+		return "ADD op carries a deleted element"
+	case op.Type == "DELETE" && !fields.IsDeleted:
+		return "DELETE op carries an element that is not deleted"
+	}
+	return ""
+}
+
+// SplitBatches splits ops into batches within the limits of ApplyOps, keeping
+// their order.
+func SplitBatches(ops []OpInput) [][]OpInput {
+	var batches [][]OpInput
+	var batch []OpInput
+	size := 0
+	for _, op := range ops {
+		opSize := batchBytes([]OpInput{op})
+		if len(batch) > 0 && (len(batch) == MaxBatchOps || size+opSize > MaxBatchBytes) {
+			batches = append(batches, batch)
+			batch, size = nil, 0
+		}
+		batch = append(batch, op)
+		size += opSize
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// batchBytes returns the size of the element data carried by the ops.
+func batchBytes(ops []OpInput) int {
+	size := 0
+	for _, op := range ops {
+		if op.Data != nil {
+			size += len(*op.Data)
+		}
+	}
+	return size
+}
+
+// elementType reads the Excalidraw type of the element an op carries.
+func elementType(data *string) string {
+	if data == nil {
+		return ""
+	}
+	var el struct {
+		Type string `json:"type"`
+	}
+	_ = json.Unmarshal([]byte(*data), &el)
+	return el.Type
+}
+
+// DeleteByProjects removes the whole op log of the given projects.
+func (r *operationRepository) DeleteByProjects(ctx context.Context, projectIDs []bson.ObjectID) error {
+	if len(projectIDs) == 0 {
+		return nil
+	}
+	_, err := r.operations.DeleteMany(ctx, bson.M{"project_id": bson.M{"$in": projectIDs}})
+	return err
+}
+
+// GetReferencedFileIDs returns the fileIds of image elements anywhere in the
+// project's op log, so files used by any historical version are kept.
+func (r *operationRepository) GetReferencedFileIDs(ctx context.Context, projectID bson.ObjectID) (map[string]bool, error) {
+	cursor, err := r.operations.Find(ctx,
+		bson.M{"project_id": projectID, "data": bson.M{"$regex": `"fileId"`}},
+		options.Find().SetProjection(bson.M{"data": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var ops []*models.Operation
+	if err := cursor.All(ctx, &ops); err != nil {
+		return nil, err
+	}
+
+	fileIDs := make(map[string]bool)
+	for _, op := range ops {
+		var el struct {
+			FileID string `json:"fileId"`
+		}
+		if op.Data != nil && json.Unmarshal([]byte(*op.Data), &el) == nil && el.FileID != "" {
+			fileIDs[el.FileID] = true
+		}
+	}
+	return fileIDs, nil
+}
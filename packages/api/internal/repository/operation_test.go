@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateOp(t *testing.T) {
+	data := func(s string) *string { return &s }
+	rect := `{"id":"r1","type":"rectangle","version":2,"x":0,"y":0,"width":10,"height":10}`
+	deleted := `{"id":"r1","type":"rectangle","version":2,"isDeleted":true,"x":0,"y":0,"width":10,"height":10}`
+
+	tests := []struct {
+		name   string
+		op     OpInput
+		reason string
+	}{
+		{"valid add", OpInput{Type: "ADD", ElementID: "r1", ElementVer: 2, Data: data(rect)}, ""},
+		{"valid update", OpInput{Type: "UPDATE", ElementID: "r1", ElementVer: 2, Data: data(rect)}, ""},
+		{"valid delete", OpInput{Type: "DELETE", ElementID: "r1", ElementVer: 2, Data: data(deleted)}, ""},
+		{"delete without data", OpInput{Type: "DELETE", ElementID: "r1", ElementVer: 2}, ""},
+		{"empty elementID", OpInput{Type: "ADD", ElementID: "", ElementVer: 2, Data: data(rect)}, `invalid elementID ""`},
+		{"add without data", OpInput{Type: "ADD", ElementID: "r1", ElementVer: 2}, "ADD op requires element data"},
+		{"update without data", OpInput{Type: "UPDATE", ElementID: "r1", ElementVer: 2}, "UPDATE op requires element data"},
+		{"unknown op type", OpInput{Type: "MOVE", ElementID: "r1", ElementVer: 2, Data: data(rect)}, `unknown op type "MOVE"`},
+		{"zero elementVer", OpInput{Type: "DELETE", ElementID: "r1"}, "elementVer must be positive, got 0"},
+		{"negative elementVer", OpInput{Type: "ADD", ElementID: "r1", ElementVer: -1, Data: data(rect)}, "elementVer must be positive, got -1"},
+		{"malformed element", OpInput{Type: "ADD", ElementID: "r1", ElementVer: 2, Data: data(`{"id":"r1"`)}, "element is not a JSON object"},
+		{"unknown element type", OpInput{Type: "ADD", ElementID: "r1", ElementVer: 2, Data: data(strings.Replace(rect, "rectangle", "hexagon", 1))}, `unsupported element type "hexagon"`},
+		{"element out of range", OpInput{Type: "UPDATE", ElementID: "r1", ElementVer: 2, Data: data(strings.Replace(rect, `"x":0`, `"x":1e12`, 1))}, "element x 1e+12 is out of range [-1e+08, 1e+08]"},
+		{"id mismatch", OpInput{Type: "UPDATE", ElementID: "r2", ElementVer: 2, Data: data(rect)}, `element id "r1" does not match elementID "r2"`},
+		{"version mismatch", OpInput{Type: "UPDATE", ElementID: "r1", ElementVer: 3, Data: data(rect)}, "element version 2 does not match elementVer 3"},
+		{"add of deleted element", OpInput{Type: "ADD", ElementID: "r1", ElementVer: 2, Data: data(deleted)}, "ADD op carries a deleted element"},
+		{"delete of live element", OpInput{Type: "DELETE", ElementID: "r1", ElementVer: 2, Data: data(rect)}, "DELETE op carries an element that is not deleted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validateOp(tt.op); got != tt.reason {
+				t.Errorf("validateOp() = %q, want %q", got, tt.reason)
+			}
+		})
+	}
+}
+
+func TestSplitBatches(t *testing.T) {
+	big := strings.Repeat("x", MaxBatchBytes/2+1)
+	ops := []OpInput{
+		{ElementID: "a", Data: &big},
+		{ElementID: "b", Data: &big},
+		{ElementID: "c"},
+	}
+	batches := SplitBatches(ops)
+	if len(batches) != 2 || len(batches[0]) != 1 || len(batches[1]) != 2 {
+		t.Fatalf("SplitBatches() made batches of %v, want [1 2]", batchSizes(batches))
+	}
+	if batches[0][0].ElementID != "a" || batches[1][0].ElementID != "b" || batches[1][1].ElementID != "c" {
+		t.Error("SplitBatches() did not keep the op order")
+	}
+
+	many := make([]OpInput, MaxBatchOps*2+1)
+	if got := batchSizes(SplitBatches(many)); len(got) != 3 || got[0] != MaxBatchOps || got[2] != 1 {
+		t.Errorf("SplitBatches() made batches of %v, want [%d %d 1]", got, MaxBatchOps, MaxBatchOps)
+	}
+}
+
+func batchSizes(batches [][]OpInput) []int {
+	sizes := make([]int, 0, len(batches))
+	for _, batch := range batches {
+		sizes = append(sizes, len(batch))
+	}
+	return sizes
+}
@@ -2,7 +2,11 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/chirag3003/collab-draw-backend/internal/config"
@@ -10,59 +14,184 @@ import (
 	"github.com/chirag3003/collab-draw-backend/internal/models"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
+// listProjection leaves out elements, which project lists never need and which
+// are by far the largest part of a project.
+var listProjection = bson.M{"elements": 0}
+
 type projectRepository struct {
-	project *mongo.Collection
+	project    *mongo.Collection
+	workspaces *mongo.Collection
+}
+
+// Fields project lists can be sorted by.
+const (
+	ProjectSortName      = "name"
+	ProjectSortCreatedAt = "created_at"
+	ProjectSortUpdatedAt = "updated_at"
+)
+
+// ProjectQuery filters, sorts and pages a list of projects.
+type ProjectQuery struct {
+	FolderID   *bson.ObjectID
+	Unfiled    bool // only projects outside any folder
+	Tags       []string
+	Name       string          // case-insensitive substring of the name
+	IDs        []bson.ObjectID // only these projects, when not nil
+	ExcludeIDs []bson.ObjectID
+	SortBy     string // one of the ProjectSort fields, updated_at by default
+	Descending bool
+	After      string // cursor of the last project of the previous page
+	Limit      int    // 0 returns every project
+}
+
+// ProjectPage is one page of a project list. Cursors[i] is the cursor of
+// Projects[i].
+type ProjectPage struct {
+	Projects    []*models.Project
+	Cursors     []string
+	HasNextPage bool
+}
+
+type projectCursor struct {
+	Sort string        `json:"s"`
+	Key  string        `json:"k"`
+	ID   bson.ObjectID `json:"id"`
+}
+
+func encodeProjectCursor(c projectCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeProjectCursor(cursor string, sortBy string) (*projectCursor, error) {
+	var c projectCursor
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil || c.Sort != sortBy {
+		return nil, errors.New("invalid cursor")
+	}
+	return &c, nil
 }
 
 type ProjectRepository interface {
 	NewProject(context context.Context, data *models.Project) error
-	UpdateProject(context context.Context, id string, elements string, userID string) error
+	UpdateProject(context context.Context, id string, elements string) error
 	UpdateProjectMetadata(context context.Context, id string, name string, description string, userID string) error
 	GetAll(context context.Context) ([]*models.Project, error)
+	GetProject(context context.Context, id string) (*models.Project, error)
 	GetProjectByID(context context.Context, id string, userID string) (*models.Project, error)
-	GetProjectsByUserID(context context.Context, userID string) ([]*models.Project, error)
 	GetPersonalProjects(context context.Context, userID string) ([]*models.Project, error)
-	GetProjectsByWorkspaceID(context context.Context, workspaceID string) ([]*models.Project, error)
 	DeleteProject(context context.Context, id string, userID string) (bool, error)
+	TransferOwnership(context context.Context, id string, fromUserID string, toUserID string) error
+	AddMember(context context.Context, id string, userID string) error
+	RemoveMember(context context.Context, id string, userID string) error
+	SetRestricted(context context.Context, id string, restricted bool) error
+	SetTemplate(context context.Context, id string, isTemplate bool) error
+	GetTemplates(context context.Context, workspaceID string, userID string) ([]*models.Project, error)
+	GetAccessibleProjects(context context.Context, userID string, workspaceID string) ([]*models.Project, error)
+	GetProjectsByIDs(context context.Context, ids []bson.ObjectID, userID string) ([]*models.Project, error)
+	ListWorkspaceProjects(context context.Context, workspaceID string, userID string, query ProjectQuery) (*ProjectPage, error)
+	ListUserProjects(context context.Context, userID string, query ProjectQuery) (*ProjectPage, error)
+	SetFolder(context context.Context, id string, folderID *bson.ObjectID) error
+	SetTags(context context.Context, id string, tags []string) error
+	GetElements(context context.Context, id string) (string, error)
+	GetWorkspaceUsage(context context.Context, workspaceID bson.ObjectID) (*WorkspaceUsage, error)
+	SetThumbnail(context context.Context, id bson.ObjectID, hash string) error
+	HasThumbnail(context context.Context, hash string) (bool, error)
+	GetTrashedProject(context context.Context, id string) (*models.Project, error)
+	GetTrashedProjects(context context.Context, userID string) ([]*models.Project, error)
+	RestoreProject(context context.Context, id string, userID string) (bool, error)
+	GetPurgeableProjects(context context.Context, deletedBefore time.Time) ([]*models.Project, error)
+	PurgeProject(context context.Context, id bson.ObjectID) error
 }
 
 func NewProjectRepository() ProjectRepository {
+	projects := db.GetCollection(config.PROJECT)
+	_, _ = projects.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "thumbnail_hash", Value: 1}},
+		Options: options.Index().SetSparse(true),
+	})
 	return &projectRepository{
-		project: db.GetCollection(config.PROJECT),
+		project:    projects,
+		workspaces: db.GetCollection(config.WORKSPACE),
 	}
 }
 
+// accessFilter matches the projects a user can access: projects they own, are
+// a direct member of, or that belong to one of their workspaces and are not
+// restricted to direct members.
+func (r *projectRepository) accessFilter(context context.Context, userID string) (bson.M, error) {
+	cursor, err := r.workspaces.Find(context, bson.M{
+		"$or": bson.A{
+			bson.M{"owner_id": userID},
+			bson.M{"members": userID},
+		},
+		"deleted_at": bson.M{"$exists": false},
+	}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var workspaces []struct {
+		ID bson.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(context, &workspaces); err != nil {
+		return nil, err
+	}
+	workspaceIDs := make(bson.A, 0, len(workspaces))
+	for _, ws := range workspaces {
+		workspaceIDs = append(workspaceIDs, ws.ID)
+	}
+
+	return bson.M{
+		"$or": bson.A{
+			bson.M{"owner": userID},
+			bson.M{"members": userID},
+			bson.M{
+				"workspace":  bson.M{"$in": workspaceIDs},
+				"restricted": bson.M{"$ne": true},
+			},
+		},
+		"deleted_at": bson.M{"$exists": false},
+	}, nil
+}
+
 func (r *projectRepository) NewProject(context context.Context, data *models.Project) error {
 	data.CreatedAt = time.Now().Format(time.RFC3339)
-	_, err := r.project.InsertOne(context, data)
+	data.UpdatedAt = data.CreatedAt
+	data.ElementCount = CountElements(data.Elements)
+	res, err := r.project.InsertOne(context, data)
 	if err != nil {
 		return err
 	}
+	if id, ok := res.InsertedID.(bson.ObjectID); ok {
+		data.ID = id
+	}
 	return nil
 }
 
-func (r *projectRepository) UpdateProject(context context.Context, id string, elements string, userID string) error {
+// UpdateProject replaces the project's elements. Callers are responsible for
+// checking that the user may edit the project.
+func (r *projectRepository) UpdateProject(context context.Context, id string, elements string) error {
 	ID, err := bson.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 	update := bson.M{
 		"$set": bson.M{
-			"elements":   elements,
-			"updated_at": time.Now().Format(time.RFC3339),
+			"elements":      elements,
+			"element_count": CountElements(elements),
+			"updated_at":    time.Now().Format(time.RFC3339),
 		},
 		"$inc": bson.M{
 			"head_seq": 1,
 		},
 	}
-	res, err := r.project.UpdateOne(context, bson.M{"_id": ID,
-		"$or": bson.A{
-			bson.M{"owner": userID},
-			bson.M{"members": userID},
-		},
-	}, update)
+	res, err := r.project.UpdateOne(context, bson.M{"_id": ID}, update)
 	if err != nil {
 		return err
 	}
@@ -96,7 +225,7 @@ func (r *projectRepository) UpdateProjectMetadata(context context.Context, id st
 
 func (r *projectRepository) GetAll(context context.Context) ([]*models.Project, error) {
 	var projects []*models.Project
-	cursor, err := r.project.Find(context, bson.M{})
+	cursor, err := r.project.Find(context, bson.M{"deleted_at": bson.M{"$exists": false}}, options.Find().SetProjection(listProjection))
 	if err != nil {
 		return nil, err
 	}
@@ -106,18 +235,36 @@ func (r *projectRepository) GetAll(context context.Context) ([]*models.Project,
 	return projects, nil
 }
 
+// GetProject fetches a project without checking membership. It is used for
+// guests whose access is granted by a share link.
+func (r *projectRepository) GetProject(context context.Context, id string) (*models.Project, error) {
+	var project models.Project
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	err = r.project.FindOne(context, bson.M{"_id": ID, "deleted_at": bson.M{"$exists": false}}).Decode(&project)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &project, nil
+}
+
 func (r *projectRepository) GetProjectByID(context context.Context, id string, userID string) (*models.Project, error) {
 	var project models.Project
 	ID, err := bson.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
-	err = r.project.FindOne(context, bson.M{"_id": ID,
-		"$or": bson.A{
-			bson.M{"owner": userID},
-			bson.M{"members": userID},
-		},
-	}).Decode(&project)
+	filter, err := r.accessFilter(context, userID)
+	if err != nil {
+		return nil, err
+	}
+	filter["_id"] = ID
+	err = r.project.FindOne(context, filter).Decode(&project)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, nil
@@ -127,9 +274,10 @@ func (r *projectRepository) GetProjectByID(context context.Context, id string, u
 	return &project, nil
 }
 
-func (r *projectRepository) GetProjectsByUserID(context context.Context, userID string) ([]*models.Project, error) {
+func (r *projectRepository) GetPersonalProjects(context context.Context, userID string) ([]*models.Project, error) {
 	var projects []*models.Project
-	cursor, err := r.project.Find(context, bson.M{"owner": userID})
+	cursor, err := r.project.Find(context, bson.M{"owner": userID, "personal": true, "deleted_at": bson.M{"$exists": false}},
+		options.Find().SetProjection(listProjection))
 	if err != nil {
 		return nil, err
 	}
@@ -139,9 +287,131 @@ func (r *projectRepository) GetProjectsByUserID(context context.Context, userID
 	return projects, nil
 }
 
-func (r *projectRepository) GetPersonalProjects(context context.Context, userID string) ([]*models.Project, error) {
+// DeleteProject moves the project to the trash. It is purged for good once the
+// trash retention period has passed.
+func (r *projectRepository) DeleteProject(context context.Context, id string, userID string) (bool, error) {
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+	result, err := r.project.UpdateOne(context, bson.M{"_id": ID, "owner": userID, "deleted_at": bson.M{"$exists": false}}, bson.M{
+		"$set": bson.M{
+			"deleted_at": time.Now().UTC().Format(time.RFC3339),
+			"deleted_by": userID,
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	if result.MatchedCount == 0 {
+		return false, nil
+	}
+	return true, nil
+}
+
+// TransferOwnership hands the project to another user. The previous owner stays
+// on as a member.
+func (r *projectRepository) TransferOwnership(context context.Context, id string, fromUserID string, toUserID string) error {
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	res, err := r.project.UpdateOne(context, bson.M{"_id": ID, "owner": fromUserID}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"owner":      toUserID,
+			"updated_at": time.Now().Format(time.RFC3339),
+			"members":    swapMember("$members", toUserID, fromUserID),
+		}}},
+	})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("no document found to update")
+	}
+	return nil
+}
+
+// swapMember is an update pipeline expression for a member list with removed
+// taken out and added appended once. A missing list counts as empty.
+func swapMember(field string, removed string, added string) bson.M {
+	return bson.M{"$concatArrays": bson.A{
+		bson.M{"$filter": bson.M{
+			"input": bson.M{"$ifNull": bson.A{field, bson.A{}}},
+			"cond":  bson.M{"$not": bson.A{bson.M{"$in": bson.A{"$$this", bson.A{removed, added}}}}},
+		}},
+		bson.A{added},
+	}}
+}
+
+func (r *projectRepository) AddMember(context context.Context, id string, userID string) error {
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	_, err = r.project.UpdateOne(context, bson.M{"_id": ID}, bson.M{
+		"$addToSet": bson.M{
+			"members": userID,
+		},
+	})
+	return err
+}
+
+func (r *projectRepository) RemoveMember(context context.Context, id string, userID string) error {
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	_, err = r.project.UpdateOne(context, bson.M{"_id": ID}, bson.M{
+		"$pull": bson.M{
+			"members": userID,
+		},
+	})
+	return err
+}
+
+func (r *projectRepository) SetRestricted(context context.Context, id string, restricted bool) error {
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	_, err = r.project.UpdateOne(context, bson.M{"_id": ID}, bson.M{
+		"$set": bson.M{
+			"restricted": restricted,
+			"updated_at": time.Now().Format(time.RFC3339),
+		},
+	})
+	return err
+}
+
+func (r *projectRepository) SetTemplate(context context.Context, id string, isTemplate bool) error {
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	_, err = r.project.UpdateOne(context, bson.M{"_id": ID}, bson.M{
+		"$set": bson.M{
+			"is_template": isTemplate,
+			"updated_at":  time.Now().Format(time.RFC3339),
+		},
+	})
+	return err
+}
+
+// GetTemplates returns the workspace's templates that the user can access.
+func (r *projectRepository) GetTemplates(context context.Context, workspaceID string, userID string) ([]*models.Project, error) {
+	ID, err := bson.ObjectIDFromHex(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := r.accessFilter(context, userID)
+	if err != nil {
+		return nil, err
+	}
+	filter["workspace"] = ID
+	filter["is_template"] = true
 	var projects []*models.Project
-	cursor, err := r.project.Find(context, bson.M{"owner": userID, "personal": true})
+	cursor, err := r.project.Find(context, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}).SetProjection(listProjection))
 	if err != nil {
 		return nil, err
 	}
@@ -151,13 +421,264 @@ func (r *projectRepository) GetPersonalProjects(context context.Context, userID
 	return projects, nil
 }
 
-func (r *projectRepository) GetProjectsByWorkspaceID(context context.Context, workspaceID string) ([]*models.Project, error) {
+// GetAccessibleProjects returns every project the user can access, limited to
+// one workspace unless workspaceID is empty. Elements are not loaded.
+func (r *projectRepository) GetAccessibleProjects(context context.Context, userID string, workspaceID string) ([]*models.Project, error) {
+	filter, err := r.accessFilter(context, userID)
+	if err != nil {
+		return nil, err
+	}
+	if workspaceID != "" {
+		ID, err := bson.ObjectIDFromHex(workspaceID)
+		if err != nil {
+			return nil, err
+		}
+		filter["workspace"] = ID
+	}
+	var projects []*models.Project
+	cursor, err := r.project.Find(context, filter, options.Find().SetProjection(listProjection))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(context, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// GetProjectsByIDs returns the given projects that the user can access, in no
+// particular order.
+func (r *projectRepository) GetProjectsByIDs(context context.Context, ids []bson.ObjectID, userID string) ([]*models.Project, error) {
+	if len(ids) == 0 {
+		return []*models.Project{}, nil
+	}
+	filter, err := r.accessFilter(context, userID)
+	if err != nil {
+		return nil, err
+	}
+	filter["_id"] = bson.M{"$in": ids}
+	var projects []*models.Project
+	cursor, err := r.project.Find(context, filter, options.Find().SetProjection(listProjection))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(context, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// ListWorkspaceProjects returns a page of the workspace's projects the user
+// can access.
+func (r *projectRepository) ListWorkspaceProjects(context context.Context, workspaceID string, userID string, query ProjectQuery) (*ProjectPage, error) {
 	ID, err := bson.ObjectIDFromHex(workspaceID)
 	if err != nil {
 		return nil, err
 	}
+	filter, err := r.accessFilter(context, userID)
+	if err != nil {
+		return nil, err
+	}
+	filter["workspace"] = ID
+	return r.list(context, filter, query)
+}
+
+// ListUserProjects returns a page of the projects the user owns.
+func (r *projectRepository) ListUserProjects(context context.Context, userID string, query ProjectQuery) (*ProjectPage, error) {
+	return r.list(context, bson.M{"owner": userID, "deleted_at": bson.M{"$exists": false}}, query)
+}
+
+func (r *projectRepository) list(context context.Context, filter bson.M, query ProjectQuery) (*ProjectPage, error) {
+	var and bson.A
+	if query.Unfiled {
+		filter["folder_id"] = bson.M{"$exists": false}
+	} else if query.FolderID != nil {
+		filter["folder_id"] = *query.FolderID
+	}
+	if len(query.Tags) > 0 {
+		filter["tags"] = bson.M{"$all": query.Tags}
+	}
+	if query.Name != "" {
+		filter["name"] = bson.M{"$regex": regexp.QuoteMeta(query.Name), "$options": "i"}
+	}
+	if query.IDs != nil {
+		and = append(and, bson.M{"_id": bson.M{"$in": query.IDs}})
+	}
+	if len(query.ExcludeIDs) > 0 {
+		and = append(and, bson.M{"_id": bson.M{"$nin": query.ExcludeIDs}})
+	}
+	if len(and) > 0 {
+		filter["$and"] = and
+	}
+
+	sortBy := query.SortBy
+	if sortBy == "" {
+		sortBy = ProjectSortUpdatedAt
+	}
+	var sortKey any
+	switch sortBy {
+	case ProjectSortName:
+		sortKey = bson.M{"$toLower": "$name"}
+	case ProjectSortCreatedAt:
+		sortKey = "$created_at"
+	case ProjectSortUpdatedAt:
+		// Projects created before updated_at was set fall back to created_at
+		sortKey = bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$updated_at", ""}}, "$updated_at", "$created_at"}}
+	default:
+		return nil, fmt.Errorf("unknown sort field %q", sortBy)
+	}
+	direction, compare := 1, "$gt"
+	if query.Descending {
+		direction, compare = -1, "$lt"
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$addFields", Value: bson.M{"sort_key": sortKey}}},
+	}
+	if query.After != "" {
+		after, err := decodeProjectCursor(query.After, sortBy)
+		if err != nil {
+			return nil, err
+		}
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"$or": bson.A{
+			bson.M{"sort_key": bson.M{compare: after.Key}},
+			bson.M{"sort_key": after.Key, "_id": bson.M{compare: after.ID}},
+		}}}})
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$project", Value: listProjection}}, bson.D{{Key: "$sort", Value: bson.D{
+		{Key: "sort_key", Value: direction},
+		{Key: "_id", Value: direction},
+	}}})
+	if query.Limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: query.Limit + 1}})
+	}
+
+	cursor, err := r.project.Aggregate(context, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		models.Project `bson:",inline"`
+		SortKey        string `bson:"sort_key"`
+	}
+	if err = cursor.All(context, &rows); err != nil {
+		return nil, err
+	}
+
+	page := &ProjectPage{}
+	if query.Limit > 0 && len(rows) > query.Limit {
+		rows = rows[:query.Limit]
+		page.HasNextPage = true
+	}
+	for i := range rows {
+		page.Projects = append(page.Projects, &rows[i].Project)
+		page.Cursors = append(page.Cursors, encodeProjectCursor(projectCursor{
+			Sort: sortBy,
+			Key:  rows[i].SortKey,
+			ID:   rows[i].ID,
+		}))
+	}
+	return page, nil
+}
+
+func (r *projectRepository) SetFolder(context context.Context, id string, folderID *bson.ObjectID) error {
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	update := bson.M{"$set": bson.M{"updated_at": time.Now().Format(time.RFC3339)}}
+	if folderID == nil {
+		update["$unset"] = bson.M{"folder_id": ""}
+	} else {
+		update["$set"].(bson.M)["folder_id"] = *folderID
+	}
+	_, err = r.project.UpdateOne(context, bson.M{"_id": ID}, update)
+	return err
+}
+
+func (r *projectRepository) SetTags(context context.Context, id string, tags []string) error {
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	_, err = r.project.UpdateOne(context, bson.M{"_id": ID}, bson.M{
+		"$set": bson.M{
+			"tags":       tags,
+			"updated_at": time.Now().Format(time.RFC3339),
+		},
+	})
+	return err
+}
+
+// GetElements returns just the elements of a project, trashed or not.
+func (r *projectRepository) GetElements(context context.Context, id string) (string, error) {
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return "", err
+	}
+	var project models.Project
+	err = r.project.FindOne(context, bson.M{"_id": ID}, options.FindOne().SetProjection(bson.M{"elements": 1})).Decode(&project)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return "", nil
+		}
+		return "", err
+	}
+	return project.Elements, nil
+}
+
+// SetThumbnail records the project's current thumbnail. It does not count as
+// an update of the project.
+func (r *projectRepository) SetThumbnail(context context.Context, id bson.ObjectID, hash string) error {
+	update := bson.M{"$set": bson.M{
+		"thumbnail_hash": hash,
+		"thumbnail_at":   time.Now().Format(time.RFC3339),
+	}}
+	if hash == "" {
+		update = bson.M{"$unset": bson.M{"thumbnail_hash": "", "thumbnail_at": ""}}
+	}
+	_, err := r.project.UpdateOne(context, bson.M{"_id": id}, update)
+	return err
+}
+
+// HasThumbnail reports whether a project that is not trashed uses the
+// thumbnail.
+func (r *projectRepository) HasThumbnail(context context.Context, hash string) (bool, error) {
+	count, err := r.project.CountDocuments(context, bson.M{
+		"thumbnail_hash": hash,
+		"deleted_at":     bson.M{"$exists": false},
+	}, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+// GetTrashedProject fetches a project from the trash. It returns nil when the
+// project does not exist or is not trashed.
+func (r *projectRepository) GetTrashedProject(context context.Context, id string) (*models.Project, error) {
+	var project models.Project
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	err = r.project.FindOne(context, bson.M{"_id": ID, "deleted_at": bson.M{"$exists": true}}).Decode(&project)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &project, nil
+}
+
+// GetTrashedProjects returns the trashed projects owned by the user, leaving
+// out those that went to the trash with their workspace.
+func (r *projectRepository) GetTrashedProjects(context context.Context, userID string) ([]*models.Project, error) {
 	var projects []*models.Project
-	cursor, err := r.project.Find(context, bson.M{"workspace": ID})
+	cursor, err := r.project.Find(context, bson.M{
+		"owner":                  userID,
+		"deleted_at":             bson.M{"$exists": true},
+		"trashed_with_workspace": bson.M{"$ne": true},
+	}, options.Find().SetSort(bson.D{{Key: "deleted_at", Value: -1}}).SetProjection(listProjection))
 	if err != nil {
 		return nil, err
 	}
@@ -167,17 +688,113 @@ func (r *projectRepository) GetProjectsByWorkspaceID(context context.Context, wo
 	return projects, nil
 }
 
-func (r *projectRepository) DeleteProject(context context.Context, id string, userID string) (bool, error) {
+func (r *projectRepository) RestoreProject(context context.Context, id string, userID string) (bool, error) {
 	ID, err := bson.ObjectIDFromHex(id)
 	if err != nil {
 		return false, err
 	}
-	result, err := r.project.DeleteOne(context, bson.M{"_id": ID, "owner": userID})
+	result, err := r.project.UpdateOne(context, bson.M{
+		"_id":                    ID,
+		"owner":                  userID,
+		"deleted_at":             bson.M{"$exists": true},
+		"trashed_with_workspace": bson.M{"$ne": true},
+	}, bson.M{
+		"$set": bson.M{
+			"updated_at": time.Now().Format(time.RFC3339),
+		},
+		"$unset": bson.M{
+			"deleted_at": "",
+			"deleted_by": "",
+		},
+	})
 	if err != nil {
 		return false, err
 	}
-	if result.DeletedCount == 0 {
-		return false, nil
+	return result.MatchedCount > 0, nil
+}
+
+// GetPurgeableProjects returns the projects trashed on their own before the
+// given time. Projects trashed with their workspace are purged with it.
+func (r *projectRepository) GetPurgeableProjects(context context.Context, deletedBefore time.Time) ([]*models.Project, error) {
+	var projects []*models.Project
+	cursor, err := r.project.Find(context, bson.M{
+		"deleted_at":             bson.M{"$lt": deletedBefore.UTC().Format(time.RFC3339)},
+		"trashed_with_workspace": bson.M{"$ne": true},
+	}, options.Find().SetProjection(bson.M{"elements": 0}))
+	if err != nil {
+		return nil, err
 	}
-	return true, nil
+	if err = cursor.All(context, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// PurgeProject permanently removes a trashed project. Its operations are
+// removed separately.
+func (r *projectRepository) PurgeProject(context context.Context, id bson.ObjectID) error {
+	_, err := r.project.DeleteOne(context, bson.M{"_id": id, "deleted_at": bson.M{"$exists": true}})
+	return err
+}
+
+// WorkspaceUsage is what the live projects of a workspace count towards its
+// quotas. Projects last saved before element counts were recorded count as
+// empty until their next edit.
+type WorkspaceUsage struct {
+	Projects     int   `bson:"projects"`
+	Elements     int   `bson:"elements"`
+	StorageBytes int64 `bson:"storage"` // size of the files linked to the projects
+}
+
+func (r *projectRepository) GetWorkspaceUsage(context context.Context, workspaceID bson.ObjectID) (*WorkspaceUsage, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"workspace": workspaceID, "deleted_at": bson.M{"$exists": false}}}},
+		{{Key: "$project", Value: bson.M{"element_count": 1}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         config.FILES,
+			"localField":   "_id",
+			"foreignField": "project_id",
+			"as":           "files",
+			"pipeline":     bson.A{bson.M{"$group": bson.M{"_id": nil, "size": bson.M{"$sum": "$size"}}}},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      nil,
+			"projects": bson.M{"$sum": 1},
+			"elements": bson.M{"$sum": bson.M{"$ifNull": bson.A{"$element_count", 0}}},
+			"storage":  bson.M{"$sum": bson.M{"$ifNull": bson.A{bson.M{"$first": "$files.size"}, 0}}},
+		}}},
+	}
+	cursor, err := r.project.Aggregate(context, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var rows []WorkspaceUsage
+	if err = cursor.All(context, &rows); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return &WorkspaceUsage{}, nil
+	}
+	return &rows[0], nil
+}
+
+// CountElements returns the number of elements of a scene that are not
+// deleted.
+func CountElements(elements string) int {
+	if elements == "" {
+		return 0
+	}
+	var parsed []struct {
+		IsDeleted bool `json:"isDeleted"`
+	}
+	if err := json.Unmarshal([]byte(elements), &parsed); err != nil {
+		return 0
+	}
+	count := 0
+	for _, el := range parsed {
+		if !el.IsDeleted {
+			count++
+		}
+	}
+	return count
 }
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/config"
+	"github.com/chirag3003/collab-draw-backend/internal/db"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type projectUserStateRepository struct {
+	states *mongo.Collection
+}
+
+type ProjectUserStateRepository interface {
+	SetFavorite(ctx context.Context, userID string, projectID bson.ObjectID, favorite bool) error
+	MarkOpened(ctx context.Context, userID string, projectID bson.ObjectID) error
+	IsFavorite(ctx context.Context, userID string, projectID bson.ObjectID) (bool, error)
+	GetFavoriteIDs(ctx context.Context, userID string) ([]bson.ObjectID, error)
+	GetRecent(ctx context.Context, userID string, limit int) ([]*models.ProjectUserState, error)
+	DeleteByProjects(ctx context.Context, projectIDs []bson.ObjectID) error
+}
+
+func NewProjectUserStateRepository() ProjectUserStateRepository {
+	states := db.GetCollection(config.PROJECT_USER_STATE)
+	_, _ = states.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "project_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "last_opened_at", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{{Key: "project_id", Value: 1}},
+		},
+	})
+	return &projectUserStateRepository{states: states}
+}
+
+func (r *projectUserStateRepository) SetFavorite(ctx context.Context, userID string, projectID bson.ObjectID, favorite bool) error {
+	update := bson.M{"$set": bson.M{"favorite": favorite}}
+	if favorite {
+		update["$set"].(bson.M)["favorited_at"] = time.Now().Format(time.RFC3339)
+	} else {
+		update["$unset"] = bson.M{"favorited_at": ""}
+	}
+	_, err := r.states.UpdateOne(ctx, bson.M{"user_id": userID, "project_id": projectID}, update,
+		options.UpdateOne().SetUpsert(true))
+	return err
+}
+
+func (r *projectUserStateRepository) MarkOpened(ctx context.Context, userID string, projectID bson.ObjectID) error {
+	_, err := r.states.UpdateOne(ctx, bson.M{"user_id": userID, "project_id": projectID}, bson.M{
+		"$set":         bson.M{"last_opened_at": time.Now().UTC().Format(time.RFC3339)},
+		"$setOnInsert": bson.M{"favorite": false},
+	}, options.UpdateOne().SetUpsert(true))
+	return err
+}
+
+func (r *projectUserStateRepository) IsFavorite(ctx context.Context, userID string, projectID bson.ObjectID) (bool, error) {
+	var state models.ProjectUserState
+	err := r.states.FindOne(ctx, bson.M{"user_id": userID, "project_id": projectID}).Decode(&state)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+		return false, err
+	}
+	return state.Favorite, nil
+}
+
+// GetFavoriteIDs returns the user's starred projects, most recently starred
+// first.
+func (r *projectUserStateRepository) GetFavoriteIDs(ctx context.Context, userID string) ([]bson.ObjectID, error) {
+	cursor, err := r.states.Find(ctx, bson.M{"user_id": userID, "favorite": true},
+		options.Find().SetSort(bson.D{{Key: "favorited_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	var states []*models.ProjectUserState
+	if err = cursor.All(ctx, &states); err != nil {
+		return nil, err
+	}
+	ids := make([]bson.ObjectID, 0, len(states))
+	for _, state := range states {
+		ids = append(ids, state.ProjectID)
+	}
+	return ids, nil
+}
+
+// GetRecent returns the projects the user opened last, most recent first.
+func (r *projectUserStateRepository) GetRecent(ctx context.Context, userID string, limit int) ([]*models.ProjectUserState, error) {
+	cursor, err := r.states.Find(ctx, bson.M{"user_id": userID, "last_opened_at": bson.M{"$exists": true}},
+		options.Find().SetSort(bson.D{{Key: "last_opened_at", Value: -1}}).SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	var states []*models.ProjectUserState
+	if err = cursor.All(ctx, &states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+func (r *projectUserStateRepository) DeleteByProjects(ctx context.Context, projectIDs []bson.ObjectID) error {
+	if len(projectIDs) == 0 {
+		return nil
+	}
+	_, err := r.states.DeleteMany(ctx, bson.M{"project_id": bson.M{"$in": projectIDs}})
+	return err
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/config"
+	"github.com/chirag3003/collab-draw-backend/internal/db"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type searchRepository struct {
+	entries *mongo.Collection
+}
+
+type SearchRepository interface {
+	SetProject(ctx context.Context, projectID bson.ObjectID, name string, description string) error
+	SetElements(ctx context.Context, projectID bson.ObjectID, texts map[string]string) error
+	ReplaceElements(ctx context.Context, projectID bson.ObjectID, texts map[string]string) error
+	RemoveElements(ctx context.Context, projectID bson.ObjectID, elementIDs []string) error
+	SetComment(ctx context.Context, entry *models.SearchEntry) error
+	RemoveComment(ctx context.Context, commentID bson.ObjectID) error
+	Search(ctx context.Context, query string, projectIDs []bson.ObjectID, limit int) ([]*models.SearchHit, error)
+	IsEmpty(ctx context.Context) (bool, error)
+	DeleteByProjects(ctx context.Context, projectIDs []bson.ObjectID) error
+}
+
+func NewSearchRepository() SearchRepository {
+	entries := db.GetCollection(config.SEARCH_INDEX)
+	_, _ = entries.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "name", Value: "text"},
+				{Key: "text", Value: "text"},
+			},
+			Options: options.Index().SetWeights(bson.D{
+				{Key: "name", Value: 10},
+				{Key: "text", Value: 1},
+			}),
+		},
+		{
+			Keys: bson.D{
+				{Key: "project_id", Value: 1},
+				{Key: "kind", Value: 1},
+				{Key: "element_id", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{{Key: "comment_id", Value: 1}},
+		},
+	})
+
+	return &searchRepository{entries: entries}
+}
+
+// SetProject indexes the project's name and description.
+func (r *searchRepository) SetProject(ctx context.Context, projectID bson.ObjectID, name string, description string) error {
+	_, err := r.entries.UpdateOne(ctx,
+		bson.M{"project_id": projectID, "kind": models.SearchKindProject},
+		bson.M{"$set": bson.M{
+			"name":       name,
+			"text":       description,
+			"updated_at": time.Now().Format(time.RFC3339),
+		}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+// SetElements indexes the text of the given elements, keyed by element ID.
+func (r *searchRepository) SetElements(ctx context.Context, projectID bson.ObjectID, texts map[string]string) error {
+	if len(texts) == 0 {
+		return nil
+	}
+	now := time.Now().Format(time.RFC3339)
+	writes := make([]mongo.WriteModel, 0, len(texts))
+	for elementID, text := range texts {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"project_id": projectID, "kind": models.SearchKindElement, "element_id": elementID}).
+			SetUpdate(bson.M{"$set": bson.M{"text": text, "updated_at": now}}).
+			SetUpsert(true))
+	}
+	_, err := r.entries.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// ReplaceElements drops every indexed element of the project and indexes the
+// given ones instead.
+func (r *searchRepository) ReplaceElements(ctx context.Context, projectID bson.ObjectID, texts map[string]string) error {
+	_, err := r.entries.DeleteMany(ctx, bson.M{"project_id": projectID, "kind": models.SearchKindElement})
+	if err != nil {
+		return err
+	}
+	return r.SetElements(ctx, projectID, texts)
+}
+
+func (r *searchRepository) RemoveElements(ctx context.Context, projectID bson.ObjectID, elementIDs []string) error {
+	if len(elementIDs) == 0 {
+		return nil
+	}
+	_, err := r.entries.DeleteMany(ctx, bson.M{
+		"project_id": projectID,
+		"kind":       models.SearchKindElement,
+		"element_id": bson.M{"$in": elementIDs},
+	})
+	return err
+}
+
+// SetComment indexes a comment, replacing its previous text.
+func (r *searchRepository) SetComment(ctx context.Context, entry *models.SearchEntry) error {
+	entry.Kind = models.SearchKindComment
+	entry.UpdatedAt = time.Now().Format(time.RFC3339)
+	_, err := r.entries.ReplaceOne(ctx,
+		bson.M{"comment_id": entry.CommentID},
+		entry,
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *searchRepository) RemoveComment(ctx context.Context, commentID bson.ObjectID) error {
+	_, err := r.entries.DeleteOne(ctx, bson.M{"comment_id": commentID.Hex()})
+	return err
+}
+
+// Search returns the entries of the given projects that match the query, most
+// relevant first.
+func (r *searchRepository) Search(ctx context.Context, query string, projectIDs []bson.ObjectID, limit int) ([]*models.SearchHit, error) {
+	if len(projectIDs) == 0 {
+		return []*models.SearchHit{}, nil
+	}
+	filter := bson.M{
+		"$text":      bson.M{"$search": query},
+		"project_id": bson.M{"$in": projectIDs},
+	}
+	opts := options.Find().
+		SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}}).
+		SetSort(bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}}).
+		SetLimit(int64(limit))
+	cursor, err := r.entries.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	hits := []*models.SearchHit{}
+	if err = cursor.All(ctx, &hits); err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+// IsEmpty reports whether nothing has been indexed yet.
+func (r *searchRepository) IsEmpty(ctx context.Context) (bool, error) {
+	count, err := r.entries.CountDocuments(ctx, bson.M{}, options.Count().SetLimit(1))
+	return count == 0, err
+}
+
+func (r *searchRepository) DeleteByProjects(ctx context.Context, projectIDs []bson.ObjectID) error {
+	if len(projectIDs) == 0 {
+		return nil
+	}
+	_, err := r.entries.DeleteMany(ctx, bson.M{"project_id": bson.M{"$in": projectIDs}})
+	return err
+}
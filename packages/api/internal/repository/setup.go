@@ -3,18 +3,44 @@ package repository
 var repo *Repository
 
 type Repository struct {
-	Project   ProjectRepository
-	Workspace WorkspaceRepository
-	User      UserRepository
-	Operation OperationRepository
+	Project      ProjectRepository
+	Workspace    WorkspaceRepository
+	User         UserRepository
+	Operation    OperationRepository
+	AccessToken  AccessTokenRepository
+	ShareLink    ShareLinkRepository
+	Invitation   InvitationRepository
+	Audit        AuditRepository
+	Comment      CommentRepository
+	Notification NotificationRepository
+	Webhook      WebhookRepository
+	Library      LibraryRepository
+	File         FileRepository
+	Search       SearchRepository
+	Folder       FolderRepository
+	ProjectState ProjectUserStateRepository
+	Migration    MigrationRepository
 }
 
 func Setup() *Repository {
 	repo = &Repository{
-		Project:   NewProjectRepository(),
-		Workspace: NewWorkspaceRepository(),
-		User:      NewUserRepository(),
-		Operation: NewOperationRepository(),
+		Project:      NewProjectRepository(),
+		Workspace:    NewWorkspaceRepository(),
+		User:         NewUserRepository(),
+		Operation:    NewOperationRepository(),
+		AccessToken:  NewAccessTokenRepository(),
+		ShareLink:    NewShareLinkRepository(),
+		Invitation:   NewInvitationRepository(),
+		Audit:        NewAuditRepository(),
+		Comment:      NewCommentRepository(),
+		Notification: NewNotificationRepository(),
+		Webhook:      NewWebhookRepository(),
+		Library:      NewLibraryRepository(),
+		File:         NewFileRepository(),
+		Search:       NewSearchRepository(),
+		Folder:       NewFolderRepository(),
+		ProjectState: NewProjectUserStateRepository(),
+		Migration:    NewMigrationRepository(),
 	}
 	return repo
 }
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/config"
+	"github.com/chirag3003/collab-draw-backend/internal/db"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type shareLinkRepository struct {
+	links *mongo.Collection
+}
+
+type ShareLinkRepository interface {
+	CreateShareLink(ctx context.Context, data *models.ShareLink) error
+	GetShareLinkByID(ctx context.Context, id string) (*models.ShareLink, error)
+	GetShareLinksByProject(ctx context.Context, projectID string) ([]*models.ShareLink, error)
+	GetShareLinkByHash(ctx context.Context, hash string) (*models.ShareLink, error)
+	RedeemShareLink(ctx context.Context, id bson.ObjectID) (bool, error)
+	RevokeShareLink(ctx context.Context, id string) (bool, error)
+	DeleteByProjects(ctx context.Context, projectIDs []bson.ObjectID) error
+}
+
+func NewShareLinkRepository() ShareLinkRepository {
+	links := db.GetCollection(config.SHARE_LINKS)
+
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "project_id", Value: 1}},
+		},
+	}
+	_, _ = links.Indexes().CreateMany(context.Background(), indexModels)
+
+	return &shareLinkRepository{
+		links: links,
+	}
+}
+
+func (r *shareLinkRepository) CreateShareLink(ctx context.Context, data *models.ShareLink) error {
+	data.CreatedAt = time.Now().Format(time.RFC3339)
+	res, err := r.links.InsertOne(ctx, data)
+	if err != nil {
+		return err
+	}
+	if id, ok := res.InsertedID.(bson.ObjectID); ok {
+		data.ID = id
+	}
+	return nil
+}
+
+func (r *shareLinkRepository) GetShareLinkByID(ctx context.Context, id string) (*models.ShareLink, error) {
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	var link models.ShareLink
+	err = r.links.FindOne(ctx, bson.M{"_id": ID}).Decode(&link)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *shareLinkRepository) GetShareLinksByProject(ctx context.Context, projectID string) ([]*models.ShareLink, error) {
+	ID, err := bson.ObjectIDFromHex(projectID)
+	if err != nil {
+		return nil, err
+	}
+	var links []*models.ShareLink
+	findOpts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.links.Find(ctx, bson.M{
+		"project_id": ID,
+		"revoked_at": bson.M{"$exists": false},
+	}, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// GetShareLinkByHash returns the active link with the given token hash, or nil
+// when the link is unknown, revoked or expired.
+func (r *shareLinkRepository) GetShareLinkByHash(ctx context.Context, hash string) (*models.ShareLink, error) {
+	var link models.ShareLink
+	err := r.links.FindOne(ctx, bson.M{
+		"token_hash": hash,
+		"revoked_at": bson.M{"$exists": false},
+	}).Decode(&link)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !link.Usable(time.Now()) {
+		return nil, nil
+	}
+	return &link, nil
+}
+
+// RedeemShareLink atomically counts one use of the link. It returns false when
+// the link has already reached its maximum number of uses.
+func (r *shareLinkRepository) RedeemShareLink(ctx context.Context, id bson.ObjectID) (bool, error) {
+	res, err := r.links.UpdateOne(ctx, bson.M{
+		"_id":        id,
+		"revoked_at": bson.M{"$exists": false},
+		"$or": bson.A{
+			bson.M{"max_uses": 0},
+			bson.M{"$expr": bson.M{"$lt": bson.A{"$uses", "$max_uses"}}},
+		},
+	}, bson.M{
+		"$inc": bson.M{"uses": 1},
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+func (r *shareLinkRepository) RevokeShareLink(ctx context.Context, id string) (bool, error) {
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+	res, err := r.links.UpdateOne(ctx, bson.M{
+		"_id":        ID,
+		"revoked_at": bson.M{"$exists": false},
+	}, bson.M{
+		"$set": bson.M{
+			"revoked_at": time.Now().Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+func (r *shareLinkRepository) DeleteByProjects(ctx context.Context, projectIDs []bson.ObjectID) error {
+	if len(projectIDs) == 0 {
+		return nil
+	}
+	_, err := r.links.DeleteMany(ctx, bson.M{"project_id": bson.M{"$in": projectIDs}})
+	return err
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/config"
+	"github.com/chirag3003/collab-draw-backend/internal/db"
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const webhookDeliveryPageSize = 50
+
+type webhookRepository struct {
+	webhooks   *mongo.Collection
+	deliveries *mongo.Collection
+}
+
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, data *models.Webhook) error
+	GetWebhook(ctx context.Context, id string) (*models.Webhook, error)
+	GetWebhooksByWorkspace(ctx context.Context, workspaceID bson.ObjectID) ([]*models.Webhook, error)
+	GetActiveWebhooks(ctx context.Context, workspaceID bson.ObjectID, event string) ([]*models.Webhook, error)
+	SetActive(ctx context.Context, id bson.ObjectID, active bool) error
+	DeleteWebhook(ctx context.Context, id bson.ObjectID) error
+	EnqueueDelivery(ctx context.Context, data *models.WebhookDelivery) error
+	ClaimDueDelivery(ctx context.Context, lease time.Duration) (*models.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id bson.ObjectID, responseCode int) error
+	MarkAttemptFailed(ctx context.Context, id bson.ObjectID, responseCode int, lastError string, nextAttemptAt *time.Time) error
+	GetDeliveries(ctx context.Context, webhookID bson.ObjectID, cursor string) ([]*models.WebhookDelivery, string, error)
+	DeleteByWorkspace(ctx context.Context, workspaceID bson.ObjectID) error
+}
+
+func NewWebhookRepository() WebhookRepository {
+	webhooks := db.GetCollection(config.WEBHOOKS)
+	deliveries := db.GetCollection(config.WEBHOOK_DELIVERIES)
+
+	_, _ = webhooks.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "workspace_id", Value: 1}},
+	})
+	_, _ = deliveries.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "next_attempt_at", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "webhook_id", Value: 1},
+				{Key: "_id", Value: -1},
+			},
+		},
+	})
+
+	return &webhookRepository{
+		webhooks:   webhooks,
+		deliveries: deliveries,
+	}
+}
+
+func (r *webhookRepository) CreateWebhook(ctx context.Context, data *models.Webhook) error {
+	data.CreatedAt = time.Now().Format(time.RFC3339)
+	res, err := r.webhooks.InsertOne(ctx, data)
+	if err != nil {
+		return err
+	}
+	if id, ok := res.InsertedID.(bson.ObjectID); ok {
+		data.ID = id
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetWebhook(ctx context.Context, id string) (*models.Webhook, error) {
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	var webhook models.Webhook
+	err = r.webhooks.FindOne(ctx, bson.M{"_id": ID}).Decode(&webhook)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *webhookRepository) GetWebhooksByWorkspace(ctx context.Context, workspaceID bson.ObjectID) ([]*models.Webhook, error) {
+	var webhooks []*models.Webhook
+	cursor, err := r.webhooks.Find(ctx, bson.M{"workspace_id": workspaceID})
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &webhooks); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// GetActiveWebhooks returns the active webhooks of the workspace subscribed to
+// the event.
+func (r *webhookRepository) GetActiveWebhooks(ctx context.Context, workspaceID bson.ObjectID, event string) ([]*models.Webhook, error) {
+	var webhooks []*models.Webhook
+	cursor, err := r.webhooks.Find(ctx, bson.M{
+		"workspace_id": workspaceID,
+		"active":       true,
+		"events":       event,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &webhooks); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (r *webhookRepository) SetActive(ctx context.Context, id bson.ObjectID, active bool) error {
+	_, err := r.webhooks.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"active": active},
+	})
+	return err
+}
+
+// DeleteWebhook removes the webhook and its delivery log.
+func (r *webhookRepository) DeleteWebhook(ctx context.Context, id bson.ObjectID) error {
+	_, err := r.deliveries.DeleteMany(ctx, bson.M{"webhook_id": id})
+	if err != nil {
+		return err
+	}
+	_, err = r.webhooks.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (r *webhookRepository) EnqueueDelivery(ctx context.Context, data *models.WebhookDelivery) error {
+	data.Status = models.WebhookDeliveryPending
+	data.CreatedAt = time.Now().Format(time.RFC3339)
+	if data.NextAttemptAt.IsZero() {
+		data.NextAttemptAt = time.Now().UTC()
+	}
+	res, err := r.deliveries.InsertOne(ctx, data)
+	if err != nil {
+		return err
+	}
+	if id, ok := res.InsertedID.(bson.ObjectID); ok {
+		data.ID = id
+	}
+	return nil
+}
+
+// ClaimDueDelivery picks a pending delivery whose next attempt is due and
+// pushes its next attempt back by the lease, so that no other worker picks it
+// up while it is in flight. It returns nil when nothing is due.
+func (r *webhookRepository) ClaimDueDelivery(ctx context.Context, lease time.Duration) (*models.WebhookDelivery, error) {
+	now := time.Now().UTC()
+	var delivery models.WebhookDelivery
+	err := r.deliveries.FindOneAndUpdate(ctx, bson.M{
+		"status":          models.WebhookDeliveryPending,
+		"next_attempt_at": bson.M{"$lte": now},
+	}, bson.M{
+		"$set": bson.M{"next_attempt_at": now.Add(lease)},
+		"$inc": bson.M{"attempts": 1},
+	}, options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)).Decode(&delivery)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *webhookRepository) MarkDelivered(ctx context.Context, id bson.ObjectID, responseCode int) error {
+	_, err := r.deliveries.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{
+			"status":        models.WebhookDeliverySucceeded,
+			"response_code": responseCode,
+			"delivered_at":  time.Now().Format(time.RFC3339),
+		},
+		"$unset": bson.M{"last_error": ""},
+	})
+	return err
+}
+
+// MarkAttemptFailed records a failed attempt. The delivery is retried at
+// nextAttemptAt, or given up on when it is nil.
+func (r *webhookRepository) MarkAttemptFailed(ctx context.Context, id bson.ObjectID, responseCode int, lastError string, nextAttemptAt *time.Time) error {
+	set := bson.M{
+		"response_code": responseCode,
+		"last_error":    lastError,
+	}
+	if nextAttemptAt != nil {
+		set["next_attempt_at"] = nextAttemptAt.UTC()
+	} else {
+		set["status"] = models.WebhookDeliveryFailed
+	}
+	_, err := r.deliveries.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	return err
+}
+
+// GetDeliveries returns a page of a webhook's delivery log, newest first. The
+// returned cursor is empty on the last page.
+func (r *webhookRepository) GetDeliveries(ctx context.Context, webhookID bson.ObjectID, cursor string) ([]*models.WebhookDelivery, string, error) {
+	query := bson.M{"webhook_id": webhookID}
+	if cursor != "" {
+		after, err := bson.ObjectIDFromHex(cursor)
+		if err != nil {
+			return nil, "", errors.New("invalid cursor")
+		}
+		query["_id"] = bson.M{"$lt": after}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(webhookDeliveryPageSize + 1)
+	res, err := r.deliveries.Find(ctx, query, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	var deliveries []*models.WebhookDelivery
+	if err = res.All(ctx, &deliveries); err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(deliveries) > webhookDeliveryPageSize {
+		deliveries = deliveries[:webhookDeliveryPageSize]
+		next = deliveries[len(deliveries)-1].ID.Hex()
+	}
+	return deliveries, next, nil
+}
+
+func (r *webhookRepository) DeleteByWorkspace(ctx context.Context, workspaceID bson.ObjectID) error {
+	_, err := r.deliveries.DeleteMany(ctx, bson.M{"workspace_id": workspaceID})
+	if err != nil {
+		return err
+	}
+	_, err = r.webhooks.DeleteMany(ctx, bson.M{"workspace_id": workspaceID})
+	return err
+}
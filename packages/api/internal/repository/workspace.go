@@ -10,6 +10,7 @@ import (
 	"github.com/chirag3003/collab-draw-backend/internal/models"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 type workspaceRepository struct {
@@ -20,13 +21,21 @@ type workspaceRepository struct {
 type WorkspaceRepository interface {
 	CreateWorkspace(context context.Context, data *models.Workspace) error
 	GetAllWorkspaces(context context.Context) ([]*models.Workspace, error)
+	GetWorkspace(context context.Context, id string) (*models.Workspace, error)
 	GetWorkspaceByID(context context.Context, id string, userID string) (*models.Workspace, error)
 	GetWorkspacesByUser(context context.Context, userID string) (*[]models.Workspace, error)
 	GetSharedWorkspaces(context context.Context, userID string) (*[]models.Workspace, error)
 	UpdateWorkspaceMetadata(context context.Context, id string, name string, description string, userID string) error
 	DeleteWorkspace(context context.Context, id string, userID string) error
-	AddMemberToWorkspace(context context.Context, workspaceID string, userID string) error
+	AddMemberToWorkspace(context context.Context, workspaceID string, userID string, role string) error
 	RemoveMemberFromWorkspace(context context.Context, workspaceID string, userID string) error
+	TransferOwnership(context context.Context, workspaceID string, fromUserID string, toUserID string) error
+	GetTrashedWorkspaces(context context.Context, userID string) ([]*models.Workspace, error)
+	RestoreWorkspace(context context.Context, id string, userID string) (bool, error)
+	GetPurgeableWorkspaces(context context.Context, deletedBefore time.Time) ([]*models.Workspace, error)
+	GetProjectIDs(context context.Context, id bson.ObjectID) ([]bson.ObjectID, error)
+	PurgeWorkspace(context context.Context, id bson.ObjectID) error
+	StripInheritedProjectMembers(context context.Context) (int64, error)
 }
 
 func NewWorkspaceRepository() WorkspaceRepository {
@@ -38,16 +47,19 @@ func NewWorkspaceRepository() WorkspaceRepository {
 
 func (r *workspaceRepository) CreateWorkspace(context context.Context, data *models.Workspace) error {
 	data.CreatedAt = time.Now().Format(time.RFC3339)
-	_, err := r.workspace.InsertOne(context, data)
+	res, err := r.workspace.InsertOne(context, data)
 	if err != nil {
 		return err
 	}
+	if id, ok := res.InsertedID.(bson.ObjectID); ok {
+		data.ID = id
+	}
 	return nil
 }
 
 func (r *workspaceRepository) GetAllWorkspaces(context context.Context) ([]*models.Workspace, error) {
 	var workspaces []*models.Workspace
-	cursor, err := r.workspace.Find(context, bson.M{})
+	cursor, err := r.workspace.Find(context, bson.M{"deleted_at": bson.M{"$exists": false}})
 	if err != nil {
 		return nil, err
 	}
@@ -57,6 +69,23 @@ func (r *workspaceRepository) GetAllWorkspaces(context context.Context) ([]*mode
 	return workspaces, nil
 }
 
+// GetWorkspace fetches a workspace without checking membership.
+func (r *workspaceRepository) GetWorkspace(context context.Context, id string) (*models.Workspace, error) {
+	var workspace models.Workspace
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	err = r.workspace.FindOne(context, bson.M{"_id": ID, "deleted_at": bson.M{"$exists": false}}).Decode(&workspace)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &workspace, nil
+}
+
 func (r *workspaceRepository) GetWorkspaceByID(context context.Context, id string, userID string) (*models.Workspace, error) {
 	var workspace models.Workspace
 	ID, err := bson.ObjectIDFromHex(id)
@@ -68,6 +97,7 @@ func (r *workspaceRepository) GetWorkspaceByID(context context.Context, id strin
 			bson.M{"owner_id": userID},
 			bson.M{"members": userID},
 		},
+		"deleted_at": bson.M{"$exists": false},
 	}).Decode(&workspace)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -80,7 +110,7 @@ func (r *workspaceRepository) GetWorkspaceByID(context context.Context, id strin
 
 func (r *workspaceRepository) GetWorkspacesByUser(context context.Context, userID string) (*[]models.Workspace, error) {
 	var workspaces []models.Workspace
-	cursor, err := r.workspace.Find(context, bson.M{"owner_id": userID})
+	cursor, err := r.workspace.Find(context, bson.M{"owner_id": userID, "deleted_at": bson.M{"$exists": false}})
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +122,7 @@ func (r *workspaceRepository) GetWorkspacesByUser(context context.Context, userI
 
 func (r *workspaceRepository) GetSharedWorkspaces(context context.Context, userID string) (*[]models.Workspace, error) {
 	var workspaces []models.Workspace
-	cursor, err := r.workspace.Find(context, bson.M{"members": bson.M{"$in": []string{userID}}})
+	cursor, err := r.workspace.Find(context, bson.M{"members": bson.M{"$in": []string{userID}}, "deleted_at": bson.M{"$exists": false}})
 	if err != nil {
 		return nil, err
 	}
@@ -119,23 +149,40 @@ func (r *workspaceRepository) UpdateWorkspaceMetadata(context context.Context, i
 	return nil
 }
 
+// DeleteWorkspace moves the workspace and the projects in it to the trash.
+// Projects already in the trash keep their own deletion time.
 func (r *workspaceRepository) DeleteWorkspace(context context.Context, id string, userID string) error {
 	ID, err := bson.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
-	_, err = r.projects.DeleteMany(context, bson.M{"workspace": ID, "owner_id": userID})
+	now := time.Now().UTC().Format(time.RFC3339)
+	res, err := r.workspace.UpdateOne(context, bson.M{"_id": ID, "owner_id": userID, "deleted_at": bson.M{"$exists": false}}, bson.M{
+		"$set": bson.M{
+			"deleted_at": now,
+			"deleted_by": userID,
+		},
+	})
 	if err != nil {
 		return err
 	}
-	_, err = r.workspace.DeleteOne(context, bson.M{"_id": ID, "owner_id": userID})
+	if res.MatchedCount == 0 {
+		return errors.New("no document found to delete")
+	}
+	_, err = r.projects.UpdateMany(context, bson.M{"workspace": ID, "deleted_at": bson.M{"$exists": false}}, bson.M{
+		"$set": bson.M{
+			"deleted_at":             now,
+			"deleted_by":             userID,
+			"trashed_with_workspace": true,
+		},
+	})
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *workspaceRepository) AddMemberToWorkspace(context context.Context, workspaceID string, userID string) error {
+func (r *workspaceRepository) AddMemberToWorkspace(context context.Context, workspaceID string, userID string, role string) error {
 	ID, err := bson.ObjectIDFromHex(workspaceID)
 	if err != nil {
 		return err
@@ -144,13 +191,8 @@ func (r *workspaceRepository) AddMemberToWorkspace(context context.Context, work
 		"$addToSet": bson.M{
 			"members": userID,
 		},
-	})
-	if err != nil {
-		return err
-	}
-	_, err = r.projects.UpdateMany(context, bson.M{"workspace": ID}, bson.M{
-		"$addToSet": bson.M{
-			"members": userID,
+		"$set": bson.M{
+			"roles." + userID: role,
 		},
 	})
 	if err != nil {
@@ -159,6 +201,8 @@ func (r *workspaceRepository) AddMemberToWorkspace(context context.Context, work
 	return nil
 }
 
+// RemoveMemberFromWorkspace drops a user from the workspace. Project-level
+// memberships are separate grants and are kept.
 func (r *workspaceRepository) RemoveMemberFromWorkspace(context context.Context, workspaceID string, userID string) error {
 	ID, err := bson.ObjectIDFromHex(workspaceID)
 	if err != nil {
@@ -168,17 +212,170 @@ func (r *workspaceRepository) RemoveMemberFromWorkspace(context context.Context,
 		"$pull": bson.M{
 			"members": userID,
 		},
+		"$unset": bson.M{
+			"roles." + userID: "",
+		},
 	})
 	if err != nil {
 		return err
 	}
-	_, err = r.projects.UpdateMany(context, bson.M{"workspace": ID}, bson.M{
-		"$pull": bson.M{
-			"members": userID,
+	return nil
+}
+
+// TransferOwnership hands the workspace and the projects the previous owner
+// held in it to another member. The previous owner stays on as an editor. The
+// workspace changes hands in a single update that only matches while
+// fromUserID still owns it, so concurrent transfers cannot both succeed.
+func (r *workspaceRepository) TransferOwnership(context context.Context, workspaceID string, fromUserID string, toUserID string) error {
+	ID, err := bson.ObjectIDFromHex(workspaceID)
+	if err != nil {
+		return err
+	}
+	res, err := r.workspace.UpdateOne(context, bson.M{"_id": ID, "owner_id": fromUserID, "members": toUserID}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"owner_id":            toUserID,
+			"members":             swapMember("$members", toUserID, fromUserID),
+			"roles." + fromUserID: models.WorkspaceRoleEditor,
+		}}},
+		{{Key: "$unset", Value: "roles." + toUserID}},
+	})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.New("no document found to update")
+	}
+
+	_, err = r.projects.UpdateMany(context, bson.M{"workspace": ID, "owner": fromUserID}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"owner":      toUserID,
+			"updated_at": time.Now().Format(time.RFC3339),
+			"members":    swapMember("$members", toUserID, fromUserID),
+		}}},
+	})
+	return err
+}
+
+func (r *workspaceRepository) GetTrashedWorkspaces(context context.Context, userID string) ([]*models.Workspace, error) {
+	var workspaces []*models.Workspace
+	cursor, err := r.workspace.Find(context, bson.M{
+		"owner_id":   userID,
+		"deleted_at": bson.M{"$exists": true},
+	}, options.Find().SetSort(bson.D{{Key: "deleted_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(context, &workspaces); err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+// RestoreWorkspace brings the workspace back from the trash together with the
+// projects that were trashed with it.
+func (r *workspaceRepository) RestoreWorkspace(context context.Context, id string, userID string) (bool, error) {
+	ID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+	res, err := r.workspace.UpdateOne(context, bson.M{"_id": ID, "owner_id": userID, "deleted_at": bson.M{"$exists": true}}, bson.M{
+		"$unset": bson.M{
+			"deleted_at": "",
+			"deleted_by": "",
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	if res.MatchedCount == 0 {
+		return false, nil
+	}
+	_, err = r.projects.UpdateMany(context, bson.M{"workspace": ID, "trashed_with_workspace": true}, bson.M{
+		"$set": bson.M{
+			"updated_at": time.Now().Format(time.RFC3339),
+		},
+		"$unset": bson.M{
+			"deleted_at":             "",
+			"deleted_by":             "",
+			"trashed_with_workspace": "",
 		},
 	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *workspaceRepository) GetPurgeableWorkspaces(context context.Context, deletedBefore time.Time) ([]*models.Workspace, error) {
+	var workspaces []*models.Workspace
+	cursor, err := r.workspace.Find(context, bson.M{
+		"deleted_at": bson.M{"$lt": deletedBefore.UTC().Format(time.RFC3339)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(context, &workspaces); err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+// GetProjectIDs returns the IDs of every project in the workspace, trashed or
+// not.
+func (r *workspaceRepository) GetProjectIDs(context context.Context, id bson.ObjectID) ([]bson.ObjectID, error) {
+	cursor, err := r.projects.Find(context, bson.M{"workspace": id}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var projects []struct {
+		ID bson.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(context, &projects); err != nil {
+		return nil, err
+	}
+	ids := make([]bson.ObjectID, 0, len(projects))
+	for _, p := range projects {
+		ids = append(ids, p.ID)
+	}
+	return ids, nil
+}
+
+// PurgeWorkspace permanently removes a trashed workspace and every project in
+// it. Operations of those projects are removed separately.
+func (r *workspaceRepository) PurgeWorkspace(context context.Context, id bson.ObjectID) error {
+	_, err := r.projects.DeleteMany(context, bson.M{"workspace": id})
 	if err != nil {
 		return err
 	}
-	return nil
+	_, err = r.workspace.DeleteOne(context, bson.M{"_id": id, "deleted_at": bson.M{"$exists": true}})
+	return err
+}
+
+// StripInheritedProjectMembers removes workspace members from the member
+// lists of the workspace's projects. Projects created before project-level
+// membership copied the workspace members into their own list; those copies
+// are not grants and would otherwise outlive the workspace membership.
+// Returns the number of projects changed.
+func (r *workspaceRepository) StripInheritedProjectMembers(context context.Context) (int64, error) {
+	cursor, err := r.workspace.Find(context, bson.M{}, options.Find().SetProjection(bson.M{"owner_id": 1, "members": 1}))
+	if err != nil {
+		return 0, err
+	}
+	var workspaces []*models.Workspace
+	if err = cursor.All(context, &workspaces); err != nil {
+		return 0, err
+	}
+
+	var changed int64
+	for _, workspace := range workspaces {
+		inherited := append([]string{workspace.Owner}, workspace.Members...)
+		res, err := r.projects.UpdateMany(context, bson.M{"workspace": workspace.ID}, bson.M{
+			"$pull": bson.M{"members": bson.M{"$in": inherited}},
+		})
+		if err != nil {
+			return changed, err
+		}
+		changed += res.ModifiedCount
+	}
+	return changed, nil
 }
@@ -0,0 +1,214 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"github.com/chirag3003/collab-draw-backend/internal/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// snippetLength is roughly how many characters of context a hit shows.
+const snippetLength = 160
+
+// Indexer keeps the search index in step with projects, their text elements
+// and comments. Indexing failures are logged and never fail the write that
+// triggered them.
+type Indexer struct {
+	repo *repository.Repository
+}
+
+func NewIndexer(repo *repository.Repository) *Indexer {
+	return &Indexer{repo: repo}
+}
+
+// StartBackfill indexes every existing project in the background when the
+// index is empty, e.g. the first time the server runs with search.
+func (i *Indexer) StartBackfill() {
+	go func() {
+		ctx := context.Background()
+		empty, err := i.repo.Search.IsEmpty(ctx)
+		if err != nil {
+			log.Printf("Warning: failed to check search index: %v", err)
+			return
+		}
+		if !empty {
+			return
+		}
+		projects, err := i.repo.Project.GetAll(ctx)
+		if err != nil {
+			log.Printf("Warning: failed to load projects for search backfill: %v", err)
+			return
+		}
+		for _, project := range projects {
+			// Project lists leave elements out
+			project.Elements, err = i.repo.Project.GetElements(ctx, project.ID.Hex())
+			if err != nil {
+				log.Printf("Warning: failed to load elements of project %s for search backfill: %v", project.ID.Hex(), err)
+				continue
+			}
+			i.IndexProject(ctx, project)
+			i.indexProjectComments(ctx, project.ID)
+		}
+		log.Printf("Search index backfilled with %d projects", len(projects))
+	}()
+}
+
+// IndexProject indexes the project's name, description and every text element,
+// replacing what was indexed for it before.
+func (i *Indexer) IndexProject(ctx context.Context, project *models.Project) {
+	i.IndexProjectInfo(ctx, project)
+
+	texts := make(map[string]string)
+	var elements []json.RawMessage
+	if project.Elements != "" {
+		if err := json.Unmarshal([]byte(project.Elements), &elements); err != nil {
+			log.Printf("Warning: failed to index elements of project %s: %v", project.ID.Hex(), err)
+			return
+		}
+	}
+	for _, data := range elements {
+		if id, text, ok := elementText(data); ok {
+			texts[id] = text
+		}
+	}
+	if err := i.repo.Search.ReplaceElements(ctx, project.ID, texts); err != nil {
+		log.Printf("Warning: failed to index elements of project %s: %v", project.ID.Hex(), err)
+	}
+}
+
+// IndexProjectInfo indexes only the project's name and description.
+func (i *Indexer) IndexProjectInfo(ctx context.Context, project *models.Project) {
+	if err := i.repo.Search.SetProject(ctx, project.ID, project.Name, project.Description); err != nil {
+		log.Printf("Warning: failed to index project %s: %v", project.ID.Hex(), err)
+	}
+}
+
+// IndexOps applies accepted ops to the index. Only the last op on an element
+// matters; elements that are deleted or no longer hold text are removed.
+func (i *Indexer) IndexOps(ctx context.Context, projectID bson.ObjectID, ops []*models.Operation) {
+	latest := make(map[string]*string)
+	for _, op := range ops {
+		latest[op.ElementID] = nil
+		if op.Type == "DELETE" || op.Data == nil {
+			continue
+		}
+		if _, text, ok := elementText(json.RawMessage(*op.Data)); ok {
+			latest[op.ElementID] = &text
+		}
+	}
+
+	texts := make(map[string]string)
+	var removed []string
+	for id, text := range latest {
+		if text == nil {
+			removed = append(removed, id)
+		} else {
+			texts[id] = *text
+		}
+	}
+	if err := i.repo.Search.RemoveElements(ctx, projectID, removed); err != nil {
+		log.Printf("Warning: failed to update search index of project %s: %v", projectID.Hex(), err)
+	}
+	if err := i.repo.Search.SetElements(ctx, projectID, texts); err != nil {
+		log.Printf("Warning: failed to update search index of project %s: %v", projectID.Hex(), err)
+	}
+}
+
+// IndexComment indexes a comment. Comments on an element point at it so the
+// client can jump there.
+func (i *Indexer) IndexComment(ctx context.Context, thread *models.CommentThread, comment *models.Comment) {
+	err := i.repo.Search.SetComment(ctx, &models.SearchEntry{
+		ProjectID: comment.ProjectID,
+		ElementID: thread.ElementID,
+		ThreadID:  thread.ID.Hex(),
+		CommentID: comment.ID.Hex(),
+		Text:      comment.Body,
+	})
+	if err != nil {
+		log.Printf("Warning: failed to index comment %s: %v", comment.ID.Hex(), err)
+	}
+}
+
+func (i *Indexer) RemoveComment(ctx context.Context, commentID bson.ObjectID) {
+	if err := i.repo.Search.RemoveComment(ctx, commentID); err != nil {
+		log.Printf("Warning: failed to remove comment %s from search index: %v", commentID.Hex(), err)
+	}
+}
+
+func (i *Indexer) indexProjectComments(ctx context.Context, projectID bson.ObjectID) {
+	threads, err := i.repo.Comment.GetThreadsByProject(ctx, projectID, true)
+	if err != nil || len(threads) == 0 {
+		return
+	}
+	byID := make(map[bson.ObjectID]*models.CommentThread, len(threads))
+	threadIDs := make([]bson.ObjectID, 0, len(threads))
+	for _, thread := range threads {
+		byID[thread.ID] = thread
+		threadIDs = append(threadIDs, thread.ID)
+	}
+	comments, err := i.repo.Comment.GetCommentsByThreads(ctx, threadIDs)
+	if err != nil {
+		log.Printf("Warning: failed to load comments of project %s for search: %v", projectID.Hex(), err)
+		return
+	}
+	for _, comment := range comments {
+		if thread, ok := byID[comment.ThreadID]; ok {
+			i.IndexComment(ctx, thread, comment)
+		}
+	}
+}
+
+// elementText returns the text of a live text element.
+func elementText(data json.RawMessage) (string, string, bool) {
+	var el struct {
+		ID        string `json:"id"`
+		Type      string `json:"type"`
+		Text      string `json:"text"`
+		IsDeleted bool   `json:"isDeleted"`
+	}
+	if err := json.Unmarshal(data, &el); err != nil {
+		return "", "", false
+	}
+	if el.Type != "text" || el.IsDeleted || strings.TrimSpace(el.Text) == "" {
+		return "", "", false
+	}
+	return el.ID, el.Text, true
+}
+
+// Snippet cuts the part of text around the first query term it contains.
+func Snippet(text string, query string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= snippetLength {
+		return text
+	}
+	lower := strings.ToLower(text)
+	start := 0
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		term = strings.Trim(term, `"-`)
+		if term == "" {
+			continue
+		}
+		if at := strings.Index(lower, term); at >= 0 {
+			start = utf8.RuneCountInString(lower[:at])
+			break
+		}
+	}
+
+	runes := []rune(text)
+	start = max(0, start-snippetLength/4)
+	end := min(len(runes), start+snippetLength)
+	start = max(0, end-snippetLength)
+	snippet := string(runes[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
@@ -0,0 +1,109 @@
+package thumbnail
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/export"
+	"github.com/chirag3003/collab-draw-backend/internal/filestore"
+	"github.com/chirag3003/collab-draw-backend/internal/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	// settleDelay is how long a project must go without edits before its
+	// thumbnail is rendered.
+	settleDelay = 5 * time.Second
+	// maxDelay bounds how stale a thumbnail gets while a project is edited
+	// without pause.
+	maxDelay = time.Minute
+	// size is the longest side of a thumbnail in pixels.
+	size = 400
+	// renderTimeout bounds a single render and upload.
+	renderTimeout = 30 * time.Second
+)
+
+// Service renders project thumbnails once edits settle and keeps them in the
+// blob store.
+type Service struct {
+	repo    *repository.Repository
+	files   *filestore.Service
+	mu      sync.Mutex
+	pending map[bson.ObjectID]*pending
+}
+
+type pending struct {
+	timer *time.Timer
+	first time.Time
+}
+
+func NewService(repo *repository.Repository, files *filestore.Service) *Service {
+	return &Service{
+		repo:    repo,
+		files:   files,
+		pending: make(map[bson.ObjectID]*pending),
+	}
+}
+
+// Schedule renders the project's thumbnail after it has not been edited for
+// settleDelay. Every edit pushes the render back, up to maxDelay after the
+// first one.
+func (s *Service) Schedule(projectID bson.ObjectID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p, ok := s.pending[projectID]; ok {
+		if time.Since(p.first)+settleDelay <= maxDelay {
+			p.timer.Reset(settleDelay)
+		}
+		return
+	}
+	s.pending[projectID] = &pending{
+		first: time.Now(),
+		timer: time.AfterFunc(settleDelay, func() {
+			s.mu.Lock()
+			delete(s.pending, projectID)
+			s.mu.Unlock()
+
+			ctx, cancel := context.WithTimeout(context.Background(), renderTimeout)
+			defer cancel()
+			if err := s.Render(ctx, projectID); err != nil {
+				log.Printf("Warning: failed to render thumbnail of project %s: %v", projectID.Hex(), err)
+			}
+		}),
+	}
+}
+
+// Render renders and stores the project's thumbnail now. Empty projects have
+// no thumbnail.
+func (s *Service) Render(ctx context.Context, projectID bson.ObjectID) error {
+	project, err := s.repo.Project.GetProject(ctx, projectID.Hex())
+	if err != nil || project == nil {
+		return err
+	}
+	scene, err := export.NewScene(project.Elements, nil)
+	if err != nil {
+		return err
+	}
+	if len(scene.Elements) == 0 {
+		if project.ThumbnailHash == "" {
+			return nil
+		}
+		return s.repo.Project.SetThumbnail(ctx, project.ID, "")
+	}
+
+	image, err := export.RenderThumbnail(scene, size)
+	if err != nil {
+		return err
+	}
+	hash, err := s.files.PutBlob(ctx, image, "image/png")
+	if err != nil {
+		return err
+	}
+	if hash == project.ThumbnailHash {
+		return nil
+	}
+	return s.repo.Project.SetThumbnail(ctx, project.ID, hash)
+}
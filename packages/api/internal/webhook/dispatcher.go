@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"github.com/chirag3003/collab-draw-backend/internal/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	// maxAttempts is how many times a delivery is tried before it is marked
+	// as failed.
+	maxAttempts = 8
+	// baseBackoff is the wait after the first failure; it doubles with every
+	// further attempt.
+	baseBackoff = 30 * time.Second
+	// deliveryLease keeps other workers off a delivery while it is in flight.
+	deliveryLease  = time.Minute
+	pollInterval   = 5 * time.Second
+	requestTimeout = 10 * time.Second
+)
+
+const (
+	SignatureHeader = "X-CollabDraw-Signature"
+	EventHeader     = "X-CollabDraw-Event"
+	DeliveryHeader  = "X-CollabDraw-Delivery"
+)
+
+// Payload is the JSON body posted to webhook URLs.
+type Payload struct {
+	ID          string `json:"id"`
+	Event       string `json:"event"`
+	WorkspaceID string `json:"workspaceId"`
+	Timestamp   string `json:"timestamp"`
+	Data        any    `json:"data"`
+}
+
+// Dispatcher queues webhook deliveries in MongoDB and sends them in the
+// background.
+type Dispatcher struct {
+	repo   repository.WebhookRepository
+	client *http.Client
+}
+
+func NewDispatcher(repo repository.WebhookRepository) *Dispatcher {
+	return &Dispatcher{
+		repo:   repo,
+		client: newClient(),
+	}
+}
+
+// GenerateSecret returns a new random signing secret.
+func GenerateSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature header value of a body: the hex HMAC-SHA256 of
+// the body keyed with the webhook secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Enqueue queues the event for every active webhook of the workspace that
+// subscribed to it. Errors are logged; webhooks never fail the caller.
+func (d *Dispatcher) Enqueue(ctx context.Context, workspaceID bson.ObjectID, event string, data any) {
+	webhooks, err := d.repo.GetActiveWebhooks(ctx, workspaceID, event)
+	if err != nil {
+		log.Printf("Warning: failed to look up webhooks of workspace %s: %v", workspaceID.Hex(), err)
+		return
+	}
+	for _, webhook := range webhooks {
+		delivery := &models.WebhookDelivery{
+			ID:          bson.NewObjectID(),
+			WebhookID:   webhook.ID,
+			WorkspaceID: workspaceID,
+			Event:       event,
+		}
+		body, err := json.Marshal(Payload{
+			ID:          delivery.ID.Hex(),
+			Event:       event,
+			WorkspaceID: workspaceID.Hex(),
+			Timestamp:   time.Now().Format(time.RFC3339),
+			Data:        data,
+		})
+		if err != nil {
+			log.Printf("Warning: failed to encode webhook payload for %s to %s: %v", event, webhook.ID.Hex(), err)
+			continue
+		}
+		delivery.Payload = string(body)
+		if err := d.repo.EnqueueDelivery(ctx, delivery); err != nil {
+			log.Printf("Warning: failed to queue webhook delivery to %s: %v", webhook.ID.Hex(), err)
+		}
+	}
+}
+
+// Start runs the delivery worker in the background.
+func (d *Dispatcher) Start() {
+	go func() {
+		for {
+			d.drain(context.Background())
+			time.Sleep(pollInterval)
+		}
+	}()
+}
+
+// drain sends every delivery that is due.
+func (d *Dispatcher) drain(ctx context.Context) {
+	for {
+		delivery, err := d.repo.ClaimDueDelivery(ctx, deliveryLease)
+		if err != nil {
+			log.Printf("Warning: failed to claim webhook delivery: %v", err)
+			return
+		}
+		if delivery == nil {
+			return
+		}
+		d.attempt(ctx, delivery)
+	}
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, delivery *models.WebhookDelivery) {
+	webhook, err := d.repo.GetWebhook(ctx, delivery.WebhookID.Hex())
+	if err != nil {
+		log.Printf("Warning: failed to fetch webhook %s: %v", delivery.WebhookID.Hex(), err)
+		return
+	}
+	if webhook == nil || !webhook.Active {
+		_ = d.repo.MarkAttemptFailed(ctx, delivery.ID, 0, "webhook was removed or disabled", nil)
+		return
+	}
+
+	code, err := d.send(ctx, webhook, delivery)
+	if err == nil {
+		if err := d.repo.MarkDelivered(ctx, delivery.ID, code); err != nil {
+			log.Printf("Warning: failed to record webhook delivery %s: %v", delivery.ID.Hex(), err)
+		}
+		return
+	}
+
+	var next *time.Time
+	if delivery.Attempts < maxAttempts {
+		at := time.Now().Add(Backoff(delivery.Attempts))
+		next = &at
+	}
+	if err := d.repo.MarkAttemptFailed(ctx, delivery.ID, code, err.Error(), next); err != nil {
+		log.Printf("Warning: failed to record webhook delivery %s: %v", delivery.ID.Hex(), err)
+	}
+}
+
+// send posts the delivery and returns the response status code. Any status
+// outside 2xx is an error.
+func (d *Dispatcher) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID.Hex())
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("receiver responded with %s", res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+// Backoff returns the wait before retrying after the given number of attempts.
+func Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return baseBackoff << (attempts - 1)
+}
@@ -0,0 +1,368 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chirag3003/collab-draw-backend/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// fakeRepository keeps webhooks and deliveries in memory. Due deliveries are
+// claimed in insertion order, ignoring lease times.
+type fakeRepository struct {
+	mu         sync.Mutex
+	webhooks   map[bson.ObjectID]*models.Webhook
+	deliveries []*models.WebhookDelivery
+	// nextAttempts records the nextAttemptAt passed to each failed attempt.
+	nextAttempts []*time.Time
+}
+
+func newFakeRepository(webhooks ...*models.Webhook) *fakeRepository {
+	repo := &fakeRepository{webhooks: map[bson.ObjectID]*models.Webhook{}}
+	for _, webhook := range webhooks {
+		repo.webhooks[webhook.ID] = webhook
+	}
+	return repo
+}
+
+func (f *fakeRepository) CreateWebhook(ctx context.Context, data *models.Webhook) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.webhooks[data.ID] = data
+	return nil
+}
+
+func (f *fakeRepository) GetWebhook(ctx context.Context, id string) (*models.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	oid, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return f.webhooks[oid], nil
+}
+
+func (f *fakeRepository) GetWebhooksByWorkspace(ctx context.Context, workspaceID bson.ObjectID) ([]*models.Webhook, error) {
+	return f.GetActiveWebhooks(ctx, workspaceID, "")
+}
+
+func (f *fakeRepository) GetActiveWebhooks(ctx context.Context, workspaceID bson.ObjectID, event string) ([]*models.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var result []*models.Webhook
+	for _, webhook := range f.webhooks {
+		if webhook.WorkspaceID != workspaceID || !webhook.Active {
+			continue
+		}
+		for _, e := range webhook.Events {
+			if event == "" || e == event {
+				result = append(result, webhook)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeRepository) SetActive(ctx context.Context, id bson.ObjectID, active bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.webhooks[id].Active = active
+	return nil
+}
+
+func (f *fakeRepository) DeleteWebhook(ctx context.Context, id bson.ObjectID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.webhooks, id)
+	return nil
+}
+
+func (f *fakeRepository) EnqueueDelivery(ctx context.Context, data *models.WebhookDelivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data.Status = models.WebhookDeliveryPending
+	f.deliveries = append(f.deliveries, data)
+	return nil
+}
+
+func (f *fakeRepository) ClaimDueDelivery(ctx context.Context, lease time.Duration) (*models.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	for _, delivery := range f.deliveries {
+		if delivery.Status != models.WebhookDeliveryPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		delivery.Attempts++
+		delivery.NextAttemptAt = now.Add(lease)
+		return delivery, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeRepository) delivery(id bson.ObjectID) *models.WebhookDelivery {
+	for _, delivery := range f.deliveries {
+		if delivery.ID == id {
+			return delivery
+		}
+	}
+	return nil
+}
+
+func (f *fakeRepository) MarkDelivered(ctx context.Context, id bson.ObjectID, responseCode int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delivery := f.delivery(id)
+	delivery.Status = models.WebhookDeliverySucceeded
+	delivery.ResponseCode = responseCode
+	delivery.LastError = ""
+	return nil
+}
+
+func (f *fakeRepository) MarkAttemptFailed(ctx context.Context, id bson.ObjectID, responseCode int, lastError string, nextAttemptAt *time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextAttempts = append(f.nextAttempts, nextAttemptAt)
+	delivery := f.delivery(id)
+	delivery.ResponseCode = responseCode
+	delivery.LastError = lastError
+	if nextAttemptAt != nil {
+		delivery.NextAttemptAt = *nextAttemptAt
+	} else {
+		delivery.Status = models.WebhookDeliveryFailed
+	}
+	return nil
+}
+
+func (f *fakeRepository) GetDeliveries(ctx context.Context, webhookID bson.ObjectID, cursor string) ([]*models.WebhookDelivery, string, error) {
+	return nil, "", errors.New("not implemented")
+}
+
+func (f *fakeRepository) DeleteByWorkspace(ctx context.Context, workspaceID bson.ObjectID) error {
+	return errors.New("not implemented")
+}
+
+// setup queues one OPS_APPLIED event for a webhook pointing at handler. The
+// dispatcher uses the test server's client, since the production client
+// refuses to dial loopback addresses.
+func setup(t *testing.T, handler http.HandlerFunc) (*Dispatcher, *fakeRepository, *models.WebhookDelivery) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	hook := &models.Webhook{
+		ID:          bson.NewObjectID(),
+		WorkspaceID: bson.NewObjectID(),
+		URL:         server.URL,
+		Events:      []string{models.WebhookEventOpsApplied},
+		Secret:      "whsec_test",
+		Active:      true,
+	}
+	repo := newFakeRepository(hook)
+	d := &Dispatcher{repo: repo, client: server.Client()}
+	d.Enqueue(context.Background(), hook.WorkspaceID, models.WebhookEventOpsApplied, map[string]int{"ops": 3})
+	if len(repo.deliveries) != 1 {
+		t.Fatalf("queued %d deliveries, want 1", len(repo.deliveries))
+	}
+	return d, repo, repo.deliveries[0]
+}
+
+func TestDeliverySignature(t *testing.T) {
+	var body []byte
+	var header http.Header
+	d, _, delivery := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header.Clone()
+	})
+	d.drain(context.Background())
+
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write(body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := header.Get(SignatureHeader); !hmac.Equal([]byte(got), []byte(want)) {
+		t.Errorf("%s = %q, want %q", SignatureHeader, got, want)
+	}
+	if got := header.Get(EventHeader); got != models.WebhookEventOpsApplied {
+		t.Errorf("%s = %q, want %q", EventHeader, got, models.WebhookEventOpsApplied)
+	}
+	if got := header.Get(DeliveryHeader); got != delivery.ID.Hex() {
+		t.Errorf("%s = %q, want %q", DeliveryHeader, got, delivery.ID.Hex())
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	if payload.ID != delivery.ID.Hex() || payload.Event != models.WebhookEventOpsApplied {
+		t.Errorf("payload = %+v, want delivery %s of %s", payload, delivery.ID.Hex(), models.WebhookEventOpsApplied)
+	}
+}
+
+func TestDeliverySucceedsOn2xx(t *testing.T) {
+	d, repo, delivery := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+	d.drain(context.Background())
+
+	if delivery.Status != models.WebhookDeliverySucceeded {
+		t.Fatalf("status = %s, want %s", delivery.Status, models.WebhookDeliverySucceeded)
+	}
+	if delivery.ResponseCode != http.StatusAccepted {
+		t.Errorf("response code = %d, want %d", delivery.ResponseCode, http.StatusAccepted)
+	}
+	if delivery.Attempts != 1 || len(repo.nextAttempts) != 0 {
+		t.Errorf("attempts = %d with %d failures, want 1 and 0", delivery.Attempts, len(repo.nextAttempts))
+	}
+}
+
+func TestDeliveryRetriesWithBackoffOn5xx(t *testing.T) {
+	calls := 0
+	d, repo, delivery := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		before := time.Now()
+		d.drain(context.Background())
+
+		if delivery.Status != models.WebhookDeliveryPending {
+			t.Fatalf("attempt %d: status = %s, want %s", attempt, delivery.Status, models.WebhookDeliveryPending)
+		}
+		if delivery.ResponseCode != http.StatusServiceUnavailable || !strings.Contains(delivery.LastError, "503") {
+			t.Errorf("attempt %d: recorded %d %q, want the 503", attempt, delivery.ResponseCode, delivery.LastError)
+		}
+		next := repo.nextAttempts[attempt-1]
+		if next == nil {
+			t.Fatalf("attempt %d: no retry scheduled", attempt)
+		}
+		wait := Backoff(attempt)
+		if next.Before(before.Add(wait)) || next.After(time.Now().Add(wait)) {
+			t.Errorf("attempt %d: retry at %v, want %v after the attempt", attempt, next, wait)
+		}
+		// Make the delivery due again instead of waiting out the backoff.
+		delivery.NextAttemptAt = time.Time{}
+	}
+	if calls != 3 {
+		t.Errorf("receiver was called %d times, want 3", calls)
+	}
+}
+
+func TestDeliveryGivesUpAfterMaxAttempts(t *testing.T) {
+	calls := 0
+	d, repo, delivery := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	for i := 0; i < maxAttempts+2; i++ {
+		d.drain(context.Background())
+		delivery.NextAttemptAt = time.Time{}
+	}
+
+	if delivery.Status != models.WebhookDeliveryFailed {
+		t.Fatalf("status = %s, want %s", delivery.Status, models.WebhookDeliveryFailed)
+	}
+	if calls != maxAttempts || delivery.Attempts != maxAttempts {
+		t.Errorf("receiver called %d times over %d attempts, want %d", calls, delivery.Attempts, maxAttempts)
+	}
+	if last := repo.nextAttempts[len(repo.nextAttempts)-1]; last != nil {
+		t.Errorf("last attempt scheduled a retry at %v, want none", last)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, baseBackoff},
+		{1, baseBackoff},
+		{2, 2 * baseBackoff},
+		{3, 4 * baseBackoff},
+		{maxAttempts, baseBackoff << (maxAttempts - 1)},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestDeliveryRefusesInternalAddresses(t *testing.T) {
+	d, _, delivery := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("receiver on a loopback address was called")
+	})
+	d.client = newClient()
+	d.drain(context.Background())
+
+	if delivery.Status != models.WebhookDeliveryPending || !strings.Contains(delivery.LastError, ErrPrivateAddress.Error()) {
+		t.Errorf("delivery ended %s with %q, want a refused dial", delivery.Status, delivery.LastError)
+	}
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"255.255.255.255", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := IsPublicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("IsPublicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr string
+	}{
+		{"https://93.184.216.34/hook", ""},
+		{"ftp://93.184.216.34/hook", "absolute http or https URL"},
+		{"/hook", "absolute http or https URL"},
+		{"http://127.0.0.1:8080/hook", ErrPrivateAddress.Error()},
+		{"http://[::1]/hook", ErrPrivateAddress.Error()},
+		{"http://169.254.169.254/latest/meta-data", ErrPrivateAddress.Error()},
+		{"http://localhost/hook", ErrPrivateAddress.Error()},
+	}
+	for _, tt := range tests {
+		_, err := ValidateURL(context.Background(), tt.url)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("ValidateURL(%q) = %v, want no error", tt.url, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("ValidateURL(%q) = %v, want %q", tt.url, err, tt.wantErr)
+		}
+	}
+}
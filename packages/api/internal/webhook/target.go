@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned for webhook targets that resolve to loopback,
+// private, link-local or otherwise internal addresses.
+var ErrPrivateAddress = errors.New("webhook URL must resolve to a public address")
+
+// IPv4 ranges that netip.Addr has no predicate for: carrier-grade NAT
+// (RFC 6598) and the limited broadcast address.
+var (
+	sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+	ipv4Broadcast      = netip.AddrFrom4([4]byte{255, 255, 255, 255})
+)
+
+// IsPublicAddr reports whether webhook deliveries may be sent to the address.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() ||
+		addr.IsUnspecified() ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	if addr.Is4() && (sharedAddressSpace.Contains(addr) || addr.As4()[0] == 0 || addr == ipv4Broadcast) {
+		return false
+	}
+	return true
+}
+
+// ValidateURL checks that a webhook URL is an absolute http or https URL whose
+// host only resolves to public addresses. The check is repeated when each
+// delivery dials, since DNS can change after the webhook is created.
+func ValidateURL(ctx context.Context, raw string) (*url.URL, error) {
+	target, err := url.Parse(raw)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "" {
+		return nil, fmt.Errorf("webhook URL must be an absolute http or https URL")
+	}
+	host := target.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !IsPublicAddr(addr) {
+			return nil, ErrPrivateAddress
+		}
+		return target, nil
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve webhook host %q: %v", host, err)
+	}
+	for _, addr := range addrs {
+		if !IsPublicAddr(addr) {
+			return nil, ErrPrivateAddress
+		}
+	}
+	return target, nil
+}
+
+// dialControl refuses connections to internal addresses. It runs after DNS
+// resolution, so it also catches hosts that were rebound to an internal
+// address after ValidateURL passed, as well as redirects.
+func dialControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("webhook dial to %q: %v", address, err)
+	}
+	if !IsPublicAddr(addrPort.Addr()) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// newClient returns the HTTP client used for deliveries. It does not use the
+// environment's proxy, since the dial check would then only see the proxy.
+func newClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: requestTimeout,
+		Control: dialControl,
+	}
+	return &http.Client{
+		Timeout: requestTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: requestTimeout,
+			IdleConnTimeout:     90 * time.Second,
+			MaxIdleConns:        16,
+		},
+	}
+}
@@ -16,9 +16,19 @@ import (
 	"github.com/chirag3003/collab-draw-backend/graph"
 	"github.com/chirag3003/collab-draw-backend/graph/resolvers"
 	"github.com/chirag3003/collab-draw-backend/internal/auth"
+	"github.com/chirag3003/collab-draw-backend/internal/cleanup"
 	"github.com/chirag3003/collab-draw-backend/internal/db"
+	"github.com/chirag3003/collab-draw-backend/internal/filestore"
+	"github.com/chirag3003/collab-draw-backend/internal/importer"
+	"github.com/chirag3003/collab-draw-backend/internal/migrate"
 	"github.com/chirag3003/collab-draw-backend/internal/oidc"
+	"github.com/chirag3003/collab-draw-backend/internal/querylimit"
+	"github.com/chirag3003/collab-draw-backend/internal/quota"
+	"github.com/chirag3003/collab-draw-backend/internal/ratelimit"
 	"github.com/chirag3003/collab-draw-backend/internal/repository"
+	"github.com/chirag3003/collab-draw-backend/internal/search"
+	"github.com/chirag3003/collab-draw-backend/internal/thumbnail"
+	"github.com/chirag3003/collab-draw-backend/internal/webhook"
 	"github.com/go-chi/chi"
 	"github.com/gorilla/websocket"
 	"github.com/joho/godotenv"
@@ -45,6 +55,37 @@ func main() {
 	// Setting up repositories
 	repo := repository.Setup()
 
+	// Bring existing data up to date before serving requests
+	if err := migrate.Run(context.Background(), repo); err != nil {
+		log.Fatalf("Failed to migrate data: %v", err)
+	}
+
+	// Purge projects and workspaces that outlived the trash retention period
+	cleanupService := cleanup.NewService(repo)
+	cleanupService.StartTrashPurger()
+
+	// Deliver queued webhook events in the background
+	webhooks := webhook.NewDispatcher(repo.Webhook)
+	webhooks.Start()
+
+	// Store embedded image files and collect the ones no longer referenced
+	blobStore, err := filestore.NewStoreFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to set up file store: %v", err)
+	}
+	files := filestore.NewService(repo, blobStore)
+	files.StartCollector()
+
+	// Render project thumbnails once edits settle
+	thumbnails := thumbnail.NewService(repo, files)
+
+	// Keep the search index current, indexing existing projects on first run
+	searchIndexer := search.NewIndexer(repo)
+	searchIndexer.StartBackfill()
+
+	// Cap what each workspace may hold, as configured by WORKSPACE_MAX_*
+	quotas := quota.NewService(repo, quota.LimitsFromEnv())
+
 	// Initialize OIDC with retry for Keycloak startup
 	for i := 0; i < 30; i++ {
 		if err := oidc.Init(); err != nil {
@@ -59,11 +100,22 @@ func main() {
 		log.Fatal("Failed to initialize OIDC provider after retries")
 	}
 
-	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: resolvers.NewResolver(repo)}))
+	resolver := resolvers.NewResolver(repo, cleanupService, webhooks, files, searchIndexer, thumbnails, quotas)
+
+	// End guest subscriptions once their share link expires
+	resolver.StartShareLinkSweeper()
+
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{
+		Resolvers:  resolver,
+		Complexity: graph.NewComplexity(),
+	}))
 
 	srv.AddTransport(transport.Websocket{
 		KeepAlivePingInterval: 10 * time.Second,
 		InitFunc: func(ctx context.Context, initPayload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+			// Count the subscriptions opened on this connection
+			ctx = ratelimit.WithConnection(ctx)
+
 			// Extract authorization from connection params
 			authHeader := initPayload.Authorization()
 			if authHeader == "" {
@@ -73,15 +125,20 @@ func main() {
 			}
 
 			if authHeader != "" {
-				tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
+				creds := auth.Credentials{
+					Token: strings.TrimPrefix(authHeader, "Bearer "),
+				}
+				creds.SharePassword, _ = initPayload["sharePassword"].(string)
+				creds.GuestID, _ = initPayload["guestID"].(string)
+				creds.GuestName, _ = initPayload["guestName"].(string)
 
-				idToken, err := oidc.Verifier.Verify(ctx, tokenStr)
+				validatedCtx, err := auth.Authenticate(ctx, creds, repo)
 				if err == nil {
-					var claims oidc.Claims
-					if err := idToken.Claims(&claims); err == nil {
-						validatedCtx := context.WithValue(ctx, auth.UserContextKey, &claims)
-						return validatedCtx, &initPayload, nil
+					// Guests get their session in the ack to reuse as guestID
+					if session := auth.GuestSessionForContext(validatedCtx); session != "" {
+						return validatedCtx, &transport.InitPayload{"guestID": session}, nil
 					}
+					return validatedCtx, &initPayload, nil
 				}
 				log.Printf("WebSocket token verification failed: %v", err)
 			}
@@ -102,10 +159,26 @@ func main() {
 	srv.AddTransport(transport.Options{})
 	srv.AddTransport(transport.GET{})
 	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{
+		MaxUploadSize: importer.MaxFileSize + 1<<20,
+		MaxMemory:     32 << 20,
+	})
 
 	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
 
-	srv.Use(extension.Introspection{})
+	srv.AroundOperations(auth.EnforceReadOnly)
+
+	// Throttle busy mutations per user and project, as configured by RATE_LIMITS
+	srv.Use(ratelimit.New(ratelimit.ConfigFromEnv()))
+
+	// Reject expensive or deeply nested queries before running them
+	srv.Use(extension.FixedComplexityLimit(querylimit.MaxComplexity()))
+	srv.Use(querylimit.DepthLimit{Max: querylimit.MaxDepth()})
+
+	// Keep the schema private in production
+	if !querylimit.IsProduction() {
+		srv.Use(extension.Introspection{})
+	}
 	srv.Use(extension.AutomaticPersistedQuery{
 		Cache: lru.New[string](100),
 	})
@@ -115,19 +188,44 @@ func main() {
 		AllowedOrigins:   []string{"http://localhost:3080", "https://collab.chirag.codes"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"*"},
+		ExposedHeaders:   []string{"X-Guest-ID"},
 		AllowCredentials: true,
 	}).Handler)
 	router.Handle("/", playground.Handler("GraphQL playground", "/query"))
 
 	// Custom middleware that allows WebSocket upgrades to bypass auth middleware
 	router.Handle("/query", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(auth.WithRequestInfo(r.Context(), r))
 		if r.Header.Get("Upgrade") == "websocket" {
 			srv.ServeHTTP(w, r)
 			return
 		}
-		auth.Middleware()(srv).ServeHTTP(w, r)
+		auth.Middleware(repo)(srv).ServeHTTP(w, r)
 	}))
 
+	// Exports are downloaded with a signed link from the exportProject query
+	// or with the usual API credentials
+	router.Get("/export/{projectID}", func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(auth.WithRequestInfo(r.Context(), r))
+		if r.URL.Query().Has("sig") {
+			resolver.ServeExport(w, r)
+			return
+		}
+		auth.Middleware(repo)(http.HandlerFunc(resolver.ServeExport)).ServeHTTP(w, r)
+	})
+
+	// Image files embedded in drawings
+	filesRouter := router.With(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(auth.WithRequestInfo(r.Context(), r)))
+		})
+	}, auth.Middleware(repo))
+	filesRouter.Get("/projects/{projectID}/files/{fileID}", resolver.ServeFile)
+	filesRouter.Put("/projects/{projectID}/files/{fileID}", resolver.ServeFileUpload)
+
+	// Project thumbnails, linked from Project.thumbnailURL
+	router.Get("/thumbnails/{file}", resolver.ServeThumbnail)
+
 	log.Printf("connect to http://localhost:%s/ for GraphQL playground", port)
 	log.Fatal(http.ListenAndServe(":"+port, router))
 }